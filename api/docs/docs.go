@@ -1,5 +1,5 @@
-// Package docs GENERATED BY SWAG; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -41,7 +41,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.Auth"
+                            "$ref": "#/definitions/internal_handler_auth.Auth"
                         }
                     }
                 ],
@@ -131,32 +131,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/": {
+        "/checks/batch": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "user provides products barcode, name, description and cost",
+                "description": "generates checks for products chosen by barcodes or, if there are none, by filter and streams ZIP archive with them, manifest.json in archive lists result for every product(ok, not_found, not_owner, archived, failed)",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Create new product",
+                "summary": "Generate checks in ZIP archive",
                 "parameters": [
                     {
-                        "description": "barcode,name,desc,cost",
-                        "name": "product",
+                        "format": "pdf by default",
+                        "description": "barcodes or filter, template and format(pdf by default)",
+                        "name": "batch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/product.Created"
+                            "$ref": "#/definitions/check.BatchRequest"
                         }
                     }
                 ],
@@ -164,7 +165,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.JSONResult"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -173,8 +174,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.JSONResult"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
@@ -188,14 +189,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/all": {
+        "/checks/shares": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "configured to return set number of products(barcodes+name+cost) per request, user sends configures how many products per page be seen and offset, only owner of product can view it",
+                "description": "returns share links of user checks with download counters, newest first",
                 "consumes": [
                     "application/x-www-form-urlencoded"
                 ],
@@ -203,26 +204,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Returns all users products with pagnation",
+                "summary": "Returns public links to checks",
                 "parameters": [
                     {
-                        "maximum": 50000,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Next page to retrieve",
-                        "name": "p",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "Check file name, links of all checks are returned if not set",
+                        "name": "check",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/checks/shares/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "disables share link, check can't be downloaded by it anymore, only owner of product can do it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "Revoke public link to check",
+                "parameters": [
                     {
-                        "maximum": 100,
-                        "minimum": 1,
                         "type": "integer",
-                        "description": "Number of products info per page",
-                        "name": "n",
-                        "in": "query",
+                        "description": "Share link id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -239,8 +271,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.JSONResult"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
@@ -254,31 +286,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/check/{checkName}": {
-            "get": {
+        "/checks/verify": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "sends user PDF check generated previously using product info, only owner of product can do it",
+                "description": "checks that uploaded PDF is signed by service and wasn't modified, returns matching check record if user owns it",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "multipart/form-data"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/pdf"
+                    "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Returns check of user product",
+                "summary": "Verify check signature",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Check file name",
-                        "name": "checkName",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Signed PDF check",
+                        "name": "check",
+                        "in": "formData",
                         "required": true
                     }
                 ],
@@ -286,7 +317,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/response.JSONResult"
                         }
                     },
                     "400": {
@@ -295,8 +326,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.JSONResult"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
@@ -306,18 +343,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
                     }
                 }
             }
         },
-        "/product/{barcode}": {
+        "/checks/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "returns full info about product user chose to view, only owner of product can view it",
+                "description": "returns creation time, size, SHA-256, template version and product snapshot of check, only owner of product can view it",
                 "consumes": [
                     "application/x-www-form-urlencoded"
                 ],
@@ -325,14 +368,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Returns user product full info",
+                "summary": "Returns check metadata",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Product barcode",
-                        "name": "barcode",
+                        "type": "integer",
+                        "description": "Check id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -370,7 +413,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "archives product but don't delete it from storage, only owner of product can do it",
+                "description": "removes check record and stored file, only owner of product can do it; issued fiscal receipts can't be deleted",
                 "consumes": [
                     "application/x-www-form-urlencoded"
                 ],
@@ -378,14 +421,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Delete user product",
+                "summary": "Delete check",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Product barcode",
-                        "name": "barcode",
+                        "type": "integer",
+                        "description": "Check id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -409,6 +452,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.JSONResult"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -418,39 +467,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/{barcode}/check": {
-            "get": {
+        "/checks/{name}/send": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "using info about given product generates PDF check using special PDF template",
+                "description": "queues sending of email with check PDF attached to recipient, delivery state is shown in check metadata, only owner of product can do it",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/pdf"
+                    "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "check"
                 ],
-                "summary": "Generates check",
+                "summary": "Send check by email",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product barcode",
-                        "name": "barcode",
+                        "description": "Check file name",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "recipient email address and optional message",
+                        "name": "send",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/check.SendRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/response.JSONResult"
                         }
                     },
                     "400": {
@@ -459,8 +516,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.JSONResult"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
@@ -470,71 +527,3221 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.JSONResult"
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.Auth": {
-            "type": "object",
-            "required": [
-                "login",
-                "password"
-            ],
-            "properties": {
-                "login": {
-                    "type": "string",
-                    "maxLength": 40,
-                    "minLength": 3,
-                    "example": "Login123"
-                },
-                "password": {
-                    "type": "string",
-                    "default": "password",
-                    "maxLength": 40,
-                    "minLength": 6
-                }
-            }
         },
-        "auth.Registration": {
-            "type": "object",
-            "required": [
-                "email",
-                "fullName",
-                "login",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "test@test.com"
-                },
-                "fullName": {
-                    "type": "string",
-                    "maxLength": 75,
-                    "minLength": 3,
-                    "example": "Ivanov Ivan Ivanovich"
-                },
-                "login": {
-                    "type": "string",
-                    "maxLength": 40,
-                    "minLength": 3,
-                    "example": "Login123"
-                },
-                "password": {
-                    "type": "string",
-                    "maxLength": 40,
-                    "minLength": 6,
-                    "example": "password"
+        "/checks/{name}/share": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "creates signed link check can be downloaded by without authentication until it expires, is revoked or max downloads count is reached, only owner of product can do it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "Create public link to check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Check file name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "link lifetime(default from configuration) and max downloads(0 - unlimited)",
+                        "name": "share",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/check.ShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
                 }
             }
         },
-        "product.Created": {
-            "type": "object",
-            "required": [
-                "barcode",
-                "cost",
+        "/coupons/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "creates percentage or fixed amount coupon with optional validity window, usage limits per code and per user and minimum order value, codes are case insensitive; coupon with amount or minimum applies only to orders in its currency",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "coupon"
+                ],
+                "summary": "Create coupon",
+                "parameters": [
+                    {
+                        "description": "coupon",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coupon.Created"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/coupons/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns coupons of user with number of their uses, newest first",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "coupon"
+                ],
+                "summary": "Returns user coupons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/coupons/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "coupon can't be applied to new orders anymore, discounts it gave stay in orders",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "coupon"
+                ],
+                "summary": "Disable coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns in-app notification list of user with low stock alerts, newest first",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Returns low stock alerts with pagination",
+                "parameters": [
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of alerts per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return only unread alerts",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/alerts/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "marks alert in notification list of user as read, marking read alert again does nothing",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Mark low stock alert read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Alert id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/reorder": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "suggests quantities to order for user products that have reorder threshold or were sold within last days: sales velocity over the window times cover days plus threshold minus available stock",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Returns reorder suggestions",
+                "parameters": [
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Sales window in days, configured default when absent",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Days suggested quantities should cover, configured default when absent",
+                        "name": "cover",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns reorder thresholds of user products with their current stock in order products were created",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Returns reorder thresholds",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/rules/{barcode}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "sets reorder threshold of user product, low stock alert is raised once when available stock drops below threshold and again only after it was back at threshold; changing threshold re-evaluates product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Set reorder threshold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "threshold",
+                        "name": "threshold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/inventory.Threshold"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "removes reorder threshold of user product, product isn't evaluated by low stock alerts anymore and its alerts stay in notification list",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Delete reorder threshold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/inventory/settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns channels low stock alerts of user are sent to besides in-app notification list",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Returns alert settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user opts in to low stock alerts by email to their address and by webhook of public host, webhook receives JSON signed with HMAC-SHA256 of webhook secret in X-Signature header; new webhook gets new secret, current secret is returned in webhookSecret of every update; channels disabled by server are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Update alert settings",
+                "parameters": [
+                    {
+                        "description": "email,webhook",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler_inventory.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns status of job(queued, running, done, failed) and link to generated check when it's done, only creator of job can view it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Returns check generation job status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user provides barcodes of own products with quantities and optional coupon codes, current product names and costs are saved in draft order, coupons are applied in given order each to price left after previous ones and recorded with order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Create new order",
+                "parameters": [
+                    {
+                        "description": "order items",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/order.Created"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns orders with totals and number of items, newest first, only owner of order can view it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Returns user orders with pagination",
+                "parameters": [
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of orders per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency amounts are converted to with rates effective at order creation",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns order with items, status history and statuses it can be moved to, only owner of order can view it; order converted to currency has original total and rate",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Returns user order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency amounts are converted to with rate effective at order creation",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "renders PDF receipt with all order lines, subtotal, discount, VAT and grand total over check template, lines that don't fit template page are continued on next pages, only owner of order can get it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Returns order receipt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name, user default template is used if not set",
+                        "name": "template",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/transitions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "moves order to requested status, allowed: draft -\u003e placed|cancelled, placed -\u003e paid|cancelled, paid -\u003e shipped|refunded, shipped -\u003e completed|refunded, completed -\u003e refunded; change is saved in order history, only owner of order can do it; placing order reserves ordered quantities in warehouse chosen by configured strategy for configured time(409 if available stock is short and negative stock is rejected or user has no warehouse), paying it turns reservations into sales(quantities of expired reservations are taken from available stock again), cancelling it releases reservations and puts goods taken from stock back to warehouses they were taken from",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Change order status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new status",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/order.Transition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user provides products barcode, name, description and cost as decimal string in ISO 4217 currency(user currency if not set), cost can't have more fraction digits than currency",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Create new product",
+                "parameters": [
+                    {
+                        "description": "barcode,name,desc,cost,currency",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.Created"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "configured to return set number of products(barcodes+name+cost) per request, user sends configures how many products per page be seen and offset, only owner of product can view it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Returns all users products with pagnation",
+                "parameters": [
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of products info per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency costs are converted to with current rates",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/check/{checkName}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "sends user check generated previously using product info, only owner of product can do it, check is sent in format chosen by format parameter or Accept header(PDF by default)",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf",
+                    "text/html",
+                    "image/png",
+                    "application/vnd.escpos"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Returns check of user product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Check file name",
+                        "name": "checkName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pdf",
+                            "html",
+                            "png",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Check format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached check",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of cached check",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment with check file name"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "SHA-256 of check content"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Check creation time"
+                            }
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Check not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "416": {
+                        "description": "Requested range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{barcode}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns full info about product user chose to view, only owner of product can view it; cost converted to currency has original cost and rate",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Returns user product full info",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency cost is converted to with current rate",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "archives product but don't delete it from storage, only owner of product can do it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Delete user product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{barcode}/check": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "using info about given product generates PDF check using special PDF template, check is sent in format chosen by format parameter or Accept header(PDF by default)",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf",
+                    "text/html",
+                    "image/png",
+                    "application/vnd.escpos"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Generates check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name, user default template is used if not set",
+                        "name": "template",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency cost is printed in, converted with current rate that is kept with check",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pdf",
+                            "html",
+                            "png",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Check format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{barcode}/checks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns metadata of product checks, newest first, only owner of product can view it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Returns checks generated for product with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of checks per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "creates job generating PDF check for given product, job status can be polled using returned link",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Enqueues check generation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name, user default template is used if not set",
+                        "name": "template",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/checks/{token}": {
+            "get": {
+                "description": "sends check by public share link without authentication, every request uses one download of link",
+                "produces": [
+                    "application/pdf",
+                    "text/html",
+                    "image/png",
+                    "application/vnd.escpos"
+                ],
+                "tags": [
+                    "share"
+                ],
+                "summary": "Returns shared check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pdf",
+                            "html",
+                            "png",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Check format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns all exchange rates ordered by pair, newest first inside pair; rate is price of one unit of base currency in quote currency, it is used from effectiveFrom until the next rate of the pair",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Returns exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "saves exchange rates sent as CSV(base,quote,rate,effectiveFrom with optional header) or JSON array of objects with the same fields, effectiveFrom is YYYY-MM-DD; rate replaces one of the same pair and date, only admins listed in configuration can import rates",
+                "consumes": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Import exchange rates",
+                "parameters": [
+                    {
+                        "description": "rates list",
+                        "name": "rates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns current stock of user products in order they were created",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Returns stock of user products with pagination",
+                "parameters": [
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of products per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/{barcode}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns current stock of user product, it is sum of all its movements, part of it reserved for placed orders and available rest, in total and in every active warehouse",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Returns product stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/{barcode}/movements": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns stock ledger of user product, newest first, movements made by orders have order id",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Returns stock movements of product with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of movements per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "records receipt, sale, adjustment or return of user product in stock ledger of warehouse(warehouse with lowest priority when it isn't set), movements are never changed, wrong one is corrected by adjustment; sale and negative adjustment are rejected when they take goods reserved for placed orders or make warehouse stock negative unless it's allowed by configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Post stock movement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "movement",
+                        "name": "movement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stock.Posted"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user uploads PDF template with layout(JSON) describing where product info is printed and optional TTF font, uploading template with existing name creates its new version",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Upload check template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Layout JSON: width,height, barcode,name,cost and optional date fields with x,y,fontSize, optional labels with x,y,fontSize and text translations by language tag",
+                        "name": "layout",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF template",
+                        "name": "template",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "TTF font",
+                        "name": "font",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns latest versions of user templates and builtin template, marks the one used by default",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Returns all user templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}/default": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "template is used for user checks when no template chosen in request",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Set default template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}/preview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "renders template with sample product data, format is chosen by format parameter or Accept header(PDF by default)",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/pdf",
+                    "text/html",
+                    "image/png",
+                    "application/vnd.escpos"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Preview template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Template version, latest if not set",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pdf",
+                            "html",
+                            "png",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Preview format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "moves quantities of user products from one active warehouse to another in one transaction, every line is recorded as pair of transfer movements in stock ledger; transfer is rejected when it takes goods reserved for placed orders or makes stock of source warehouse negative unless it's allowed by configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Create transfer",
+                "parameters": [
+                    {
+                        "description": "transfer",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transfer.Created"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns transfers between warehouses of user with number of lines, newest first",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Returns user transfers with pagination",
+                "parameters": [
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of transfers per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns transfer between warehouses with its lines, only owner of transfer can view it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Returns user transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/seller": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns seller legal name, tax ID, address and VAT rate printed on user checks, VAT rate is absent when default rate is used",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns seller profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user sets seller legal name, tax ID, address and VAT rate(percent, default rate is used if not set) printed on checks generated after update",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update seller profile",
+                "parameters": [
+                    {
+                        "description": "name,taxId,address,vatRate",
+                        "name": "seller",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler_user.Seller"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns locale, currency and time zone used to format user checks",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns user settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "user sets locale(BCP 47 tag), currency(ISO 4217 code) and time zone(IANA name) used to format amounts, dates and template labels on checks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update user settings",
+                "parameters": [
+                    {
+                        "description": "locale,currency,timeZone",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler_user.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "creates storage location of user, codes are case insensitive and can't be reused after warehouse is archived; warehouse with lowest priority is used for movements without warehouse",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Create warehouse",
+                "parameters": [
+                    {
+                        "description": "warehouse",
+                        "name": "warehouse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/warehouse.Created"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns active warehouses of user ordered by priority",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Returns user warehouses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "warehouse isn't used for new movements, transfers and orders anymore, its movements stay in stock history; only warehouse without stock and reservations can be archived",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Archive warehouse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Warehouse code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/{code}/stock": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns current stock of user products in warehouse in order products were created",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Returns stock of warehouse with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Warehouse code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Next page to retrieve",
+                        "name": "p",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of products per page",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.JSONResult"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.Registration": {
+            "type": "object",
+            "required": [
+                "email",
+                "fullName",
+                "login",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "test@test.com"
+                },
+                "fullName": {
+                    "type": "string",
+                    "maxLength": 75,
+                    "minLength": 3,
+                    "example": "Ivanov Ivan Ivanovich"
+                },
+                "login": {
+                    "type": "string",
+                    "maxLength": 40,
+                    "minLength": 3,
+                    "example": "Login123"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 40,
+                    "minLength": 6,
+                    "example": "password"
+                }
+            }
+        },
+        "check.BatchFilter": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "maxCost": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "minCost": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "nameContains": {
+                    "type": "string",
+                    "maxLength": 60
+                }
+            }
+        },
+        "check.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "filter": {
+                    "$ref": "#/definitions/check.BatchFilter"
+                },
+                "format": {
+                    "type": "string",
+                    "default": "pdf",
+                    "enum": [
+                        "pdf",
+                        "html",
+                        "png",
+                        "escpos"
+                    ]
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "check.SendRequest": {
+            "type": "object",
+            "required": [
+                "recipient"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "recipient": {
+                    "type": "string",
+                    "maxLength": 254
+                }
+            }
+        },
+        "check.ShareRequest": {
+            "type": "object",
+            "properties": {
+                "maxDownloads": {
+                    "type": "integer",
+                    "default": 0,
+                    "maximum": 100000,
+                    "minimum": 0
+                },
+                "ttlSeconds": {
+                    "type": "integer",
+                    "default": 604800,
+                    "minimum": 0
+                }
+            }
+        },
+        "coupon.Created": {
+            "type": "object",
+            "required": [
+                "code",
+                "kind"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "code": {
+                    "type": "string",
+                    "default": "SPRING10"
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "EUR"
+                },
+                "kind": {
+                    "type": "string",
+                    "default": "percent",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ]
+                },
+                "maxUses": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "maxUsesPerUser": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "minOrderValue": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "percent": {
+                    "type": "integer",
+                    "default": 1000,
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validTo": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler_auth.Auth": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "maxLength": 40,
+                    "minLength": 3,
+                    "example": "Login123"
+                },
+                "password": {
+                    "type": "string",
+                    "default": "password",
+                    "maxLength": 40,
+                    "minLength": 6
+                }
+            }
+        },
+        "internal_handler_inventory.Settings": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "webhook": {
+                    "type": "string",
+                    "default": "https://example.com/hooks/stock",
+                    "maxLength": 500
+                }
+            }
+        },
+        "internal_handler_user.Seller": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "default": "1 Main St, Springfield",
+                    "maxLength": 255
+                },
+                "name": {
+                    "type": "string",
+                    "default": "Products LLC",
+                    "maxLength": 120
+                },
+                "taxId": {
+                    "type": "string",
+                    "default": "1234567890",
+                    "maxLength": 32
+                },
+                "vatRate": {
+                    "type": "number",
+                    "default": 20,
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_handler_user.Settings": {
+            "type": "object",
+            "required": [
+                "currency",
+                "locale",
+                "timeZone"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "default": "USD"
+                },
+                "locale": {
+                    "type": "string",
+                    "default": "en-US",
+                    "maxLength": 35
+                },
+                "timeZone": {
+                    "type": "string",
+                    "default": "UTC",
+                    "maxLength": 64
+                }
+            }
+        },
+        "inventory.Threshold": {
+            "type": "object",
+            "required": [
+                "threshold"
+            ],
+            "properties": {
+                "threshold": {
+                    "type": "integer",
+                    "default": 10,
+                    "maximum": 1000000000,
+                    "minimum": 0
+                }
+            }
+        },
+        "order.Created": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "coupons": {
+                    "type": "array",
+                    "maxItems": 3,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "customer": {
+                    "description": "Customer is email of buyer, uses of coupons limited per customer are counted by it",
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": "buyer@test.com"
+                },
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/order.CreatedItem"
+                    }
+                }
+            }
+        },
+        "order.CreatedItem": {
+            "type": "object",
+            "required": [
+                "barcode",
+                "quantity"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "default": "1234567890"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "default": 1,
+                    "maximum": 10000,
+                    "minimum": 1
+                }
+            }
+        },
+        "order.Transition": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "default": "placed"
+                }
+            }
+        },
+        "product.Created": {
+            "type": "object",
+            "required": [
+                "barcode",
+                "cost",
                 "desc",
                 "name"
             ],
@@ -544,9 +3751,13 @@ const docTemplate = `{
                     "default": "1234567890"
                 },
                 "cost": {
-                    "type": "integer",
-                    "default": 100,
-                    "minimum": 10
+                    "type": "string",
+                    "default": "9.99",
+                    "maxLength": 20
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "EUR"
                 },
                 "desc": {
                     "type": "string",
@@ -570,6 +3781,116 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "stock.Posted": {
+            "type": "object",
+            "required": [
+                "kind",
+                "quantity"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "default": "receipt",
+                    "enum": [
+                        "receipt",
+                        "sale",
+                        "adjustment",
+                        "return"
+                    ]
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "quantity": {
+                    "type": "integer",
+                    "default": 10,
+                    "maximum": 1000000,
+                    "minimum": -1000000
+                },
+                "warehouse": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "transfer.Created": {
+            "type": "object",
+            "required": [
+                "from",
+                "items",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "default": "MAIN"
+                },
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/transfer.CreatedItem"
+                    }
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "to": {
+                    "type": "string",
+                    "default": "STORE"
+                }
+            }
+        },
+        "transfer.CreatedItem": {
+            "type": "object",
+            "required": [
+                "barcode",
+                "quantity"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "default": "1234567890"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "default": 1,
+                    "maximum": 1000000,
+                    "minimum": 1
+                }
+            }
+        },
+        "warehouse.Created": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "code": {
+                    "type": "string",
+                    "default": "MAIN"
+                },
+                "name": {
+                    "type": "string",
+                    "default": "Main warehouse",
+                    "maxLength": 60
+                },
+                "priority": {
+                    "type": "integer",
+                    "default": 0,
+                    "maximum": 1000000,
+                    "minimum": -1000000
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -591,6 +3912,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "User products service API using swagger 2.0.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
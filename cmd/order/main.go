@@ -10,10 +10,15 @@ import (
 
 	"github.com/AnisaForWork/user_orders/internal/config"
 	"github.com/AnisaForWork/user_orders/internal/handler"
+	"github.com/AnisaForWork/user_orders/internal/provider/mail"
 	"github.com/AnisaForWork/user_orders/internal/provider/token"
 	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
 	"github.com/AnisaForWork/user_orders/internal/server"
 	"github.com/AnisaForWork/user_orders/internal/service"
+	"github.com/AnisaForWork/user_orders/internal/service/delivery"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/signature"
 	"github.com/AnisaForWork/user_orders/migration"
 
 	"github.com/jmoiron/sqlx"
@@ -70,7 +75,50 @@ func main() {
 			"place": "system(main)",
 		}).WithError(err).Panic("Cant access service config")
 	}
-	serv := service.NewService(repo, provider, servCfg)
+
+	st, err := initStore(cfg, repo)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"place": "system(main)",
+		}).WithError(err).Panic("Initialization of check storage failed")
+	}
+
+	var signer *signature.Signer
+	if sigCfg := cfg.SignatureConfig(); sigCfg.Enabled {
+		signer, err = signature.NewSigner(sigCfg)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"place": "system(main)",
+			}).WithError(err).Panic("Initialization of check signer failed")
+		}
+	}
+
+	mailer, err := initMailer(servCfg.Mail)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"place": "system(main)",
+		}).WithError(err).Panic("Initialization of mailer failed")
+	}
+
+	assets, err := render.NewAssets(servCfg.Render.CacheSize)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"place": "system(main)",
+		}).WithError(err).Panic("Initialization of render assets cache failed")
+	}
+
+	serv := service.NewService(repo, provider, st, signer, mailer, assets, servCfg)
+
+	if servCfg.Rates.File != "" {
+		n, err := serv.LoadRatesFile(context.Background(), servCfg.Rates.File)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"place": "system(main)",
+				"file":  servCfg.Rates.File,
+			}).WithError(err).Panic("Loading of exchange rates failed")
+		}
+		log.WithFields(log.Fields{"file": servCfg.Rates.File, "rates": n}).Info("Exchange rates loaded")
+	}
 
 	srvWPrv := ServicesAndProviders{
 		serv,
@@ -84,6 +132,18 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	go serv.RunJobs(ctx)
+
+	go serv.RunDeliveries(ctx)
+
+	go serv.RunRetention(ctx)
+
+	go serv.RunReservations(ctx)
+
+	go serv.RunAlerts(ctx)
+
+	go assets.Watch(ctx)
+
 	go func() {
 		if err := srv.Run(ctx); err != nil {
 			log.WithFields(log.Fields{"place": "system(main)"}).WithError(err).Error("Server failed during run")
@@ -163,3 +223,44 @@ func initDBwithRetry(cfg *config.Configurator, migr *migration.Migratory) (repo
 
 	return repo, err
 }
+
+// initStore returns check storage backend chosen in configuration
+func initStore(cfg *config.Configurator, repo *mysql.Repository) (store.CheckStore, error) {
+	stCfg, err := cfg.StorageConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"storageType": stCfg.Type,
+	}).Info("Getting check storage")
+
+	switch stCfg.Type {
+	case store.TypeFS, "":
+		return store.NewFSStore(stCfg.PathToDir)
+	case store.TypeS3:
+		return store.NewS3Store(stCfg.S3)
+	case store.TypeMysql:
+		return store.NewBlobStore(repo.DB()), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", stCfg.Type)
+	}
+}
+
+// initMailer returns mailer chosen in configuration, nil if sending checks by email is disabled
+func initMailer(mailCfg *config.Mail) (delivery.Mailer, error) {
+	log.WithFields(log.Fields{
+		"mailerType": mailCfg.Type,
+	}).Info("Getting mailer")
+
+	switch mailCfg.Type {
+	case mail.TypeOutbox, "":
+		return mail.NewOutboxMailer(mailCfg.OutboxDir)
+	case mail.TypeSMTP:
+		return mail.NewSMTPMailer(mailCfg.SMTP), nil
+	case mail.TypeNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer type %q", mailCfg.Type)
+	}
+}
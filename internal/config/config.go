@@ -158,8 +158,17 @@ func (cfg *Configurator) DBConfig() (*DB, error) {
 
 // Service holds config information all defined services
 type Service struct {
-	Auth    *Auth
-	Product *Product
+	Auth      *Auth
+	Product   *Product
+	Template  *Template
+	Jobs      *Jobs
+	Render    *Render
+	Share     *Share
+	Mail      *Mail
+	Retention *Retention
+	Rates     *Rates
+	Stock     *Stock
+	Alerts    *Alerts
 }
 
 // Auth holds config information required for Authentication service
@@ -185,10 +194,28 @@ func (cfg *Configurator) ServiceConfig() (*Service, error) {
 	}
 
 	product := cfg.ProductConfig()
+	template := cfg.TemplateConfig()
+	jobs := cfg.JobsConfig()
+	render := cfg.RenderConfig()
+	share := cfg.ShareConfig()
+	mail := cfg.MailConfig()
+	retention := cfg.RetentionConfig()
+	rates := cfg.RatesConfig()
+	stock := cfg.StockConfig()
+	alerts := cfg.AlertsConfig()
 
 	s := &Service{
-		Auth:    auth,
-		Product: product,
+		Auth:      auth,
+		Product:   product,
+		Template:  template,
+		Jobs:      jobs,
+		Render:    render,
+		Share:     share,
+		Mail:      mail,
+		Retention: retention,
+		Rates:     rates,
+		Stock:     stock,
+		Alerts:    alerts,
 	}
 	return s, nil
 }
@@ -256,6 +283,9 @@ type Product struct {
 	TimeFormat     string
 	TemplateW      float64
 	TemplateH      float64
+	BatchMaxChecks int
+	BatchWorkers   int
+	VATRate        float64
 }
 
 // ProductConfig returns configuration for product service
@@ -273,10 +303,342 @@ func (cfg *Configurator) ProductConfig() *Product {
 		TimeFormat:     viper.GetString("product.timeFormat"),
 		TemplateW:      viper.GetFloat64("product.templateW"),
 		TemplateH:      viper.GetFloat64("product.templateH"),
+		BatchMaxChecks: viper.GetInt("product.batchMaxChecks"),
+		BatchWorkers:   viper.GetInt("product.batchWorkers"),
+		VATRate:        viper.GetFloat64("product.vatRate"),
 	}
 	return pr
 }
 
+// Template holds config information required for check template registry
+type Template struct {
+	PathToTemplates string
+	MaxSizeBytes    int64
+}
+
+// TemplateConfig returns configuration for template service
+func (cfg *Configurator) TemplateConfig() *Template {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+	}).Info("reading template service configuration from file")
+
+	tpl := &Template{
+		PathToTemplates: viper.GetString("template.pathToTemplates"),
+		MaxSizeBytes:    viper.GetInt64("template.maxSizeBytes"),
+	}
+	return tpl
+}
+
+// Jobs holds config information for asynchronous check generation
+type Jobs struct {
+	Workers          int
+	MaxQueuedPerUser int
+	MaxAttempts      int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+	PollInterval     time.Duration
+	Lease            time.Duration
+}
+
+// JobsConfig returns configuration for job service
+func (cfg *Configurator) JobsConfig() *Jobs {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+	}).Info("reading job service configuration from file")
+
+	j := &Jobs{
+		Workers:          viper.GetInt("jobs.workers"),
+		MaxQueuedPerUser: viper.GetInt("jobs.maxQueuedPerUser"),
+		MaxAttempts:      viper.GetInt("jobs.maxAttempts"),
+		Backoff:          viper.GetDuration("jobs.backoff"),
+		MaxBackoff:       viper.GetDuration("jobs.maxBackoff"),
+		PollInterval:     viper.GetDuration("jobs.pollInterval"),
+		Lease:            viper.GetDuration("jobs.lease"),
+	}
+	return j
+}
+
+// Storage holds config information for check storage backend
+type Storage struct {
+	Type      string
+	PathToDir string
+	S3        *S3
+}
+
+// S3 holds config information for S3 compatible storage
+type S3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	PathStyle bool
+	Timeout   time.Duration
+	AccessKey string
+	SecretKey string
+}
+
+// StorageConfig returns configuration for check storage
+func (cfg *Configurator) StorageConfig() (*Storage, error) {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+		"source2": ".env",
+	}).Info("reading check storage configuration from file")
+
+	st := &Storage{
+		Type:      viper.GetString("storage.type"),
+		PathToDir: viper.GetString("product.pathToCheckDir"),
+	}
+
+	if st.Type != "s3" {
+		return st, nil
+	}
+
+	accessKey, ok := os.LookupEnv("S3_ACCESS_KEY")
+	if !ok {
+		return nil, fmt.Errorf("S3_ACCESS_KEY was not found in env")
+	}
+
+	secretKey, ok := os.LookupEnv("S3_SECRET_KEY")
+	if !ok {
+		return nil, fmt.Errorf("S3_SECRET_KEY was not found in env")
+	}
+
+	st.S3 = &S3{
+		Endpoint:  viper.GetString("storage.s3.endpoint"),
+		Region:    viper.GetString("storage.s3.region"),
+		Bucket:    viper.GetString("storage.s3.bucket"),
+		PathStyle: viper.GetBool("storage.s3.pathStyle"),
+		Timeout:   viper.GetDuration("storage.s3.timeout"),
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+	return st, nil
+}
+
+// Signature holds config information for signing generated checks
+type Signature struct {
+	Enabled      bool
+	CertFile     string
+	KeyFile      string
+	Name         string
+	ContentsSize int
+}
+
+// SignatureConfig returns configuration for check signing
+func (cfg *Configurator) SignatureConfig() *Signature {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+	}).Info("reading check signature configuration from file")
+
+	sig := &Signature{
+		Enabled:      viper.GetBool("signature.enabled"),
+		CertFile:     viper.GetString("signature.certFile"),
+		KeyFile:      viper.GetString("signature.keyFile"),
+		Name:         viper.GetString("signature.name"),
+		ContentsSize: viper.GetInt("signature.contentsSize"),
+	}
+	return sig
+}
+
+// Render holds config information for non PDF check formats
+type Render struct {
+	PNGWidth      int
+	ESCPOSColumns int
+	Fonts         map[string]string
+	CacheSize     int
+}
+
+// RenderConfig returns configuration for check renderers
+func (cfg *Configurator) RenderConfig() *Render {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+	}).Info("reading check render configuration from file")
+
+	r := &Render{
+		PNGWidth:      viper.GetInt("render.pngWidth"),
+		ESCPOSColumns: viper.GetInt("render.escposColumns"),
+		Fonts:         viper.GetStringMapString("render.fonts"),
+		CacheSize:     viper.GetInt("render.cacheSize"),
+	}
+	return r
+}
+
+// Share holds config information for public check links
+type Share struct {
+	Secret     []byte
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// ShareConfig returns configuration for public check links, links are disabled when secret is empty
+func (cfg *Configurator) ShareConfig() *Share {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+		"source2": ".env",
+	}).Info("reading check share configuration from file")
+
+	sh := &Share{
+		Secret:     []byte(os.Getenv("CHECK_SHARE_SECRET")),
+		DefaultTTL: viper.GetDuration("share.defaultTTL"),
+		MaxTTL:     viper.GetDuration("share.maxTTL"),
+	}
+	return sh
+}
+
+// Mail holds config information for sending checks by email
+type Mail struct {
+	Type         string
+	From         string
+	OutboxDir    string
+	Workers      int
+	MaxAttempts  int
+	Backoff      time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Lease        time.Duration
+	SMTP         *SMTP
+}
+
+// SMTP holds config information for SMTP server
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	StartTLS bool
+	Timeout  time.Duration
+}
+
+// MailConfig returns configuration for sending checks by email
+func (cfg *Configurator) MailConfig() *Mail {
+	log.WithFields(log.Fields{
+		"source1": viper.ConfigFileUsed(),
+		"source2": ".env",
+	}).Info("reading mail configuration from file")
+
+	m := &Mail{
+		Type:         viper.GetString("mail.type"),
+		From:         viper.GetString("mail.from"),
+		OutboxDir:    viper.GetString("mail.outboxDir"),
+		Workers:      viper.GetInt("mail.workers"),
+		MaxAttempts:  viper.GetInt("mail.maxAttempts"),
+		Backoff:      viper.GetDuration("mail.backoff"),
+		MaxBackoff:   viper.GetDuration("mail.maxBackoff"),
+		PollInterval: viper.GetDuration("mail.pollInterval"),
+		Lease:        viper.GetDuration("mail.lease"),
+		SMTP: &SMTP{
+			Host:     viper.GetString("mail.smtp.host"),
+			Port:     viper.GetInt("mail.smtp.port"),
+			Username: viper.GetString("mail.smtp.username"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			StartTLS: viper.GetBool("mail.smtp.startTLS"),
+			Timeout:  viper.GetDuration("mail.smtp.timeout"),
+		},
+	}
+	return m
+}
+
+// Retention holds config information for cleanup of old checks
+type Retention struct {
+	Interval         time.Duration
+	MaxAge           time.Duration
+	MaxChecksPerUser int
+	BatchSize        int
+	Repair           bool
+	OrphanGrace      time.Duration
+}
+
+// RetentionConfig returns configuration for check retention job
+func (cfg *Configurator) RetentionConfig() *Retention {
+	log.WithFields(log.Fields{
+		"source": viper.ConfigFileUsed(),
+	}).Info("reading retention configuration from file")
+
+	r := &Retention{
+		Interval:         viper.GetDuration("retention.interval"),
+		MaxAge:           viper.GetDuration("retention.maxAge"),
+		MaxChecksPerUser: viper.GetInt("retention.maxChecksPerUser"),
+		BatchSize:        viper.GetInt("retention.batchSize"),
+		Repair:           viper.GetBool("retention.repair"),
+		OrphanGrace:      viper.GetDuration("retention.orphanGrace"),
+	}
+	return r
+}
+
+// Rates holds config information for currency conversion by exchange rates
+type Rates struct {
+	File     string
+	Rounding string
+	Admins   []string
+}
+
+// RatesConfig returns configuration for exchange rates
+func (cfg *Configurator) RatesConfig() *Rates {
+	log.WithFields(log.Fields{
+		"source": viper.ConfigFileUsed(),
+	}).Info("reading exchange rates configuration from file")
+
+	r := &Rates{
+		File:     viper.GetString("rates.file"),
+		Rounding: viper.GetString("rates.rounding"),
+		Admins:   viper.GetStringSlice("rates.admins"),
+	}
+	return r
+}
+
+// Stock holds config information for inventory tracking
+type Stock struct {
+	Negative       string
+	Strategy       string
+	ReservationTTL time.Duration
+	SweepInterval  time.Duration
+	SweepBatch     int
+}
+
+// StockConfig returns configuration for stock service
+func (cfg *Configurator) StockConfig() *Stock {
+	log.WithFields(log.Fields{
+		"source": viper.ConfigFileUsed(),
+	}).Info("reading stock configuration from file")
+
+	st := &Stock{
+		Negative:       viper.GetString("stock.negative"),
+		Strategy:       viper.GetString("stock.strategy"),
+		ReservationTTL: viper.GetDuration("stock.reservationTTL"),
+		SweepInterval:  viper.GetDuration("stock.sweepInterval"),
+		SweepBatch:     viper.GetInt("stock.sweepBatch"),
+	}
+	return st
+}
+
+// Alerts holds config information for low stock alerts and reorder suggestions
+type Alerts struct {
+	Interval       time.Duration
+	BatchSize      int
+	Channels       []string
+	From           string
+	WebhookTimeout time.Duration
+	SalesDays      int
+	CoverDays      int
+}
+
+// AlertsConfig returns configuration for low stock alerts
+func (cfg *Configurator) AlertsConfig() *Alerts {
+	log.WithFields(log.Fields{
+		"source": viper.ConfigFileUsed(),
+	}).Info("reading alerts configuration from file")
+
+	a := &Alerts{
+		Interval:       viper.GetDuration("alerts.interval"),
+		BatchSize:      viper.GetInt("alerts.batchSize"),
+		Channels:       viper.GetStringSlice("alerts.channels"),
+		From:           viper.GetString("mail.from"),
+		WebhookTimeout: viper.GetDuration("alerts.webhookTimeout"),
+		SalesDays:      viper.GetInt("alerts.salesDays"),
+		CoverDays:      viper.GetInt("alerts.coverDays"),
+	}
+	return a
+}
+
 type JWTProvider struct {
 	Host         string
 	Port         int
@@ -0,0 +1,498 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	prhandler "github.com/AnisaForWork/user_orders/internal/handler/product"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary      Returns check metadata
+// @Description  returns creation time, size, SHA-256, template version and product snapshot of check, only owner of product can view it
+// @Tags         check
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      int true  "Check id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /checks/{id} [get]
+func (ch *Router) checkInfo(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	info, err := ch.service.CheckInfo(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "checkInfo",
+			"userLogin": login,
+			"check":     id,
+		}).WithError(err).Error("Error retrieving check")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Check", prhandler.NewCheck(info)))
+}
+
+// @Summary      Delete check
+// @Description  removes check record and stored file, only owner of product can do it; issued fiscal receipts can't be deleted
+// @Tags         check
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      int true  "Check id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /checks/{id} [delete]
+func (ch *Router) delete(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	err = ch.service.DeleteCheck(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "delete",
+			"userLogin": login,
+			"check":     id,
+		}).WithError(err).Error("Error deleting check")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Check deleted"))
+}
+
+// Verification model used to parse signature verification result into JSON response
+type Verification struct {
+	Valid    bool             `json:"valid"`
+	Modified bool             `json:"modified"`
+	Signer   string           `json:"signer,omitempty"`
+	SignedAt *time.Time       `json:"signedAt,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Check    *prhandler.Check `json:"check,omitempty"`
+}
+
+// @Summary      Verify check signature
+// @Description  checks that uploaded PDF is signed by service and wasn't modified, returns matching check record if user owns it
+// @Tags         check
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        check  formData  file  true  "Signed PDF check"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      413  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Failure      501  {object}  response.JSONResult
+// @Router       /checks/verify [post]
+func (ch *Router) verify(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	header, err := c.FormFile("check")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("check", "PDF file is required"))
+		return
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("check", "can't read file"))
+		return
+	}
+	defer f.Close()
+
+	v, err := ch.service.VerifyCheck(c.Request.Context(), f, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "verify",
+			"userLogin": login,
+		}).WithError(err).Error("Error verifying check")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := Verification{
+		Valid:    v.Valid,
+		Modified: v.Modified,
+		Signer:   v.Signer,
+		Error:    v.Error,
+	}
+	if !v.SignedAt.IsZero() {
+		res.SignedAt = &v.SignedAt
+	}
+	if v.Check != nil {
+		info := prhandler.NewCheck(v.Check)
+		res.Check = &info
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Verification", res))
+}
+
+// BatchFilter used to parse conditions products of batch are chosen by,
+// cost bounds are decimal strings in currency, only products in it are chosen when bounds are set
+type BatchFilter struct {
+	NameContains string `json:"nameContains" binding:"max=60"`
+	MinCost      string `json:"minCost" binding:"max=20"`
+	MaxCost      string `json:"maxCost" binding:"max=20"`
+	Currency     string `json:"currency" binding:"omitempty,len=3,alpha"`
+}
+
+// BatchRequest used to parse request body of batch check generation
+type BatchRequest struct {
+	Barcodes []string     `json:"barcodes" binding:"omitempty,max=1000,dive,len=10,numeric"`
+	Filter   *BatchFilter `json:"filter"`
+	Template string       `json:"template"`
+	Format   string       `json:"format" binding:"omitempty,oneof=pdf html png escpos" default:"pdf"`
+}
+
+// @Summary      Generate checks in ZIP archive
+// @Description  generates checks for products chosen by barcodes or, if there are none, by filter and streams ZIP archive with them, manifest.json in archive lists result for every product(ok, not_found, not_owner, archived, failed)
+// @Tags         check
+// @Accept       json
+// @Produce      application/zip
+// @Security     ApiKeyAuth
+// @Param        batch  body  check.BatchRequest true "barcodes or filter, template and format(pdf by default)"
+// @Success      200  {file}    ZipFile
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /checks/batch [post]
+func (ch *Router) batch(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	if len(req.Barcodes) == 0 && req.Filter == nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcodes", "barcodes or filter should be set"))
+		return
+	}
+
+	if req.Template != "" && !ch.tplNameRegex.MatchString(req.Template) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	batchReq := product.BatchRequest{
+		Barcodes: req.Barcodes,
+		Template: req.Template,
+		Format:   render.FormatPDF,
+	}
+	if req.Format != "" {
+		batchReq.Format = render.Format(req.Format)
+	}
+	if req.Filter != nil {
+		batchReq.Filter = product.ProductFilter{
+			NameContains: req.Filter.NameContains,
+		}
+
+		var err error
+		if req.Filter.MinCost != "" {
+			if batchReq.Filter.MinCost, err = money.Parse(req.Filter.MinCost, req.Filter.Currency); err != nil {
+				c.JSON(http.StatusBadRequest, validator.MoneyError("minCost", "currency", err))
+				return
+			}
+		}
+		if req.Filter.MaxCost != "" {
+			if batchReq.Filter.MaxCost, err = money.Parse(req.Filter.MaxCost, req.Filter.Currency); err != nil {
+				c.JSON(http.StatusBadRequest, validator.MoneyError("maxCost", "currency", err))
+				return
+			}
+		}
+	}
+
+	lg := log.WithFields(logrus.Fields{
+		"handler":   "check",
+		"func":      "batch",
+		"userLogin": login,
+	})
+
+	b, err := ch.service.BatchChecks(c.Request.Context(), batchReq, login)
+	if err != nil {
+		lg.WithError(err).Error("Error preparing checks batch")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.Header("Content-type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="checks_%s.zip"`, time.Now().Format("2006_01_02-15_04_05")))
+	c.Status(http.StatusOK)
+	if err := b.WriteZip(c.Request.Context(), c.Writer); err != nil {
+		lg.WithError(err).Warn("Could not send checks batch")
+	}
+}
+
+// ShareRequest used to parse request body of share link creation
+type ShareRequest struct {
+	TTLSeconds   int `json:"ttlSeconds" binding:"min=0" default:"604800"`
+	MaxDownloads int `json:"maxDownloads" binding:"min=0,max=100000" default:"0"`
+}
+
+// Share model used to parse check share link into JSON response
+type Share struct {
+	ID           int64     `json:"id"`
+	FileName     string    `json:"filename"`
+	URL          string    `json:"url,omitempty"`
+	Expires      time.Time `json:"expires"`
+	MaxDownloads int       `json:"maxDownloads,omitempty"`
+	Downloads    int       `json:"downloads"`
+	Revoked      bool      `json:"revoked"`
+	Created      time.Time `json:"created"`
+}
+
+func newShare(sh *product.Share) Share {
+	res := Share{
+		ID:           sh.ID,
+		FileName:     sh.FileName,
+		Expires:      sh.Expires,
+		MaxDownloads: sh.MaxDownloads,
+		Downloads:    sh.Downloads,
+		Revoked:      sh.Revoked,
+		Created:      sh.Created,
+	}
+	if sh.Token != "" {
+		res.URL = "/public/checks/" + sh.Token
+	}
+	return res
+}
+
+// @Summary      Create public link to check
+// @Description  creates signed link check can be downloaded by without authentication until it expires, is revoked or max downloads count is reached, only owner of product can do it
+// @Tags         check
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 name   path      string true  "Check file name"
+// @Param        share  body      check.ShareRequest false "link lifetime(default from configuration) and max downloads(0 - unlimited)"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Failure      501  {object}  response.JSONResult
+// @Router       /checks/{name}/share [post]
+func (ch *Router) share(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.Param("name")
+
+	var req ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	sh, err := ch.service.CreateShare(c.Request.Context(), name, time.Duration(req.TTLSeconds)*time.Second, req.MaxDownloads, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "share",
+			"userLogin": login,
+			"check":     name,
+		}).WithError(err).Error("Error creating check share link")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Share", newShare(sh)))
+}
+
+// @Summary      Returns public links to checks
+// @Description  returns share links of user checks with download counters, newest first
+// @Tags         check
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 check  query     string false  "Check file name, links of all checks are returned if not set"
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /checks/shares [get]
+func (ch *Router) shares(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.Query("check")
+
+	shares, err := ch.service.CheckShares(c.Request.Context(), name, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "shares",
+			"userLogin": login,
+			"check":     name,
+		}).WithError(err).Error("Error retrieving check share links")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Share, len(shares))
+	for i := range shares {
+		res[i] = newShare(&shares[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Shares", res))
+}
+
+// @Summary      Revoke public link to check
+// @Description  disables share link, check can't be downloaded by it anymore, only owner of product can do it
+// @Tags         check
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      int true  "Share link id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /checks/shares/{id} [delete]
+func (ch *Router) revokeShare(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	err = ch.service.RevokeShare(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "revokeShare",
+			"userLogin": login,
+			"share":     id,
+		}).WithError(err).Error("Error revoking check share link")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Share link revoked"))
+}
+
+// SendRequest used to parse request body of sending check by email
+type SendRequest struct {
+	Recipient string `json:"recipient" binding:"required,email,max=254"`
+	Message   string `json:"message" binding:"max=1000"`
+}
+
+// @Summary      Send check by email
+// @Description  queues sending of email with check PDF attached to recipient, delivery state is shown in check metadata, only owner of product can do it
+// @Tags         check
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 name   path      string true  "Check file name"
+// @Param        send   body      check.SendRequest true "recipient email address and optional message"
+// @Success      202  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Failure      501  {object}  response.JSONResult
+// @Router       /checks/{name}/send [post]
+func (ch *Router) send(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.Param("name")
+
+	var req SendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	d, err := ch.service.SendCheck(c.Request.Context(), name, req.Recipient, req.Message, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "check",
+			"func":      "send",
+			"userLogin": login,
+			"check":     name,
+		}).WithError(err).Error("Error queueing check email")
+
+		errInf := ch.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.CreateJSONResult("Delivery", prhandler.NewDelivery(d)))
+}
@@ -0,0 +1,77 @@
+package check
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/delivery"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/signature"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call check related service level logic
+type Service interface {
+	CheckInfo(ctx context.Context, id int64, login string) (*product.Check, error)
+	DeleteCheck(ctx context.Context, id int64, login string) error
+	VerifyCheck(ctx context.Context, pdf io.Reader, login string) (*product.CheckVerification, error)
+	BatchChecks(ctx context.Context, req product.BatchRequest, login string) (*product.Batch, error)
+	CreateShare(ctx context.Context, fileName string, ttl time.Duration, maxDownloads int, login string) (*product.Share, error)
+	CheckShares(ctx context.Context, fileName string, login string) ([]product.Share, error)
+	RevokeShare(ctx context.Context, id int64, login string) error
+	SendCheck(ctx context.Context, fileName string, recipient string, message string, login string) (*product.Delivery, error)
+}
+
+type Router struct {
+	service      Service
+	errMapper    mapper.ErrorMapper
+	tplNameRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:              mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			mysql.ErrReceiptIssued:       mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Issued fiscal receipt can't be deleted"},
+			product.ErrNoSigning:         mapper.ErrorInfo{StatusCode: http.StatusNotImplemented, Msg: "Checks are not signed"},
+			product.ErrTooLarge:          mapper.ErrorInfo{StatusCode: http.StatusRequestEntityTooLarge, Msg: "File is too large"},
+			product.ErrBatchEmpty:        mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "No products found for batch"},
+			product.ErrBatchTooLarge:     mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Too many products in batch"},
+			product.ErrNoSharing:         mapper.ErrorInfo{StatusCode: http.StatusNotImplemented, Msg: "Checks sharing is not configured"},
+			product.ErrShareTTL:          mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Link lifetime is too long"},
+			delivery.ErrNoMailer:         mapper.ErrorInfo{StatusCode: http.StatusNotImplemented, Msg: "Sending checks by email is not configured"},
+			delivery.ErrInvalidRecipient: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Recipient should be email address"},
+			signature.ErrNotSigned:       mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Document is not signed"},
+			signature.ErrMalformedPDF:    mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Document is not valid PDF"},
+		},
+	)
+
+	tplNameRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{3,40}$`)
+
+	router := &Router{
+		service:      service,
+		errMapper:    mapping,
+		tplNameRegex: tplNameRegex,
+	}
+
+	return router
+}
+
+func (ch *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/verify", ch.verify)
+	r.POST("/batch", ch.batch)
+	r.POST("/:name/share", ch.share)
+	r.POST("/:name/send", ch.send)
+	r.GET("/shares", ch.shares)
+	r.DELETE("/shares/:id", ch.revokeShare)
+	r.GET("/:id", ch.checkInfo)
+	r.DELETE("/:id", ch.delete)
+	return r
+}
@@ -0,0 +1,220 @@
+package coupon
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Created used to parse request body with new coupon, percent coupons take percent in basis points(1000 - 10%),
+// fixed ones take amount, amounts are decimal strings in currency, omitted bounds and limits mean there are none
+type Created struct {
+	Code           string     `json:"code" binding:"required" default:"SPRING10"`
+	Kind           string     `json:"kind" binding:"required,oneof=percent fixed" default:"percent"`
+	Percent        int        `json:"percent" binding:"min=0,max=10000" default:"1000"`
+	Amount         string     `json:"amount" binding:"max=20" default:""`
+	MinOrderValue  string     `json:"minOrderValue" binding:"max=20" default:""`
+	Currency       string     `json:"currency" binding:"omitempty,len=3,alpha" default:"EUR"`
+	ValidFrom      *time.Time `json:"validFrom"`
+	ValidTo        *time.Time `json:"validTo"`
+	MaxUses        *int       `json:"maxUses" binding:"omitempty,min=1"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser" binding:"omitempty,min=1"`
+}
+
+// Coupon model used to parse coupon into JSON response
+type Coupon struct {
+	Code           string       `json:"code"`
+	Kind           string       `json:"kind"`
+	Percent        int          `json:"percent,omitempty"`
+	Amount         *money.Money `json:"amount,omitempty"`
+	MinOrderValue  *money.Money `json:"minOrderValue,omitempty"`
+	ValidFrom      *time.Time   `json:"validFrom,omitempty"`
+	ValidTo        *time.Time   `json:"validTo,omitempty"`
+	MaxUses        *int         `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int         `json:"maxUsesPerUser,omitempty"`
+	Uses           int          `json:"uses"`
+	Disabled       bool         `json:"disabled"`
+	Created        time.Time    `json:"created"`
+}
+
+func newCoupon(c *coupon.Coupon) Coupon {
+	res := Coupon{
+		Code:           c.Code,
+		Kind:           string(c.Kind),
+		Percent:        c.Percent,
+		ValidFrom:      c.ValidFrom,
+		ValidTo:        c.ValidTo,
+		MaxUses:        c.MaxUses,
+		MaxUsesPerUser: c.MaxUsesPerUser,
+		Uses:           c.Uses,
+		Disabled:       c.Disabled,
+		Created:        c.Created,
+	}
+	if c.Amount.Amount != 0 {
+		res.Amount = &c.Amount
+	}
+	if c.MinOrderValue.Amount != 0 {
+		res.MinOrderValue = &c.MinOrderValue
+	}
+	return res
+}
+
+// @Summary      Create coupon
+// @Description  creates percentage or fixed amount coupon with optional validity window, usage limits per code and per user and minimum order value, codes are case insensitive; coupon with amount or minimum applies only to orders in its currency
+// @Tags         coupon
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        coupon  body      coupon.Created true "coupon"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /coupons/ [post]
+func (cp *Router) create(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Created
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	if !cp.codeRegex.MatchString(req.Code) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("code", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	var amount, minOrder money.Money
+	if req.Kind == string(coupon.KindFixed) {
+		var err error
+		if amount, err = money.Parse(req.Amount, req.Currency); err != nil {
+			c.JSON(http.StatusBadRequest, validator.MoneyError("amount", "currency", err))
+			return
+		}
+	}
+	if req.MinOrderValue != "" {
+		var err error
+		if minOrder, err = money.Parse(req.MinOrderValue, req.Currency); err != nil {
+			c.JSON(http.StatusBadRequest, validator.MoneyError("minOrderValue", "currency", err))
+			return
+		}
+	}
+
+	cpn, err := cp.service.CreateCoupon(c.Request.Context(), coupon.Coupon{
+		Code:           req.Code,
+		Kind:           coupon.Kind(req.Kind),
+		Percent:        req.Percent,
+		Amount:         amount,
+		MinOrderValue:  minOrder,
+		ValidFrom:      req.ValidFrom,
+		ValidTo:        req.ValidTo,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "coupon",
+			"func":      "create",
+			"userLogin": login,
+			"code":      req.Code,
+		}).WithError(err).Error("Error creating coupon")
+
+		errInf := cp.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Coupon", newCoupon(cpn)))
+}
+
+// @Summary      Returns user coupons
+// @Description  returns coupons of user with number of their uses, newest first
+// @Tags         coupon
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /coupons/all [get]
+func (cp *Router) userCoupons(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	coupons, err := cp.service.UserCoupons(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "coupon",
+			"func":      "userCoupons",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving user coupons")
+
+		errInf := cp.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Coupon, len(coupons))
+	for i := range coupons {
+		res[i] = newCoupon(&coupons[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Coupons", res))
+}
+
+// @Summary      Disable coupon
+// @Description  coupon can't be applied to new orders anymore, discounts it gave stay in orders
+// @Tags         coupon
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 code path      string true  "Coupon code"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /coupons/{code} [delete]
+func (cp *Router) disable(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	code := c.Param("code")
+	if !cp.codeRegex.MatchString(code) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("code", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	err := cp.service.DisableCoupon(c.Request.Context(), code, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "coupon",
+			"func":      "disable",
+			"userLogin": login,
+			"code":      code,
+		}).WithError(err).Error("Error disabling coupon")
+
+		errInf := cp.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Coupon disabled"))
+}
@@ -0,0 +1,58 @@
+package coupon
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call coupon related service level logic
+type Service interface {
+	CreateCoupon(ctx context.Context, c coupon.Coupon, login string) (*coupon.Coupon, error)
+	UserCoupons(ctx context.Context, login string) ([]coupon.Coupon, error)
+	DisableCoupon(ctx context.Context, code string, login string) error
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+	codeRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:        mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			coupon.ErrCouponExists: mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Coupon with this code already exists"},
+			coupon.ErrUnknownKind:  mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown coupon kind, supported: percent, fixed"},
+			coupon.ErrValue:        mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Percent should be 1-10000 basis points, amounts should be positive"},
+			coupon.ErrCurrency:     mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Coupon amounts should be in one currency"},
+			coupon.ErrValidity:     mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "validTo should be after validFrom"},
+			coupon.ErrLimit:        mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Usage limits should be positive"},
+		},
+	)
+
+	codeRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{3,40}$`)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+		codeRegex: codeRegex,
+	}
+
+	return router
+}
+
+func (cp *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/", cp.create)
+	r.GET("/all", cp.userCoupons)
+	r.DELETE("/:code", cp.disable)
+	return r
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -37,3 +38,15 @@ func ErrorMsg(field string, extra ...string) response.JSONResult {
 	}
 	return response.CreateJSONResult("Error", msg)
 }
+
+// MoneyError returns response.JSONResult for error of parsing amount with its currency
+func MoneyError(amountField, currencyField string, err error) response.JSONResult {
+	switch err {
+	case money.ErrInvalidCurrency:
+		return ErrorMsg(currencyField, "should be ISO 4217 currency code")
+	case money.ErrPrecision:
+		return ErrorMsg(amountField, "has more fraction digits than currency allows")
+	default:
+		return ErrorMsg(amountField, "should be decimal number like 9.99")
+	}
+}
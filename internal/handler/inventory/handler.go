@@ -0,0 +1,484 @@
+package inventory
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/inventory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Threshold used to parse request body with reorder threshold of product
+type Threshold struct {
+	Threshold *int64 `json:"threshold" binding:"required,min=0,max=1000000000" default:"10"`
+}
+
+// Rule model used to parse reorder threshold of product into JSON response, alerted is set after low stock alert
+// was raised and until available stock is back at threshold
+type Rule struct {
+	Barcode   string    `json:"barcode"`
+	Name      string    `json:"name"`
+	Threshold int64     `json:"threshold"`
+	Stock     int64     `json:"stock"`
+	Reserved  int64     `json:"reserved"`
+	Available int64     `json:"available"`
+	Alerted   bool      `json:"alerted"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Alert model used to parse low stock alert into JSON response
+type Alert struct {
+	ID        int64     `json:"id"`
+	Barcode   string    `json:"barcode"`
+	Name      string    `json:"name"`
+	Available int64     `json:"available"`
+	Threshold int64     `json:"threshold"`
+	Read      bool      `json:"read"`
+	Created   time.Time `json:"created"`
+}
+
+// Settings model used to parse request body and into JSON response, empty webhook disables webhook alerts
+type Settings struct {
+	Email   bool   `json:"email" default:"false"`
+	Webhook string `json:"webhook" binding:"max=500" default:"https://example.com/hooks/stock"`
+}
+
+// UpdatedSettings model used to parse saved settings into JSON response, webhook secret is set when webhook is set
+type UpdatedSettings struct {
+	Settings
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+}
+
+// Report model used to parse reorder suggestions into JSON response
+type Report struct {
+	Days  int           `json:"days"`
+	Cover int           `json:"cover"`
+	Since time.Time     `json:"since"`
+	Lines []ReorderLine `json:"lines"`
+}
+
+// ReorderLine model used to parse reorder suggestion for product into JSON response, velocity is average
+// quantity sold per day and suggested is quantity to order
+type ReorderLine struct {
+	Barcode   string  `json:"barcode"`
+	Name      string  `json:"name"`
+	Threshold int64   `json:"threshold"`
+	Stock     int64   `json:"stock"`
+	Reserved  int64   `json:"reserved"`
+	Available int64   `json:"available"`
+	Sold      int64   `json:"sold"`
+	Velocity  float64 `json:"velocity"`
+	Suggested int64   `json:"suggested"`
+}
+
+// @Summary      Set reorder threshold
+// @Description  sets reorder threshold of user product, low stock alert is raised once when available stock drops below threshold and again only after it was back at threshold; changing threshold re-evaluates product
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path      string true  "Product barcode"
+// @Param        threshold body      inventory.Threshold true "threshold"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/rules/{barcode} [put]
+func (in *Router) setRule(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !in.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	var req Threshold
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	err := in.service.SetRule(c.Request.Context(), barcode, *req.Threshold, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "setRule",
+			"userLogin": login,
+			"barcode":   barcode,
+		}).WithError(err).Error("Error setting reorder threshold")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Reorder threshold set"))
+}
+
+// @Summary      Delete reorder threshold
+// @Description  removes reorder threshold of user product, product isn't evaluated by low stock alerts anymore and its alerts stay in notification list
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path      string true  "Product barcode"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/rules/{barcode} [delete]
+func (in *Router) deleteRule(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !in.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	err := in.service.DeleteRule(c.Request.Context(), barcode, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "deleteRule",
+			"userLogin": login,
+			"barcode":   barcode,
+		}).WithError(err).Error("Error deleting reorder threshold")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Reorder threshold deleted"))
+}
+
+// @Summary      Returns reorder thresholds
+// @Description  returns reorder thresholds of user products with their current stock in order products were created
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/rules [get]
+func (in *Router) rules(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	rules, err := in.service.Rules(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "rules",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving reorder thresholds")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Rule, len(rules))
+	for i, r := range rules {
+		res[i] = Rule{
+			Barcode:   r.Barcode,
+			Name:      r.Name,
+			Threshold: r.Threshold,
+			Stock:     r.Stock,
+			Reserved:  r.Reserved,
+			Available: r.Available,
+			Alerted:   r.Alerted,
+			Updated:   r.Updated,
+		}
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Rules", res))
+}
+
+// @Summary      Returns low stock alerts with pagination
+// @Description  returns in-app notification list of user with low stock alerts, newest first
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of alerts per page" minimum(1)    maximum(100)
+// @Param   	 unread query   bool   false "Return only unread alerts"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/alerts [get]
+func (in *Router) alerts(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	alertsPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (alertsPerPage < 1 || alertsPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	unread := false
+	if v := c.Query("unread"); v != "" {
+		unread, err = strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, validator.ErrorMsg("unread", "should be true or false"))
+			return
+		}
+	}
+
+	alerts, err := in.service.Alerts(c.Request.Context(), unread, page, alertsPerPage, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "inventory",
+			"func":       "alerts",
+			"userLogin":  login,
+			"page":       page,
+			"numPerPage": alertsPerPage,
+		}).WithError(err).Error("Error retrieving low stock alerts")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Alert, len(alerts))
+	for i, a := range alerts {
+		res[i] = Alert{
+			ID:        a.ID,
+			Barcode:   a.Barcode,
+			Name:      a.Name,
+			Available: a.Available,
+			Threshold: a.Threshold,
+			Read:      a.Read,
+			Created:   a.Created,
+		}
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Alerts", res))
+}
+
+// @Summary      Mark low stock alert read
+// @Description  marks alert in notification list of user as read, marking read alert again does nothing
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id path      int true  "Alert id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/alerts/{id}/read [post]
+func (in *Router) readAlert(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	err = in.service.ReadAlert(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "readAlert",
+			"userLogin": login,
+			"id":        id,
+		}).WithError(err).Error("Error marking low stock alert read")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Alert marked read"))
+}
+
+// @Summary      Returns alert settings
+// @Description  returns channels low stock alerts of user are sent to besides in-app notification list
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/settings [get]
+func (in *Router) settings(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	st, err := in.service.AlertSettings(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "settings",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving alert settings")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Settings", Settings{Email: st.Email, Webhook: st.Webhook}))
+}
+
+// @Summary      Update alert settings
+// @Description  user opts in to low stock alerts by email to their address and by webhook of public host, webhook receives JSON signed with HMAC-SHA256 of webhook secret in X-Signature header; new webhook gets new secret, current secret is returned in webhookSecret of every update; channels disabled by server are skipped
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        settings  body  inventory.Settings true "email,webhook"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/settings [put]
+func (in *Router) updateSettings(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Settings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	st, err := in.service.UpdateAlertSettings(c.Request.Context(), inventory.Settings{
+		Email:   req.Email,
+		Webhook: req.Webhook,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "updateSettings",
+			"userLogin": login,
+		}).WithError(err).Error("Error updating alert settings")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Settings updated", UpdatedSettings{
+		Settings:      Settings{Email: st.Email, Webhook: st.Webhook},
+		WebhookSecret: st.WebhookSecret,
+	}))
+}
+
+// @Summary      Returns reorder suggestions
+// @Description  suggests quantities to order for user products that have reorder threshold or were sold within last days: sales velocity over the window times cover days plus threshold minus available stock
+// @Tags         inventory
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param   	 days query     int    false "Sales window in days, configured default when absent" minimum(1)    maximum(365)
+// @Param   	 cover query    int    false "Days suggested quantities should cover, configured default when absent" minimum(1)    maximum(365)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /inventory/reorder [get]
+func (in *Router) reorder(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	days := 0
+	if v := c.Query("days"); v != "" {
+		var err error
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 || days > inventory.MaxReportDays {
+			c.JSON(http.StatusBadRequest, validator.ErrorMsg("days", "should be between 1 and 365"))
+			return
+		}
+	}
+
+	cover := 0
+	if v := c.Query("cover"); v != "" {
+		var err error
+		cover, err = strconv.Atoi(v)
+		if err != nil || cover < 1 || cover > inventory.MaxReportDays {
+			c.JSON(http.StatusBadRequest, validator.ErrorMsg("cover", "should be between 1 and 365"))
+			return
+		}
+	}
+
+	rep, err := in.service.Reorder(c.Request.Context(), days, cover, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "inventory",
+			"func":      "reorder",
+			"userLogin": login,
+			"days":      days,
+			"cover":     cover,
+		}).WithError(err).Error("Error building reorder report")
+
+		errInf := in.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := Report{
+		Days:  rep.Days,
+		Cover: rep.Cover,
+		Since: rep.Since,
+		Lines: make([]ReorderLine, len(rep.Lines)),
+	}
+	for i, l := range rep.Lines {
+		res.Lines[i] = ReorderLine{
+			Barcode:   l.Barcode,
+			Name:      l.Name,
+			Threshold: l.Threshold,
+			Stock:     l.Stock,
+			Reserved:  l.Reserved,
+			Available: l.Available,
+			Sold:      l.Sold,
+			Velocity:  l.Velocity,
+			Suggested: l.Suggested,
+		}
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Reorder", res))
+}
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/inventory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call low stock alerts and reorder related service level logic
+type Service interface {
+	SetRule(ctx context.Context, barcode string, threshold int64, login string) error
+	DeleteRule(ctx context.Context, barcode string, login string) error
+	Rules(ctx context.Context, login string) ([]inventory.Rule, error)
+	Alerts(ctx context.Context, unread bool, page, alertsPerPage int, login string) ([]inventory.Alert, error)
+	ReadAlert(ctx context.Context, id int64, login string) error
+	AlertSettings(ctx context.Context, login string) (*inventory.Settings, error)
+	UpdateAlertSettings(ctx context.Context, st inventory.Settings, login string) (*inventory.Settings, error)
+	Reorder(ctx context.Context, days, cover int, login string) (*inventory.Report, error)
+}
+
+type Router struct {
+	service      Service
+	errMapper    mapper.ErrorMapper
+	barcodeRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:         mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			inventory.ErrThreshold:  mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Threshold can't be negative"},
+			inventory.ErrWebhookURL: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Webhook should be absolute http or https URL of public host"},
+			inventory.ErrReportDays: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Number of days should be between 1 and 365"},
+		},
+	)
+
+	barcodeRegex := regexp.MustCompile(`^[0-9]{10}$`)
+
+	router := &Router{
+		service:      service,
+		errMapper:    mapping,
+		barcodeRegex: barcodeRegex,
+	}
+
+	return router
+}
+
+func (in *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/rules", in.rules)
+	r.PUT("/rules/:barcode", in.setRule)
+	r.DELETE("/rules/:barcode", in.deleteRule)
+	r.GET("/alerts", in.alerts)
+	r.POST("/alerts/:id/read", in.readAlert)
+	r.GET("/settings", in.settings)
+	r.PUT("/settings", in.updateSettings)
+	r.GET("/reorder", in.reorder)
+	return r
+}
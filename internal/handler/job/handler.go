@@ -0,0 +1,83 @@
+package job
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Job model used to parse into JSON response
+type Job struct {
+	ID       string    `json:"id"`
+	Barcode  string    `json:"barcode"`
+	Template string    `json:"template,omitempty"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+	Download string    `json:"download,omitempty"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// @Summary      Returns check generation job status
+// @Description  returns status of job(queued, running, done, failed) and link to generated check when it's done, only creator of job can view it
+// @Tags         job
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      string true  "Job id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /jobs/{id} [get]
+func (j *Router) userJob(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id := c.Param("id")
+	if !j.idRegex.MatchString(id) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be UUID"))
+		return
+	}
+
+	jb, err := j.service.UserJob(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "job",
+			"func":      "userJob",
+			"userLogin": login,
+			"job":       id,
+		}).WithError(err).Error("Error retrieving job")
+
+		errInf := j.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := Job{
+		ID:       jb.ID,
+		Barcode:  jb.Barcode,
+		Template: jb.Template,
+		Status:   jb.Status,
+		Attempts: jb.Attempts,
+		Error:    jb.Error,
+		Created:  jb.Created,
+		Updated:  jb.Updated,
+	}
+	if jb.Status == mysql.JobDone {
+		res.Download = "/product/check/" + jb.FileName
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Job", res))
+}
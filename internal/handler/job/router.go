@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/job"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call job service level logic
+type Service interface {
+	UserJob(ctx context.Context, id string, login string) (*job.Job, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+	idRegex   *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows: mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+		},
+	)
+
+	idRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+		idRegex:   idRegex,
+	}
+
+	return router
+}
+
+func (j *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/:id", j.userJob)
+	return r
+}
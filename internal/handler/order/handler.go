@@ -0,0 +1,389 @@
+package order
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/service/order"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Created used to parse request body with new order
+type Created struct {
+	Items   []CreatedItem `json:"items" binding:"required,min=1,max=100,dive"`
+	Coupons []string      `json:"coupons" binding:"max=3,dive,min=3,max=40"`
+	// Customer is email of buyer, uses of coupons limited per customer are counted by it
+	Customer string `json:"customer" binding:"omitempty,email,max=254" example:"buyer@test.com"`
+}
+
+// CreatedItem used to parse order line of new order
+type CreatedItem struct {
+	Barcode  string `json:"barcode" binding:"required,len=10,numeric" default:"1234567890"`
+	Quantity int    `json:"quantity" binding:"required,min=1,max=10000" default:"1"`
+}
+
+// Order model used to parse order into JSON response
+type Order struct {
+	ID         int64             `json:"id"`
+	Customer   string            `json:"customer,omitempty"`
+	Status     string            `json:"status"`
+	Next       []string          `json:"next"`
+	Subtotal   money.Money       `json:"subtotal"`
+	Discount   money.Money       `json:"discount"`
+	Discounts  []Discount        `json:"discounts,omitempty"`
+	Total      money.Money       `json:"total"`
+	Conversion *money.Conversion `json:"conversion,omitempty"`
+	ItemsCount int               `json:"itemsCount"`
+	Items      []Item            `json:"items,omitempty"`
+	Events     []Event           `json:"events,omitempty"`
+	Created    time.Time         `json:"created"`
+	Updated    time.Time         `json:"updated"`
+}
+
+// Event model used to parse order status change into JSON response
+type Event struct {
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to"`
+	Actor   string    `json:"actor"`
+	Created time.Time `json:"created"`
+}
+
+// Transition used to parse request body of order status change
+type Transition struct {
+	Status string `json:"status" binding:"required" default:"placed"`
+}
+
+// Discount model used to parse coupon applied to order into JSON response
+type Discount struct {
+	Code   string      `json:"code"`
+	Amount money.Money `json:"amount"`
+}
+
+// Item model used to parse order line into JSON response
+type Item struct {
+	Barcode     string      `json:"barcode"`
+	ProductName string      `json:"productName"`
+	Price       money.Money `json:"price"`
+	Quantity    int         `json:"quantity"`
+	Total       money.Money `json:"total"`
+}
+
+func newOrder(o *order.Order) Order {
+	res := Order{
+		ID:         o.ID,
+		Customer:   o.Customer,
+		Status:     string(o.Status),
+		Next:       []string{},
+		Subtotal:   o.Subtotal,
+		Discount:   o.Discount,
+		Total:      o.Total,
+		Conversion: o.Conversion,
+		ItemsCount: o.ItemsCount,
+		Created:    o.Created,
+		Updated:    o.Updated,
+	}
+	for _, next := range o.Status.Next() {
+		res.Next = append(res.Next, string(next))
+	}
+	for _, e := range o.Events {
+		res.Events = append(res.Events, Event{
+			From:    string(e.From),
+			To:      string(e.To),
+			Actor:   e.Actor,
+			Created: e.Created,
+		})
+	}
+	for _, d := range o.Discounts {
+		res.Discounts = append(res.Discounts, Discount{
+			Code:   d.Code,
+			Amount: d.Amount,
+		})
+	}
+	for _, it := range o.Items {
+		res.Items = append(res.Items, Item{
+			Barcode:     it.Barcode,
+			ProductName: it.ProductName,
+			Price:       it.Price,
+			Quantity:    it.Quantity,
+			Total:       it.Total(),
+		})
+	}
+	return res
+}
+
+// @Summary      Create new order
+// @Description  user provides barcodes of own products with quantities and optional coupon codes, current product names and costs are saved in draft order, coupons are applied in given order each to price left after previous ones and recorded with order
+// @Tags         order
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        order  body      order.Created true "order items"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /orders/ [post]
+func (o *Router) create(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Created
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	items := make([]order.Item, len(req.Items))
+	for i, it := range req.Items {
+		items[i] = order.Item{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		}
+	}
+
+	ord, err := o.service.CreateOrder(c.Request.Context(), items, req.Coupons, req.Customer, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "order",
+			"func":      "create",
+			"userLogin": login,
+		}).WithError(err).Error("Error creating order")
+
+		errInf := o.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Order", newOrder(ord)))
+}
+
+// @Summary      Returns user orders with pagination
+// @Description  returns orders with totals and number of items, newest first, only owner of order can view it
+// @Tags         order
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of orders per page" minimum(1)    maximum(100)
+// @Param   	 currency query  string false "ISO 4217 currency amounts are converted to with rates effective at order creation"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /orders/all [get]
+func (o *Router) userOrders(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	ordersPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (ordersPerPage < 1 || ordersPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	currency, err := response.Currency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("currency", "should be ISO 4217 code"))
+		return
+	}
+
+	orders, err := o.service.UserOrders(c.Request.Context(), page, ordersPerPage, currency, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "order",
+			"func":       "userOrders",
+			"userLogin":  login,
+			"page":       page,
+			"numPerPage": ordersPerPage,
+		}).WithError(err).Error("Error retrieving user orders")
+
+		errInf := o.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Order, len(orders))
+	for i := range orders {
+		res[i] = newOrder(&orders[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Orders", res))
+}
+
+// @Summary      Returns user order
+// @Description  returns order with items, status history and statuses it can be moved to, only owner of order can view it; order converted to currency has original total and rate
+// @Tags         order
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      int true  "Order id"
+// @Param 		 currency  query  string false "ISO 4217 currency amounts are converted to with rate effective at order creation"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /orders/{id} [get]
+func (o *Router) userOrder(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	currency, err := response.Currency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("currency", "should be ISO 4217 code"))
+		return
+	}
+
+	ord, err := o.service.UserOrder(c.Request.Context(), id, currency, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "order",
+			"func":      "userOrder",
+			"userLogin": login,
+			"order":     id,
+		}).WithError(err).Error("Error retrieving order")
+
+		errInf := o.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Order", newOrder(ord)))
+}
+
+// @Summary      Change order status
+// @Description  moves order to requested status, allowed: draft -> placed|cancelled, placed -> paid|cancelled, paid -> shipped|refunded, shipped -> completed|refunded, completed -> refunded; change is saved in order history, only owner of order can do it; placing order reserves ordered quantities in warehouse chosen by configured strategy for configured time(409 if available stock is short and negative stock is rejected or user has no warehouse), paying it turns reservations into sales(quantities of expired reservations are taken from available stock again), cancelling it releases reservations and puts goods taken from stock back to warehouses they were taken from
+// @Tags         order
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id          path      int true  "Order id"
+// @Param        transition  body      order.Transition true "new status"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /orders/{id}/transitions [post]
+func (o *Router) transition(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	var req Transition
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	to, err := order.ParseStatus(req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("status", "should be one of draft, placed, paid, shipped, completed, cancelled, refunded"))
+		return
+	}
+
+	ord, err := o.service.Transition(c.Request.Context(), id, to, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "order",
+			"func":      "transition",
+			"userLogin": login,
+			"order":     id,
+			"status":    to,
+		}).WithError(err).Error("Error changing order status")
+
+		errInf := o.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Order", newOrder(ord)))
+}
+
+// @Summary      Returns order receipt
+// @Description  renders PDF receipt with all order lines, subtotal, discount, VAT and grand total over check template, lines that don't fit template page are continued on next pages, only owner of order can get it
+// @Tags         order
+// @Accept       x-www-form-urlencoded
+// @Security     ApiKeyAuth
+// @Param 		 id        path      int    true  "Order id"
+// @Param 		 template  query     string false "Template name, user default template is used if not set"
+// @Produce  	 application/pdf
+// @Success 	 200 {file} PdfFile
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /orders/{id}/receipt [get]
+func (o *Router) receipt(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	tplName := c.Query("template")
+	if tplName != "" && !o.tplNameRegex.MatchString(tplName) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	f, err := o.service.Receipt(c.Request.Context(), id, tplName, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "order",
+			"func":      "receipt",
+			"userLogin": login,
+			"order":     id,
+			"template":  tplName,
+		}).WithError(err).Error("Error rendering order receipt")
+
+		errInf := o.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	response.SendCheck(c, f)
+}
@@ -0,0 +1,80 @@
+package order
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+	"github.com/AnisaForWork/user_orders/internal/service/exchange"
+	"github.com/AnisaForWork/user_orders/internal/service/order"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call order related service level logic
+type Service interface {
+	CreateOrder(ctx context.Context, items []order.Item, codes []string, customer string, login string) (*order.Order, error)
+	UserOrders(ctx context.Context, page, ordersPerPage int, currency string, login string) ([]order.Order, error)
+	UserOrder(ctx context.Context, id int64, currency string, login string) (*order.Order, error)
+	Transition(ctx context.Context, id int64, to order.Status, login string) (*order.Order, error)
+	Receipt(ctx context.Context, id int64, tplName string, login string) (*product.CheckFile, error)
+}
+
+type Router struct {
+	service      Service
+	errMapper    mapper.ErrorMapper
+	tplNameRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			render.ErrInvalidLayout:    mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Template has no room for receipt"},
+			mysql.ErrNoRows:            mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			order.ErrNoItems:           mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Order should have at least one item"},
+			order.ErrDuplicateItem:     mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Product is listed more than once"},
+			order.ErrUnknownStatus:     mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown order status"},
+			order.ErrIllegalTransition: mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Order can't be moved to requested status from current one"},
+			order.ErrQuantity:          mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Quantity should be positive"},
+			coupon.ErrTooManyCoupons:   mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "At most 3 coupons can be applied to order"},
+			coupon.ErrDuplicateCoupon:  mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Coupon is applied more than once"},
+			coupon.ErrCouponNotFound:   mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Coupon doesn't exist"},
+			coupon.ErrCouponInactive:   mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Coupon is disabled, expired or not valid yet"},
+			coupon.ErrCouponUsedUp:     mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Coupon usage limit is reached"},
+			coupon.ErrCouponPerUser:    mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Customer has already used this coupon maximum times"},
+			coupon.ErrCouponCustomer:   mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Coupon can be used only in order with customer"},
+			coupon.ErrCouponMinOrder:   mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Order value is below coupon minimum"},
+			coupon.ErrCouponCurrency:   mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "Coupon is for orders in other currency"},
+			order.ErrCurrencies:        mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "All order products should have the same currency"},
+			stock.ErrOutOfStock:        mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Not enough stock of ordered products"},
+			stock.ErrNoWarehouse:       mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "No active warehouse to take ordered products from"},
+			exchange.ErrNoRate:         mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "No exchange rate between currencies"},
+		},
+	)
+
+	tplNameRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{3,40}$`)
+
+	router := &Router{
+		service:      service,
+		errMapper:    mapping,
+		tplNameRegex: tplNameRegex,
+	}
+
+	return router
+}
+
+func (o *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/", o.create)
+	r.GET("/all", o.userOrders)
+	r.GET("/:id", o.userOrder)
+	r.POST("/:id/transitions", o.transition)
+	r.GET("/:id/receipt", o.receipt)
+	return r
+}
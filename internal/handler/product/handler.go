@@ -1,7 +1,6 @@
 package product
 
 import (
-	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,6 +8,7 @@ import (
 	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
 	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
 	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
 	"github.com/AnisaForWork/user_orders/internal/service/product"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -16,29 +16,30 @@ import (
 
 // Created used to parse requests body with info about new Order
 type Created struct {
-	Barcode string `json:"barcode"   binding:"required,len=10,numeric" minimum:"10" maximum:"10" default:"1234567890"`
-	Name    string `json:"name"  binding:"required,min=10,max=60,startsnotwith= ,endsnotwith= " minimum:"10" maximum:"60" default:"product name"`
-	Descr   string `json:"desc" binding:"required,min=10,max=1000,startsnotwith= ,endsnotwith= " default:"Description"`
-	Cost    int    `json:"cost" binding:"required,min=1" minimum:"10" default:"100"`
+	Barcode  string `json:"barcode"   binding:"required,len=10,numeric" minimum:"10" maximum:"10" default:"1234567890"`
+	Name     string `json:"name"  binding:"required,min=10,max=60,startsnotwith= ,endsnotwith= " minimum:"10" maximum:"60" default:"product name"`
+	Descr    string `json:"desc" binding:"required,min=10,max=1000,startsnotwith= ,endsnotwith= " default:"Description"`
+	Cost     string `json:"cost" binding:"required,max=20" default:"9.99"`
+	Currency string `json:"currency" binding:"omitempty,len=3,alpha" default:"EUR"`
 }
 
 // Product model used to parse into JSON response
 type Product struct {
-	Barcode  string     `json:"barcode,omitempty"`
-	Name     string     `json:"name,omitempty"`
-	Descr    string     `json:"desc,omitempty"`
-	Cost     int        `json:"cost,omitempty"`
-	Created  *time.Time `json:"created,omitempty"`
-	FileName []string   `json:"filaname,omitempty"`
+	Barcode  string       `json:"barcode,omitempty"`
+	Name     string       `json:"name,omitempty"`
+	Descr    string       `json:"desc,omitempty"`
+	Cost     *money.Money `json:"cost,omitempty"`
+	Created  *time.Time   `json:"created,omitempty"`
+	FileName []string     `json:"filaname,omitempty"`
 }
 
 // @Summary      Create new product
-// @Description  user provides products barcode, name, description and cost
+// @Description  user provides products barcode, name, description and cost as decimal string in ISO 4217 currency(user currency if not set), cost can't have more fraction digits than currency
 // @Tags         product
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        product      body  product.Created true "barcode,name,desc,cost"
+// @Param        product      body  product.Created true "barcode,name,desc,cost,currency"
 // @Success      200  {object}  response.JSONResult
 // @Failure      400  {object}  response.JSONResult
 // @Failure      409  {object}  response.JSONResult
@@ -55,14 +56,39 @@ func (p *Router) create(c *gin.Context) {
 		return
 	}
 
+	cur := prodt.Currency
+	if cur == "" {
+		st, err := p.service.Settings(c.Request.Context(), login)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"handler":   "product",
+				"func":      "create",
+				"userLogin": login,
+			}).WithError(err).Error("Error retrieving user currency")
+
+			errInf := p.errMapper.MapError(err)
+			c.JSON(errInf.StatusCode,
+				response.CreateJSONResult("Error", errInf.Msg))
+
+			return
+		}
+		cur = st.Currency
+	}
+
+	cost, err := money.Parse(prodt.Cost, cur)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.MoneyError("cost", "currency", err))
+		return
+	}
+
 	srvProdt := product.Product{
 		Barcode: prodt.Barcode,
 		Name:    prodt.Name,
 		Descr:   prodt.Descr,
-		Cost:    prodt.Cost,
+		Cost:    cost,
 	}
 
-	err := p.service.Create(c.Request.Context(), srvProdt, login)
+	err = p.service.Create(c.Request.Context(), srvProdt, login)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"handler":   "product",
@@ -88,9 +114,11 @@ func (p *Router) create(c *gin.Context) {
 // @Security     ApiKeyAuth
 // @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
 // @Param   	 n query     int    true "Number of products info per page" minimum(1)    maximum(100)
+// @Param   	 currency query  string false "ISO 4217 currency costs are converted to with current rates"
 // @Success      200  {object}  response.JSONResult
 // @Failure      400  {object}  response.JSONResult
 // @Failure      403  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
 // @Failure      500  {object}  response.JSONResult
 // @Router       /product/all [get]
 func (p *Router) allUserProducts(c *gin.Context) {
@@ -111,7 +139,13 @@ func (p *Router) allUserProducts(c *gin.Context) {
 		return
 	}
 
-	prods, err := p.service.UserProducts(c.Request.Context(), page, prodsPerPage, login)
+	currency, err := response.Currency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("currency", "should be ISO 4217 code"))
+		return
+	}
+
+	prods, err := p.service.UserProducts(c.Request.Context(), page, prodsPerPage, currency, login)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"handler":    "prodyct",
@@ -132,15 +166,17 @@ func (p *Router) allUserProducts(c *gin.Context) {
 }
 
 // @Summary      Returns user product full info
-// @Description  returns full info about product user chose to view, only owner of product can view it
+// @Description  returns full info about product user chose to view, only owner of product can view it; cost converted to currency has original cost and rate
 // @Tags         product
 // @Accept       x-www-form-urlencoded
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param 		 barcode   path      string true  "Product barcode"
+// @Param 		 currency  query     string false "ISO 4217 currency cost is converted to with current rate"
 // @Success      200  {object}  response.JSONResult
 // @Failure      400  {object}  response.JSONResult
 // @Failure      404  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
 // @Failure      500  {object}  response.JSONResult
 // @Router       /product/{barcode} [get]
 func (p *Router) userProduct(c *gin.Context) {
@@ -154,7 +190,13 @@ func (p *Router) userProduct(c *gin.Context) {
 		return
 	}
 
-	prod, err := p.service.UserProduct(c.Request.Context(), barcode, login)
+	currency, err := response.Currency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("currency", "should be ISO 4217 code"))
+		return
+	}
+
+	prod, err := p.service.UserProduct(c.Request.Context(), barcode, currency, login)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"handler":   "product",
@@ -216,16 +258,21 @@ func (p *Router) delete(c *gin.Context) {
 }
 
 // @Summary      Generates check
-// @Description  using info about given product generates PDF check using special PDF template
+// @Description  using info about given product generates PDF check using special PDF template, check is sent in format chosen by format parameter or Accept header(PDF by default)
 // @Tags         product
 // @Accept       x-www-form-urlencoded
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param 		 barcode   path      string true  "Product barcode"
-// @Produce  	 application/pdf
+// @Param 		 template  query     string false "Template name, user default template is used if not set"
+// @Param 		 currency  query     string false "ISO 4217 currency cost is printed in, converted with current rate that is kept with check"
+// @Param 		 format    query     string false "Check format" Enums(pdf, html, png, escpos)
+// @Produce  	 application/pdf,text/html,image/png,application/vnd.escpos
 // @Success 	 200 {file} PdfFile
 // @Failure      400  {object}  response.JSONResult
 // @Failure      403  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      422  {object}  response.JSONResult
 // @Failure      500  {object}  response.JSONResult
 // @Router       /product/{barcode}/check [get]
 func (p *Router) genCheck(c *gin.Context) {
@@ -239,13 +286,26 @@ func (p *Router) genCheck(c *gin.Context) {
 		return
 	}
 
-	f, err := p.service.GenCheck(c.Request.Context(), barcode, login)
+	tplName := c.Query("template")
+	if tplName != "" && !p.tplNameRegex.MatchString(tplName) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	currency, err := response.Currency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("currency", "should be ISO 4217 code"))
+		return
+	}
+
+	f, err := p.service.GenCheck(c.Request.Context(), barcode, tplName, currency, response.CheckFormat(c), login)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"handler":   "product",
 			"func":      "create",
 			"userLogin": login,
 			"barcode":   barcode,
+			"template":  tplName,
 		}).WithError(err).Error("Error creating check for product")
 
 		errInf := p.errMapper.MapError(err)
@@ -254,27 +314,251 @@ func (p *Router) genCheck(c *gin.Context) {
 
 		return
 	}
-	defer f.Close()
+	response.SendCheck(c, f)
+}
+
+// JobCreated model used to parse into JSON response
+type JobCreated struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// @Summary      Enqueues check generation
+// @Description  creates job generating PDF check for given product, job status can be polled using returned link
+// @Tags         product
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path      string true  "Product barcode"
+// @Param 		 template  query     string false "Template name, user default template is used if not set"
+// @Success      202  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      503  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /product/{barcode}/checks [post]
+func (p *Router) enqueueCheck(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !p.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	tplName := c.Query("template")
+	if tplName != "" && !p.tplNameRegex.MatchString(tplName) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	id, err := p.service.EnqueueCheck(c.Request.Context(), barcode, tplName, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "product",
+			"func":      "enqueueCheck",
+			"userLogin": login,
+			"barcode":   barcode,
+			"template":  tplName,
+		}).WithError(err).Error("Error enqueueing check generation")
+
+		errInf := p.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	statusURL := "/jobs/" + id
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, response.CreateJSONResult("Check generation queued", JobCreated{ID: id, Status: statusURL}))
+}
+
+// Check model used to parse check metadata into JSON response
+type Check struct {
+	ID              int64       `json:"id"`
+	FileName        string      `json:"filename"`
+	Barcode         string      `json:"barcode"`
+	Created         time.Time   `json:"created"`
+	Size            int64       `json:"size"`
+	SHA256          string      `json:"sha256"`
+	TemplateVersion int         `json:"templateVersion"`
+	ProductName     string      `json:"productName"`
+	ProductCost     money.Money `json:"productCost"`
+	Converted       *Converted  `json:"converted,omitempty"`
+	Download        string      `json:"download"`
+	Receipt         *Receipt    `json:"receipt,omitempty"`
+	Deliveries      []Delivery  `json:"deliveries,omitempty"`
+}
+
+// Converted model used to parse cost check was printed with in other currency into JSON response
+type Converted struct {
+	Cost money.Money `json:"cost"`
+	Rate string      `json:"rate"`
+}
+
+// Receipt model used to parse fiscal data of check into JSON response, VAT rate is in percent
+type Receipt struct {
+	Number        int64       `json:"number"`
+	SellerName    string      `json:"sellerName"`
+	SellerTaxID   string      `json:"sellerTaxId"`
+	SellerAddress string      `json:"sellerAddress"`
+	VATRate       float64     `json:"vatRate"`
+	Net           money.Money `json:"net"`
+	Tax           money.Money `json:"tax"`
+	Gross         money.Money `json:"gross"`
+}
+
+// Delivery model used to parse state of sending check by email into JSON response
+type Delivery struct {
+	ID        int64     `json:"id"`
+	Recipient string    `json:"recipient"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+// NewDelivery converts service level check delivery into response model
+func NewDelivery(d *product.Delivery) Delivery {
+	return Delivery{
+		ID:        d.ID,
+		Recipient: d.Recipient,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		Error:     d.Error,
+		Created:   d.Created,
+		Updated:   d.Updated,
+	}
+}
+
+// NewCheck converts service level check metadata into response model
+func NewCheck(ch *product.Check) Check {
+	res := Check{
+		ID:              ch.ID,
+		FileName:        ch.FileName,
+		Barcode:         ch.Barcode,
+		Created:         ch.Created,
+		Size:            ch.Size,
+		SHA256:          ch.SHA256,
+		TemplateVersion: ch.TemplateVersion,
+		ProductName:     ch.ProductName,
+		ProductCost:     ch.ProductCost,
+		Download:        "/product/check/" + ch.FileName,
+	}
+
+	if cv := ch.Converted; cv != nil {
+		res.Converted = &Converted{
+			Cost: cv.Cost,
+			Rate: money.FormatRate(cv.Rate),
+		}
+	}
 
-	c.Header("Content-type", "application/pdf")
-	c.Status(http.StatusOK)
-	if _, err := io.Copy(c.Writer, f); err != nil {
+	if r := ch.Receipt; r != nil {
+		res.Receipt = &Receipt{
+			Number:        r.Number,
+			SellerName:    r.SellerName,
+			SellerTaxID:   r.SellerTaxID,
+			SellerAddress: r.SellerAddress,
+			VATRate:       float64(r.VATRate) / 100,
+			Net:           r.Net,
+			Tax:           r.Tax,
+			Gross:         r.Gross,
+		}
+	}
+
+	for i := range ch.Deliveries {
+		res.Deliveries = append(res.Deliveries, NewDelivery(&ch.Deliveries[i]))
+	}
+
+	return res
+}
+
+// @Summary      Returns checks generated for product with pagination
+// @Description  returns metadata of product checks, newest first, only owner of product can view it
+// @Tags         product
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path  string true "Product barcode"
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of checks per page" minimum(1)    maximum(100)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /product/{barcode}/checks [get]
+func (p *Router) productChecks(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !p.barcodeRegex.MatchString(barcode) {
 		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "shouldd be between 1 and 500000"))
+		return
+	}
+
+	checksPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (checksPerPage < 1 || checksPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "shouldd be between 1 and 100"))
+		return
 	}
+
+	checks, err := p.service.ProductChecks(c.Request.Context(), barcode, page, checksPerPage, login)
 	if err != nil {
-		log.WithError(err).Warn("Could not send check")
+		log.WithFields(logrus.Fields{
+			"handler":    "product",
+			"func":       "productChecks",
+			"userLogin":  login,
+			"barcode":    barcode,
+			"page":       page,
+			"numPerPage": checksPerPage,
+		}).WithError(err).Error("Error retrieving product checks")
+
+		errInf := p.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Check, len(checks))
+	for i := range checks {
+		res[i] = NewCheck(&checks[i])
 	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Checks", res))
 }
 
 // @Summary      Returns check of user product
-// @Description  sends user PDF check generated previously using product info, only owner of product can do it
+// @Description  sends user check generated previously using product info, only owner of product can do it, check is sent in format chosen by format parameter or Accept header(PDF by default)
 // @Tags         product
 // @Accept       x-www-form-urlencoded
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param 		 checkName   path   string true  "Check file name"
-// @Produce  	 application/pdf
+// @Param 		 format      query  string false "Check format" Enums(pdf, html, png, escpos)
+// @Param 		 Range       header string false "Byte range, e.g. bytes=0-1023"
+// @Param 		 If-None-Match     header string false "ETag of cached check"
+// @Param 		 If-Modified-Since header string false "Time of cached check"
+// @Produce  	 application/pdf,text/html,image/png,application/vnd.escpos
 // @Success 	 200 {file} PdfFile
+// @Header       200 {string} ETag "SHA-256 of check content"
+// @Header       200 {string} Last-Modified "Check creation time"
+// @Header       200 {string} Content-Disposition "attachment with check file name"
+// @Success 	 206 {file} PdfFile
+// @Success 	 304 "Check not modified"
+// @Failure      416 "Requested range not satisfiable"
 // @Failure      400  {object}  response.JSONResult
 // @Failure      403  {object}  response.JSONResult
 // @Failure      500  {object}  response.JSONResult
@@ -286,7 +570,7 @@ func (p *Router) productCheck(c *gin.Context) {
 
 	ch := c.Param("checkName")
 
-	f, err := p.service.UserProductCheck(c.Request.Context(), ch, login)
+	f, err := p.service.UserProductCheck(c.Request.Context(), ch, response.CheckFormat(c), login)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"handler":   "product",
@@ -301,12 +585,5 @@ func (p *Router) productCheck(c *gin.Context) {
 
 		return
 	}
-	defer f.Close()
-
-	c.Header("Content-type", "application/pdf")
-	c.Status(http.StatusOK)
-	//Stream to response
-	if _, err := io.Copy(c.Writer, f); err != nil {
-		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
-	}
+	response.SendCheck(c, f)
 }
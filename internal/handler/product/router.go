@@ -3,12 +3,16 @@ package product
 import (
 	"context"
 	"net/http"
-	"os"
 	"regexp"
 
 	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
 	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/exchange"
+	"github.com/AnisaForWork/user_orders/internal/service/job"
 	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/user"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,17 +20,21 @@ import (
 // Service used to call auth service level auth
 type Service interface {
 	Create(ctx context.Context, pr product.Product, login string) error
-	UserProducts(ctx context.Context, page, prodsPerPage int, login string) ([]product.Product, error)
+	UserProducts(ctx context.Context, page, prodsPerPage int, currency string, login string) ([]product.Product, error)
 	Delete(ctx context.Context, login string, barcode string) error
-	UserProduct(ctx context.Context, barcode string, login string) (*product.Product, error)
-	GenCheck(ctx context.Context, barcode string, login string) (*os.File, error)
-	UserProductCheck(ctx context.Context, filename string, login string) (*os.File, error)
+	UserProduct(ctx context.Context, barcode string, currency string, login string) (*product.Product, error)
+	GenCheck(ctx context.Context, barcode string, tplName string, currency string, format render.Format, login string) (*product.CheckFile, error)
+	UserProductCheck(ctx context.Context, filename string, format render.Format, login string) (*product.CheckFile, error)
+	EnqueueCheck(ctx context.Context, barcode string, tplName string, login string) (string, error)
+	ProductChecks(ctx context.Context, barcode string, page, checksPerPage int, login string) ([]product.Check, error)
+	Settings(ctx context.Context, login string) (*user.Settings, error)
 }
 
 type Router struct {
 	service      Service
 	errMapper    mapper.ErrorMapper
 	barcodeRegex *regexp.Regexp
+	tplNameRegex *regexp.Regexp
 }
 
 func NewRouter(service Service) *Router {
@@ -36,15 +44,23 @@ func NewRouter(service Service) *Router {
 			mysql.ErrUniqConstrViolation: mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Already exists"},
 			product.ErrNotExists:         mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
 			product.ErrNotOwner:          mapper.ErrorInfo{StatusCode: http.StatusForbidden, Msg: "Can't do it"},
+			store.ErrNotFound:            mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Check file not found"},
+			store.ErrExists:              mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Check file already exists, try again"},
+			render.ErrUnknownFormat:      mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown format, supported: pdf, html, png, escpos"},
+			product.ErrCost:              mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Cost should be positive"},
+			exchange.ErrNoRate:           mapper.ErrorInfo{StatusCode: http.StatusUnprocessableEntity, Msg: "No exchange rate between currencies"},
+			job.ErrQueueFull:             mapper.ErrorInfo{StatusCode: http.StatusServiceUnavailable, Msg: "Too many checks in queue, try later"},
 		},
 	)
 
 	barcodeRegex := regexp.MustCompile(`^[0-9]{10}$`)
+	tplNameRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{3,40}$`)
 
 	router := &Router{
 		service:      service,
 		errMapper:    mapping,
 		barcodeRegex: barcodeRegex,
+		tplNameRegex: tplNameRegex,
 	}
 
 	return router
@@ -57,6 +73,8 @@ func (p *Router) InitRoutes() *gin.Engine {
 	r.GET("/:barcode", p.userProduct)
 	r.DELETE("/:barcode", p.delete)
 	r.GET("/:barcode/check", p.genCheck)
+	r.POST("/:barcode/checks", p.enqueueCheck)
+	r.GET("/:barcode/checks", p.productChecks)
 	r.GET("/check/:checkName", p.productCheck)
 	return r
 }
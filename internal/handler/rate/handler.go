@@ -0,0 +1,121 @@
+package rate
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/service/exchange"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxListBytes limits size of uploaded rates list
+const maxListBytes = 2 << 20
+
+// formats maps content types of rates list on its formats
+var formats = map[string]exchange.Format{
+	"text/csv":         exchange.FormatCSV,
+	"application/json": exchange.FormatJSON,
+}
+
+// Rate model used to parse exchange rate into JSON response
+type Rate struct {
+	Base          string    `json:"base"`
+	Quote         string    `json:"quote"`
+	Rate          string    `json:"rate"`
+	EffectiveFrom string    `json:"effectiveFrom"`
+	Created       time.Time `json:"created"`
+}
+
+// @Summary      Returns exchange rates
+// @Description  returns all exchange rates ordered by pair, newest first inside pair; rate is price of one unit of base currency in quote currency, it is used from effectiveFrom until the next rate of the pair
+// @Tags         rate
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /rates/ [get]
+func (rt *Router) rates(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	rates, err := rt.service.ExchangeRates(c.Request.Context())
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler": "rate",
+			"func":    "rates",
+		}).WithError(err).Error("Error retrieving exchange rates")
+
+		errInf := rt.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Rate, len(rates))
+	for i, r := range rates {
+		res[i] = Rate{
+			Base:          r.Base,
+			Quote:         r.Quote,
+			Rate:          money.FormatRate(r.Rate),
+			EffectiveFrom: r.EffectiveFrom.Format(exchange.DateLayout),
+			Created:       r.Created,
+		}
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Rates", res))
+}
+
+// @Summary      Import exchange rates
+// @Description  saves exchange rates sent as CSV(base,quote,rate,effectiveFrom with optional header) or JSON array of objects with the same fields, effectiveFrom is YYYY-MM-DD; rate replaces one of the same pair and date, only admins listed in configuration can import rates
+// @Tags         rate
+// @Accept       text/csv,application/json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        rates  body      string true "rates list"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      403  {object}  response.JSONResult
+// @Failure      413  {object}  response.JSONResult
+// @Failure      415  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /rates/ [put]
+func (rt *Router) importRates(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	format := formats[mediaType]
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxListBytes)
+	n, err := rt.service.ImportExchangeRates(c.Request.Context(), body, format, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "rate",
+			"func":      "importRates",
+			"userLogin": login,
+		}).WithError(err).Error("Error importing exchange rates")
+
+		var rateErr *exchange.RateError
+		if errors.As(err, &rateErr) {
+			c.JSON(http.StatusBadRequest, response.CreateJSONResult("Error", rateErr.Error()))
+			return
+		}
+
+		errInf := rt.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Imported", n))
+}
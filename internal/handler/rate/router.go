@@ -0,0 +1,49 @@
+package rate
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/service/exchange"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call exchange rate related service level logic
+type Service interface {
+	ExchangeRates(ctx context.Context) ([]exchange.Rate, error)
+	ImportExchangeRates(ctx context.Context, list io.Reader, format exchange.Format, login string) (int, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			exchange.ErrNotAdmin:      mapper.ErrorInfo{StatusCode: http.StatusForbidden, Msg: "Only admins can change exchange rates"},
+			exchange.ErrUnknownFormat: mapper.ErrorInfo{StatusCode: http.StatusUnsupportedMediaType, Msg: "Rates should be sent as text/csv or application/json"},
+			exchange.ErrRatesFormat:   mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Rates list is malformed"},
+			exchange.ErrNoRates:       mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Rates list is empty"},
+			exchange.ErrTooManyRates:  mapper.ErrorInfo{StatusCode: http.StatusRequestEntityTooLarge, Msg: "Rates list is too long"},
+		},
+	)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+	}
+
+	return router
+}
+
+func (rt *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/", rt.rates)
+	r.PUT("/", rt.importRates)
+	return r
+}
@@ -0,0 +1,49 @@
+package response
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SendCheck serves check content with support of byte ranges and conditional requests,
+// headers are already sent when transfer is interrupted so error is only logged
+func SendCheck(c *gin.Context, f *product.CheckFile) {
+	defer f.Content.Close()
+
+	c.Header("Content-Type", f.ContentType)
+	// format of check is negotiated by Accept header, caches must not serve one format for another
+	c.Header("Vary", "Accept")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Name}))
+	if f.SHA256 != "" {
+		c.Header("ETag", `"`+f.SHA256+`"`)
+	}
+
+	w := &transferWriter{ResponseWriter: c.Writer}
+	http.ServeContent(w, c.Request, f.Name, f.Modified, f.Content)
+
+	if w.err != nil {
+		logrus.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"func":  "SendCheck",
+			"check": f.Name,
+		}).WithError(w.err).Warn("Check transfer interrupted")
+	}
+}
+
+// transferWriter remembers first error of writing response body
+type transferWriter struct {
+	http.ResponseWriter
+	err error
+}
+
+func (w *transferWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkContentTypes maps content types to check formats
+var checkContentTypes = map[string]render.Format{
+	"application/pdf":        render.FormatPDF,
+	"text/html":              render.FormatHTML,
+	"image/png":              render.FormatPNG,
+	"application/vnd.escpos": render.FormatESCPOS,
+}
+
+// offeredContentTypes lists content types of check formats in order of preference
+var offeredContentTypes = []string{"application/pdf", "text/html", "image/png", "application/vnd.escpos"}
+
+// CheckFormat returns check format chosen by format query parameter or Accept header,
+// PDF is used if neither of them is set or Accept header doesn't match any format
+func CheckFormat(c *gin.Context) render.Format {
+	if f := c.Query("format"); f != "" {
+		return render.Format(f)
+	}
+
+	if f, ok := checkContentTypes[c.NegotiateFormat(offeredContentTypes...)]; ok {
+		return f
+	}
+
+	return render.FormatPDF
+}
+
+// Currency returns currency amounts should be converted to chosen by currency query parameter,
+// empty string means amounts are returned in their own currencies
+func Currency(c *gin.Context) (string, error) {
+	cur := c.Query("currency")
+	if cur == "" {
+		return "", nil
+	}
+
+	return money.ParseCurrency(cur)
+}
@@ -1,10 +1,24 @@
 package handler
 
 import (
+	"expvar"
+
 	docs "github.com/AnisaForWork/user_orders/api/docs"
 	"github.com/AnisaForWork/user_orders/internal/handler/auth"
+	"github.com/AnisaForWork/user_orders/internal/handler/check"
+	"github.com/AnisaForWork/user_orders/internal/handler/coupon"
+	"github.com/AnisaForWork/user_orders/internal/handler/inventory"
+	"github.com/AnisaForWork/user_orders/internal/handler/job"
 	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/order"
 	"github.com/AnisaForWork/user_orders/internal/handler/product"
+	"github.com/AnisaForWork/user_orders/internal/handler/rate"
+	"github.com/AnisaForWork/user_orders/internal/handler/share"
+	"github.com/AnisaForWork/user_orders/internal/handler/stock"
+	"github.com/AnisaForWork/user_orders/internal/handler/template"
+	"github.com/AnisaForWork/user_orders/internal/handler/transfer"
+	"github.com/AnisaForWork/user_orders/internal/handler/user"
+	"github.com/AnisaForWork/user_orders/internal/handler/warehouse"
 
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
@@ -16,6 +30,18 @@ type Service interface {
 	middleware.Service
 	auth.Service
 	product.Service
+	template.Service
+	job.Service
+	check.Service
+	user.Service
+	share.Service
+	order.Service
+	coupon.Service
+	rate.Service
+	stock.Service
+	warehouse.Service
+	transfer.Service
+	inventory.Service
 }
 
 // @title           User products service API
@@ -51,14 +77,52 @@ func NewRouter(service Service, isRelease bool) *gin.Engine {
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	docs.SwaggerInfo.BasePath = "/"
 
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
 	authR := auth.NewRouter(service)
 	Mount("/auth", router, authR.InitRoutes().Routes())
 
+	shareR := share.NewRouter(service)
+	Mount("/public/checks", router, shareR.InitRoutes().Routes())
+
 	authenticated := router.Group("/", middl.Authentication())
 
 	prod := product.NewRouter(service)
 	Mount("/product", authenticated, prod.InitRoutes().Routes())
 
+	tpl := template.NewRouter(service)
+	Mount("/templates", authenticated, tpl.InitRoutes().Routes())
+
+	jb := job.NewRouter(service)
+	Mount("/jobs", authenticated, jb.InitRoutes().Routes())
+
+	ch := check.NewRouter(service)
+	Mount("/checks", authenticated, ch.InitRoutes().Routes())
+
+	ord := order.NewRouter(service)
+	Mount("/orders", authenticated, ord.InitRoutes().Routes())
+
+	cpn := coupon.NewRouter(service)
+	Mount("/coupons", authenticated, cpn.InitRoutes().Routes())
+
+	rt := rate.NewRouter(service)
+	Mount("/rates", authenticated, rt.InitRoutes().Routes())
+
+	stk := stock.NewRouter(service)
+	Mount("/stock", authenticated, stk.InitRoutes().Routes())
+
+	wh := warehouse.NewRouter(service)
+	Mount("/warehouses", authenticated, wh.InitRoutes().Routes())
+
+	tr := transfer.NewRouter(service)
+	Mount("/transfers", authenticated, tr.InitRoutes().Routes())
+
+	in := inventory.NewRouter(service)
+	Mount("/inventory", authenticated, in.InitRoutes().Routes())
+
+	usr := user.NewRouter(service)
+	Mount("/users", authenticated, usr.InitRoutes().Routes())
+
 	return router
 }
 
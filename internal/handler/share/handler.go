@@ -0,0 +1,39 @@
+package share
+
+import (
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary      Returns shared check
+// @Description  sends check by public share link without authentication, every request uses one download of link
+// @Tags         share
+// @Produce  	 application/pdf,text/html,image/png,application/vnd.escpos
+// @Param 		 token   path   string true  "Share link token"
+// @Param 		 format  query  string false "Check format" Enums(pdf, html, png, escpos)
+// @Success 	 200 {file} PdfFile
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /public/checks/{token} [get]
+func (s *Router) sharedCheck(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	f, err := s.service.SharedCheck(c.Request.Context(), c.Param("token"), response.CheckFormat(c))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler": "share",
+			"func":    "sharedCheck",
+		}).WithError(err).Warn("Error sending shared check")
+
+		errInf := s.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	response.SendCheck(c, f)
+}
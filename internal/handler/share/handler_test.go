@@ -0,0 +1,170 @@
+package share
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shareRepo keeps one share link of check.pdf, downloads are taken the way conditional update does
+type shareRepo struct {
+	product.Repository
+	mu           sync.Mutex
+	maxDownloads int
+	downloads    int
+	fileName     string // shared file name, check.pdf if empty
+}
+
+func (r *shareRepo) CreateCheckShare(ctx context.Context, sh mysql.CheckShare, login string) (int64, error) {
+	r.maxDownloads = int(sh.MaxDownloads.Int64)
+	return 1, nil
+}
+
+func (r *shareRepo) UseCheckShare(ctx context.Context, id int64, at time.Time) (*mysql.SharedCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id != 1 || (r.maxDownloads > 0 && r.downloads >= r.maxDownloads) {
+		return nil, mysql.ErrNoRows
+	}
+	r.downloads++
+
+	name := r.fileName
+	if name == "" {
+		name = "check.pdf"
+	}
+	return &mysql.SharedCheck{FileName: name, Login: "owner"}, nil
+}
+
+func (r *shareRepo) ReturnCheckShareDownload(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.downloads--
+	return nil
+}
+
+func (r *shareRepo) UserCheckByName(ctx context.Context, filename string, login string) (*mysql.Check, error) {
+	return &mysql.Check{FileName: filename, SHA256: "abc", Created: time.Unix(1700000000, 0)}, nil
+}
+
+// testShare returns share link handler and token of link allowing given number of downloads
+func testShare(t *testing.T, maxDownloads int) (*gin.Engine, *shareRepo, string) {
+	st, err := store.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Put(context.Background(), "check.pdf", strings.NewReader("%PDF-1.4 check")); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &shareRepo{}
+	s := &product.PService{
+		Repo:        repo,
+		Store:       st,
+		Renderers:   render.Renderers{render.FormatPDF: &render.PDF{}},
+		ShareSecret: []byte("secret"),
+		ShareTTL:    time.Hour,
+	}
+
+	sh, err := s.CreateShare(context.Background(), "check.pdf", 0, maxDownloads, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	return NewRouter(s).InitRoutes(), repo, sh.Token
+}
+
+func get(h http.Handler, path string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestSharedCheckRangeUsesDownload(t *testing.T) {
+	h, repo, token := testShare(t, 2)
+
+	// whole file can be fetched by open range, such requests must use up link as well
+	for i := 0; i < 2; i++ {
+		if code := get(h, "/"+token, map[string]string{"Range": "bytes=0-"}); code != http.StatusPartialContent {
+			t.Fatalf("range request %d returned %d, want %d", i, code, http.StatusPartialContent)
+		}
+	}
+
+	if code := get(h, "/"+token, map[string]string{"If-None-Match": `"abc"`}); code != http.StatusNotFound {
+		t.Fatalf("request of used up link returned %d, want %d", code, http.StatusNotFound)
+	}
+	if code := get(h, "/"+token, nil); code != http.StatusNotFound {
+		t.Fatalf("request of used up link returned %d, want %d", code, http.StatusNotFound)
+	}
+	if repo.downloads != 2 {
+		t.Fatalf("link has %d downloads, want 2", repo.downloads)
+	}
+}
+
+func TestSharedCheckConcurrent(t *testing.T) {
+	const maxDownloads, requests = 3, 20
+
+	h, repo, token := testShare(t, maxDownloads)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		codes = map[int]int{}
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code := get(h, "/"+token, nil)
+			mu.Lock()
+			codes[code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if codes[http.StatusOK] != maxDownloads || codes[http.StatusNotFound] != requests-maxDownloads {
+		t.Fatalf("concurrent requests returned %v, want %d downloads", codes, maxDownloads)
+	}
+	if repo.downloads != maxDownloads {
+		t.Fatalf("link has %d downloads, want %d", repo.downloads, maxDownloads)
+	}
+}
+
+func TestSharedCheckGivesDownloadBack(t *testing.T) {
+	h, repo, token := testShare(t, 1)
+
+	if code := get(h, "/"+token+"x", nil); code != http.StatusNotFound {
+		t.Fatalf("request with invalid token returned %d, want %d", code, http.StatusNotFound)
+	}
+
+	repo.fileName = "missing.pdf"
+	if code := get(h, "/"+token, nil); code != http.StatusNotFound {
+		t.Fatalf("request of missing check returned %d, want %d", code, http.StatusNotFound)
+	}
+	if repo.downloads != 0 {
+		t.Fatalf("failed requests left %d downloads, want 0", repo.downloads)
+	}
+
+	repo.fileName = ""
+	if code := get(h, "/"+token, nil); code != http.StatusOK {
+		t.Fatalf("request after failed ones returned %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,47 @@
+package share
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to get checks by public share links
+type Service interface {
+	SharedCheck(ctx context.Context, token string, format render.Format) (*product.CheckFile, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			product.ErrShareInvalid: mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Link is invalid or expired"},
+			product.ErrNoSharing:    mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Link is invalid or expired"},
+			store.ErrNotFound:       mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Check file not found"},
+			render.ErrUnknownFormat: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown format, supported: pdf, html, png, escpos"},
+		},
+	)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+	}
+
+	return router
+}
+
+func (s *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/:token", s.sharedCheck)
+	return r
+}
@@ -0,0 +1,319 @@
+package stock
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Posted used to parse request body with new stock movement, quantity of receipt, sale and return
+// is amount of goods, adjustment takes signed change of stock; warehouse with lowest priority is used
+// when warehouse code isn't set
+type Posted struct {
+	Kind      string `json:"kind" binding:"required,oneof=receipt sale adjustment return" default:"receipt"`
+	Quantity  int64  `json:"quantity" binding:"required,min=-1000000,max=1000000" default:"10"`
+	Warehouse string `json:"warehouse" binding:"max=20" default:""`
+	Note      string `json:"note" binding:"max=200" default:""`
+}
+
+// Movement model used to parse stock movement into JSON response, quantity is signed change of stock in warehouse
+type Movement struct {
+	ID         int64     `json:"id"`
+	Barcode    string    `json:"barcode"`
+	Warehouse  string    `json:"warehouse"`
+	Kind       string    `json:"kind"`
+	Quantity   int64     `json:"quantity"`
+	OrderID    int64     `json:"orderId,omitempty"`
+	TransferID int64     `json:"transferId,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Actor      string    `json:"actor"`
+	Created    time.Time `json:"created"`
+}
+
+// Level model used to parse current stock of product into JSON response, stock is goods on hand,
+// reserved is part of it held for placed orders and available is the rest
+type Level struct {
+	Barcode    string           `json:"barcode"`
+	Name       string           `json:"name"`
+	Stock      int64            `json:"stock"`
+	Reserved   int64            `json:"reserved"`
+	Available  int64            `json:"available"`
+	Updated    *time.Time       `json:"updated,omitempty"`
+	Warehouses []WarehouseLevel `json:"warehouses,omitempty"`
+}
+
+// WarehouseLevel model used to parse stock of product in warehouse into JSON response
+type WarehouseLevel struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Stock     int64  `json:"stock"`
+	Reserved  int64  `json:"reserved"`
+	Available int64  `json:"available"`
+}
+
+func newMovement(m *stock.Movement) Movement {
+	return Movement{
+		ID:         m.ID,
+		Barcode:    m.Barcode,
+		Warehouse:  m.Warehouse,
+		Kind:       string(m.Kind),
+		Quantity:   m.Quantity,
+		OrderID:    m.OrderID,
+		TransferID: m.TransferID,
+		Note:       m.Note,
+		Actor:      m.Actor,
+		Created:    m.Created,
+	}
+}
+
+func newLevel(l *stock.Level) Level {
+	res := Level{
+		Barcode:   l.Barcode,
+		Name:      l.Name,
+		Stock:     l.Stock,
+		Reserved:  l.Reserved,
+		Available: l.Available,
+		Updated:   l.Updated,
+	}
+	if l.Warehouses != nil {
+		res.Warehouses = make([]WarehouseLevel, len(l.Warehouses))
+		for i, w := range l.Warehouses {
+			res.Warehouses[i] = WarehouseLevel{
+				Code:      w.Code,
+				Name:      w.Name,
+				Stock:     w.Stock,
+				Reserved:  w.Reserved,
+				Available: w.Available,
+			}
+		}
+	}
+	return res
+}
+
+// @Summary      Post stock movement
+// @Description  records receipt, sale, adjustment or return of user product in stock ledger of warehouse(warehouse with lowest priority when it isn't set), movements are never changed, wrong one is corrected by adjustment; sale and negative adjustment are rejected when they take goods reserved for placed orders or make warehouse stock negative unless it's allowed by configuration
+// @Tags         stock
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path      string true  "Product barcode"
+// @Param        movement  body      stock.Posted true "movement"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /stock/{barcode}/movements [post]
+func (st *Router) postMovement(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !st.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	var req Posted
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	if req.Warehouse != "" && !st.warehouseRegex.MatchString(req.Warehouse) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("warehouse", "should consist of 2-20 letters, numbers, _ or -"))
+		return
+	}
+
+	m, err := st.service.PostMovement(c.Request.Context(), barcode, stock.Movement{
+		Kind:      stock.Kind(req.Kind),
+		Quantity:  req.Quantity,
+		Warehouse: req.Warehouse,
+		Note:      req.Note,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "stock",
+			"func":      "postMovement",
+			"userLogin": login,
+			"barcode":   barcode,
+			"kind":      req.Kind,
+		}).WithError(err).Error("Error posting stock movement")
+
+		errInf := st.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Movement", newMovement(m)))
+}
+
+// @Summary      Returns product stock
+// @Description  returns current stock of user product, it is sum of all its movements, part of it reserved for placed orders and available rest, in total and in every active warehouse
+// @Tags         stock
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path      string true  "Product barcode"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /stock/{barcode} [get]
+func (st *Router) productStock(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !st.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	lvl, err := st.service.ProductStock(c.Request.Context(), barcode, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "stock",
+			"func":      "productStock",
+			"userLogin": login,
+			"barcode":   barcode,
+		}).WithError(err).Error("Error retrieving product stock")
+
+		errInf := st.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Stock", newLevel(lvl)))
+}
+
+// @Summary      Returns stock of user products with pagination
+// @Description  returns current stock of user products in order they were created
+// @Tags         stock
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of products per page" minimum(1)    maximum(100)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /stock/all [get]
+func (st *Router) userStock(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	productsPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (productsPerPage < 1 || productsPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	levels, err := st.service.UserStock(c.Request.Context(), page, productsPerPage, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "stock",
+			"func":       "userStock",
+			"userLogin":  login,
+			"page":       page,
+			"numPerPage": productsPerPage,
+		}).WithError(err).Error("Error retrieving user stock")
+
+		errInf := st.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Level, len(levels))
+	for i := range levels {
+		res[i] = newLevel(&levels[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Stock", res))
+}
+
+// @Summary      Returns stock movements of product with pagination
+// @Description  returns stock ledger of user product, newest first, movements made by orders have order id
+// @Tags         stock
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 barcode   path  string true "Product barcode"
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of movements per page" minimum(1)    maximum(100)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /stock/{barcode}/movements [get]
+func (st *Router) movements(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	barcode := c.Param("barcode")
+	if !st.barcodeRegex.MatchString(barcode) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("barcode", "should consist of ten numeric numbers"))
+		return
+	}
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	movementsPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (movementsPerPage < 1 || movementsPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	movements, err := st.service.StockMovements(c.Request.Context(), barcode, page, movementsPerPage, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "stock",
+			"func":       "movements",
+			"userLogin":  login,
+			"barcode":    barcode,
+			"page":       page,
+			"numPerPage": movementsPerPage,
+		}).WithError(err).Error("Error retrieving stock movements")
+
+		errInf := st.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Movement, len(movements))
+	for i := range movements {
+		res[i] = newMovement(&movements[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Movements", res))
+}
@@ -0,0 +1,60 @@
+package stock
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call stock related service level logic
+type Service interface {
+	PostMovement(ctx context.Context, barcode string, m stock.Movement, login string) (*stock.Movement, error)
+	ProductStock(ctx context.Context, barcode string, login string) (*stock.Level, error)
+	UserStock(ctx context.Context, page, productsPerPage int, login string) ([]stock.Level, error)
+	StockMovements(ctx context.Context, barcode string, page, movementsPerPage int, login string) ([]stock.Movement, error)
+}
+
+type Router struct {
+	service        Service
+	errMapper      mapper.ErrorMapper
+	barcodeRegex   *regexp.Regexp
+	warehouseRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:      mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			stock.ErrUnknownKind: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown movement kind, supported: receipt, sale, adjustment, return"},
+			stock.ErrQuantity:    mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Quantity should be positive, adjustment can't be zero"},
+			stock.ErrOutOfStock:  mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Not enough stock in warehouse"},
+		},
+	)
+
+	barcodeRegex := regexp.MustCompile(`^[0-9]{10}$`)
+	warehouseRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{2,20}$`)
+
+	router := &Router{
+		service:        service,
+		errMapper:      mapping,
+		barcodeRegex:   barcodeRegex,
+		warehouseRegex: warehouseRegex,
+	}
+
+	return router
+}
+
+func (st *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/all", st.userStock)
+	r.GET("/:barcode", st.productStock)
+	r.GET("/:barcode/movements", st.movements)
+	r.POST("/:barcode/movements", st.postMovement)
+	return r
+}
@@ -0,0 +1,260 @@
+package template
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Template model used to parse into JSON response
+type Template struct {
+	Name      string        `json:"name"`
+	Version   int           `json:"version"`
+	IsDefault bool          `json:"isDefault"`
+	Layout    render.Layout `json:"layout"`
+	Created   *time.Time    `json:"created,omitempty"`
+}
+
+// @Summary      Upload check template
+// @Description  user uploads PDF template with layout(JSON) describing where product info is printed and optional TTF font, uploading template with existing name creates its new version
+// @Tags         template
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name      formData  string true  "Template name"
+// @Param        layout    formData  string true  "Layout JSON: width,height, barcode,name,cost and optional date fields with x,y,fontSize, optional labels with x,y,fontSize and text translations by language tag"
+// @Param        template  formData  file   true  "PDF template"
+// @Param        font      formData  file   false "TTF font"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      413  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /templates/ [post]
+func (t *Router) create(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.PostForm("name")
+	if !t.tplNameRegex.MatchString(name) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("name", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	var layout render.Layout
+	if err := json.Unmarshal([]byte(c.PostForm("layout")), &layout); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("layout", "should be valid JSON"))
+		return
+	}
+
+	pdfHeader, err := c.FormFile("template")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "PDF file is required"))
+		return
+	}
+
+	pdf, err := pdfHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("template", "can't read file"))
+		return
+	}
+	defer pdf.Close()
+
+	nt := template.NewTemplate{
+		Name:   name,
+		Layout: layout,
+		PDF:    pdf,
+	}
+
+	if fontHeader, err := c.FormFile("font"); err == nil {
+		font, err := fontHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, validator.ErrorMsg("font", "can't read file"))
+			return
+		}
+		defer font.Close()
+
+		nt.Font = font
+	}
+
+	tpl, err := t.service.CreateTemplate(c.Request.Context(), nt, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "template",
+			"func":      "create",
+			"userLogin": login,
+			"template":  name,
+		}).WithError(err).Error("Error during creating template")
+
+		errInf := t.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Template created", toResponse(tpl)))
+}
+
+// @Summary      Returns all user templates
+// @Description  returns latest versions of user templates and builtin template, marks the one used by default
+// @Tags         template
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /templates/all [get]
+func (t *Router) allUserTemplates(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	tpls, err := t.service.Templates(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "template",
+			"func":      "allUserTemplates",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving user templates")
+
+		errInf := t.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Template, len(tpls))
+	for i := range tpls {
+		res[i] = toResponse(&tpls[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Templates", res))
+}
+
+// @Summary      Preview template
+// @Description  renders template with sample product data, format is chosen by format parameter or Accept header(PDF by default)
+// @Tags         template
+// @Accept       x-www-form-urlencoded
+// @Security     ApiKeyAuth
+// @Param 		 name      path   string true  "Template name"
+// @Param 		 version   query  int    false "Template version, latest if not set" minimum(1)
+// @Param 		 format    query  string false "Preview format" Enums(pdf, html, png, escpos)
+// @Produce  	 application/pdf,text/html,image/png,application/vnd.escpos
+// @Success 	 200 {file} PdfFile
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /templates/{name}/preview [get]
+func (t *Router) preview(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.Param("name")
+	if !t.tplNameRegex.MatchString(name) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("name", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	version := 0
+	if v := c.Query("version"); v != "" {
+		var err error
+		version, err = strconv.Atoi(v)
+		if err != nil || version < 1 {
+			c.JSON(http.StatusBadRequest, validator.ErrorMsg("version", "should be positive number"))
+			return
+		}
+	}
+
+	preview, contentType, err := t.service.TemplatePreview(c.Request.Context(), name, version, response.CheckFormat(c), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "template",
+			"func":      "preview",
+			"userLogin": login,
+			"template":  name,
+		}).WithError(err).Error("Error rendering template preview")
+
+		errInf := t.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.Header("Content-type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, preview); err != nil {
+		log.WithError(err).Warn("Could not send template preview")
+	}
+}
+
+// @Summary      Set default template
+// @Description  template is used for user checks when no template chosen in request
+// @Tags         template
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 name      path   string true  "Template name"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /templates/{name}/default [put]
+func (t *Router) setDefault(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	name := c.Param("name")
+	if !t.tplNameRegex.MatchString(name) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("name", "should consist of 3-40 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	err := t.service.SetDefaultTemplate(c.Request.Context(), name, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "template",
+			"func":      "setDefault",
+			"userLogin": login,
+			"template":  name,
+		}).WithError(err).Error("Error setting default template")
+
+		errInf := t.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Default template set"))
+}
+
+func toResponse(tpl *template.Template) Template {
+	res := Template{
+		Name:      tpl.Name,
+		Version:   tpl.Version,
+		IsDefault: tpl.IsDefault,
+		Layout:    tpl.Layout,
+	}
+	if !tpl.Created.IsZero() {
+		created := tpl.Created
+		res.Created = &created
+	}
+	return res
+}
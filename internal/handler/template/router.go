@@ -0,0 +1,62 @@
+package template
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call template service level logic
+type Service interface {
+	CreateTemplate(ctx context.Context, nt template.NewTemplate, login string) (*template.Template, error)
+	Templates(ctx context.Context, login string) ([]template.Template, error)
+	SetDefaultTemplate(ctx context.Context, name string, login string) error
+	TemplatePreview(ctx context.Context, name string, version int, format render.Format, login string) (io.Reader, string, error)
+}
+
+type Router struct {
+	service      Service
+	errMapper    mapper.ErrorMapper
+	tplNameRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:              mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			mysql.ErrUniqConstrViolation: mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Already exists"},
+			render.ErrInvalidLayout:      mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Layout is invalid"},
+			render.ErrUnknownFormat:      mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Unknown format, supported: pdf, html, png, escpos"},
+			template.ErrNotPDF:           mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Template should be PDF file"},
+			template.ErrTooLarge:         mapper.ErrorInfo{StatusCode: http.StatusRequestEntityTooLarge, Msg: "Template file is too large"},
+			template.ErrReservedName:     mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Template name is reserved"},
+		},
+	)
+
+	tplNameRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{3,40}$`)
+
+	router := &Router{
+		service:      service,
+		errMapper:    mapping,
+		tplNameRegex: tplNameRegex,
+	}
+
+	return router
+}
+
+func (t *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/", t.create)
+	r.GET("/all", t.allUserTemplates)
+	r.GET("/:name/preview", t.preview)
+	r.PUT("/:name/default", t.setDefault)
+	return r
+}
@@ -0,0 +1,228 @@
+package transfer
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Created used to parse request body with new transfer between warehouses(codes)
+type Created struct {
+	From  string        `json:"from" binding:"required" default:"MAIN"`
+	To    string        `json:"to" binding:"required" default:"STORE"`
+	Items []CreatedItem `json:"items" binding:"required,min=1,max=100,dive"`
+	Note  string        `json:"note" binding:"max=200" default:""`
+}
+
+// CreatedItem used to parse transfer line
+type CreatedItem struct {
+	Barcode  string `json:"barcode" binding:"required,len=10,numeric" default:"1234567890"`
+	Quantity int64  `json:"quantity" binding:"required,min=1,max=1000000" default:"1"`
+}
+
+// Transfer model used to parse transfer into JSON response
+type Transfer struct {
+	ID         int64     `json:"id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Note       string    `json:"note,omitempty"`
+	Actor      string    `json:"actor"`
+	ItemsCount int       `json:"itemsCount"`
+	Items      []Item    `json:"items,omitempty"`
+	Created    time.Time `json:"created"`
+}
+
+// Item model used to parse transfer line into JSON response
+type Item struct {
+	Barcode  string `json:"barcode"`
+	Quantity int64  `json:"quantity"`
+}
+
+func newTransfer(t *stock.Transfer) Transfer {
+	res := Transfer{
+		ID:         t.ID,
+		From:       t.From,
+		To:         t.To,
+		Note:       t.Note,
+		Actor:      t.Actor,
+		ItemsCount: t.Lines,
+		Created:    t.Created,
+	}
+	for _, it := range t.Items {
+		res.Items = append(res.Items, Item{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		})
+	}
+	return res
+}
+
+// @Summary      Create transfer
+// @Description  moves quantities of user products from one active warehouse to another in one transaction, every line is recorded as pair of transfer movements in stock ledger; transfer is rejected when it takes goods reserved for placed orders or makes stock of source warehouse negative unless it's allowed by configuration
+// @Tags         transfer
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        transfer  body      transfer.Created true "transfer"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /transfers/ [post]
+func (tr *Router) create(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Created
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	if !tr.codeRegex.MatchString(req.From) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("from", "should consist of 2-20 latin letters, numbers, '_' or '-'"))
+		return
+	}
+	if !tr.codeRegex.MatchString(req.To) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("to", "should consist of 2-20 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	items := make([]stock.TransferItem, len(req.Items))
+	for i, it := range req.Items {
+		items[i] = stock.TransferItem{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		}
+	}
+
+	t, err := tr.service.CreateTransfer(c.Request.Context(), stock.Transfer{
+		From:  req.From,
+		To:    req.To,
+		Note:  req.Note,
+		Items: items,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "transfer",
+			"func":      "create",
+			"userLogin": login,
+			"from":      req.From,
+			"to":        req.To,
+		}).WithError(err).Error("Error creating transfer")
+
+		errInf := tr.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Transfer", newTransfer(t)))
+}
+
+// @Summary      Returns user transfers with pagination
+// @Description  returns transfers between warehouses of user with number of lines, newest first
+// @Tags         transfer
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of transfers per page" minimum(1)    maximum(100)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /transfers/all [get]
+func (tr *Router) userTransfers(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	transfersPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (transfersPerPage < 1 || transfersPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	transfers, err := tr.service.UserTransfers(c.Request.Context(), page, transfersPerPage, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "transfer",
+			"func":       "userTransfers",
+			"userLogin":  login,
+			"page":       page,
+			"numPerPage": transfersPerPage,
+		}).WithError(err).Error("Error retrieving user transfers")
+
+		errInf := tr.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Transfer, len(transfers))
+	for i := range transfers {
+		res[i] = newTransfer(&transfers[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Transfers", res))
+}
+
+// @Summary      Returns user transfer
+// @Description  returns transfer between warehouses with its lines, only owner of transfer can view it
+// @Tags         transfer
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 id   path      int true  "Transfer id"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /transfers/{id} [get]
+func (tr *Router) userTransfer(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("id", "should be positive number"))
+		return
+	}
+
+	t, err := tr.service.UserTransfer(c.Request.Context(), id, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "transfer",
+			"func":      "userTransfer",
+			"userLogin": login,
+			"transfer":  id,
+		}).WithError(err).Error("Error retrieving transfer")
+
+		errInf := tr.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Transfer", newTransfer(t)))
+}
@@ -0,0 +1,56 @@
+package transfer
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call transfer related service level logic
+type Service interface {
+	CreateTransfer(ctx context.Context, t stock.Transfer, login string) (*stock.Transfer, error)
+	UserTransfers(ctx context.Context, page, transfersPerPage int, login string) ([]stock.Transfer, error)
+	UserTransfer(ctx context.Context, id int64, login string) (*stock.Transfer, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+	codeRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:        mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			stock.ErrSameWarehouse: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Source and destination should be different warehouses"},
+			stock.ErrTransferItems: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Transfer should have 1-100 lines with different products"},
+			stock.ErrQuantity:      mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Quantity should be positive"},
+			stock.ErrOutOfStock:    mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Not enough stock in source warehouse"},
+		},
+	)
+
+	codeRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{2,20}$`)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+		codeRegex: codeRegex,
+	}
+
+	return router
+}
+
+func (tr *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/", tr.create)
+	r.GET("/all", tr.userTransfers)
+	r.GET("/:id", tr.userTransfer)
+	return r
+}
@@ -0,0 +1,210 @@
+package user
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Settings model used to parse request body and into JSON response
+type Settings struct {
+	Locale   string `json:"locale" binding:"required,max=35" default:"en-US"`
+	Currency string `json:"currency" binding:"required,len=3" default:"USD"`
+	TimeZone string `json:"timeZone" binding:"required,max=64" default:"UTC"`
+}
+
+// @Summary      Returns user settings
+// @Description  returns locale, currency and time zone used to format user checks
+// @Tags         user
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /users/settings [get]
+func (u *Router) settings(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	st, err := u.service.Settings(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "user",
+			"func":      "settings",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving user settings")
+
+		errInf := u.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Settings", toResponse(st)))
+}
+
+// @Summary      Update user settings
+// @Description  user sets locale(BCP 47 tag), currency(ISO 4217 code) and time zone(IANA name) used to format amounts, dates and template labels on checks
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        settings  body  user.Settings true "locale,currency,timeZone"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /users/settings [put]
+func (u *Router) updateSettings(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Settings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	st, err := u.service.UpdateSettings(c.Request.Context(), user.Settings{
+		Locale:   req.Locale,
+		Currency: req.Currency,
+		TimeZone: req.TimeZone,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "user",
+			"func":      "updateSettings",
+			"userLogin": login,
+		}).WithError(err).Error("Error updating user settings")
+
+		errInf := u.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Settings updated", toResponse(st)))
+}
+
+func toResponse(st *user.Settings) Settings {
+	return Settings{
+		Locale:   st.Locale,
+		Currency: st.Currency,
+		TimeZone: st.TimeZone,
+	}
+}
+
+// Seller model used to parse request body and into JSON response, VAT rate is in percent
+type Seller struct {
+	Name    string   `json:"name" binding:"max=120" default:"Products LLC"`
+	TaxID   string   `json:"taxId" binding:"max=32" default:"1234567890"`
+	Address string   `json:"address" binding:"max=255" default:"1 Main St, Springfield"`
+	VATRate *float64 `json:"vatRate,omitempty" binding:"omitempty,min=0,max=100" default:"20"`
+}
+
+// @Summary      Returns seller profile
+// @Description  returns seller legal name, tax ID, address and VAT rate printed on user checks, VAT rate is absent when default rate is used
+// @Tags         user
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /users/seller [get]
+func (u *Router) seller(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	sl, err := u.service.Seller(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "user",
+			"func":      "seller",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving seller profile")
+
+		errInf := u.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Seller", toSellerResponse(sl)))
+}
+
+// @Summary      Update seller profile
+// @Description  user sets seller legal name, tax ID, address and VAT rate(percent, default rate is used if not set) printed on checks generated after update
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        seller  body  user.Seller true "name,taxId,address,vatRate"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /users/seller [put]
+func (u *Router) updateSeller(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Seller
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	sl := user.Seller{
+		Name:    req.Name,
+		TaxID:   req.TaxID,
+		Address: req.Address,
+	}
+	if req.VATRate != nil {
+		rate := int(math.Round(*req.VATRate * 100))
+		sl.VATRate = &rate
+	}
+
+	res, err := u.service.UpdateSeller(c.Request.Context(), sl, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "user",
+			"func":      "updateSeller",
+			"userLogin": login,
+		}).WithError(err).Error("Error updating seller profile")
+
+		errInf := u.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Seller updated", toSellerResponse(res)))
+}
+
+func toSellerResponse(sl *user.Seller) Seller {
+	res := Seller{
+		Name:    sl.Name,
+		TaxID:   sl.TaxID,
+		Address: sl.Address,
+	}
+	if sl.VATRate != nil {
+		rate := float64(*sl.VATRate) / 100
+		res.VATRate = &rate
+	}
+	return res
+}
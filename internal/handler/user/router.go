@@ -0,0 +1,53 @@
+package user
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call user service level logic
+type Service interface {
+	Settings(ctx context.Context, login string) (*user.Settings, error)
+	UpdateSettings(ctx context.Context, st user.Settings, login string) (*user.Settings, error)
+	Seller(ctx context.Context, login string) (*user.Seller, error)
+	UpdateSeller(ctx context.Context, sl user.Seller, login string) (*user.Seller, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:           mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			locale.ErrInvalidLocale:   mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Locale should be BCP 47 language tag, e.g. en-US"},
+			locale.ErrInvalidCurrency: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Currency should be ISO 4217 code, e.g. USD"},
+			locale.ErrInvalidTimeZone: mapper.ErrorInfo{StatusCode: http.StatusBadRequest, Msg: "Time zone should be IANA time zone name, e.g. Europe/Berlin"},
+		},
+	)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+	}
+
+	return router
+}
+
+func (u *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.GET("/settings", u.settings)
+	r.PUT("/settings", u.updateSettings)
+	r.GET("/seller", u.seller)
+	r.PUT("/seller", u.updateSeller)
+	return r
+}
@@ -0,0 +1,255 @@
+package warehouse
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/validator"
+	"github.com/AnisaForWork/user_orders/internal/handler/middleware"
+	"github.com/AnisaForWork/user_orders/internal/handler/response"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Created used to parse request body with new warehouse, warehouses with lower priority are used first
+type Created struct {
+	Code     string `json:"code" binding:"required" default:"MAIN"`
+	Name     string `json:"name" binding:"required,max=60" default:"Main warehouse"`
+	Address  string `json:"address" binding:"max=200" default:""`
+	Priority int    `json:"priority" binding:"min=-1000000,max=1000000" default:"0"`
+}
+
+// Warehouse model used to parse warehouse into JSON response
+type Warehouse struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Address  string    `json:"address,omitempty"`
+	Priority int       `json:"priority"`
+	Created  time.Time `json:"created"`
+}
+
+// Level model used to parse stock of product in warehouse into JSON response, reserved is part of stock
+// held for placed orders and available is the rest
+type Level struct {
+	Barcode   string     `json:"barcode"`
+	Name      string     `json:"name"`
+	Stock     int64      `json:"stock"`
+	Reserved  int64      `json:"reserved"`
+	Available int64      `json:"available"`
+	Updated   *time.Time `json:"updated,omitempty"`
+}
+
+func newWarehouse(w *stock.Warehouse) Warehouse {
+	return Warehouse{
+		Code:     w.Code,
+		Name:     w.Name,
+		Address:  w.Address,
+		Priority: w.Priority,
+		Created:  w.Created,
+	}
+}
+
+// @Summary      Create warehouse
+// @Description  creates storage location of user, codes are case insensitive and can't be reused after warehouse is archived; warehouse with lowest priority is used for movements without warehouse
+// @Tags         warehouse
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        warehouse  body      warehouse.Created true "warehouse"
+// @Success      201  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /warehouses/ [post]
+func (wh *Router) create(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	var req Created
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ProcessValidatorError(err))
+		return
+	}
+
+	if !wh.codeRegex.MatchString(req.Code) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("code", "should consist of 2-20 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	w, err := wh.service.CreateWarehouse(c.Request.Context(), stock.Warehouse{
+		Code:     req.Code,
+		Name:     req.Name,
+		Address:  req.Address,
+		Priority: req.Priority,
+	}, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "warehouse",
+			"func":      "create",
+			"userLogin": login,
+			"code":      req.Code,
+		}).WithError(err).Error("Error creating warehouse")
+
+		errInf := wh.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreateJSONResult("Warehouse", newWarehouse(w)))
+}
+
+// @Summary      Returns user warehouses
+// @Description  returns active warehouses of user ordered by priority
+// @Tags         warehouse
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /warehouses/all [get]
+func (wh *Router) userWarehouses(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	warehouses, err := wh.service.UserWarehouses(c.Request.Context(), login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "warehouse",
+			"func":      "userWarehouses",
+			"userLogin": login,
+		}).WithError(err).Error("Error retrieving user warehouses")
+
+		errInf := wh.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Warehouse, len(warehouses))
+	for i := range warehouses {
+		res[i] = newWarehouse(&warehouses[i])
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Warehouses", res))
+}
+
+// @Summary      Archive warehouse
+// @Description  warehouse isn't used for new movements, transfers and orders anymore, its movements stay in stock history; only warehouse without stock and reservations can be archived
+// @Tags         warehouse
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 code path      string true  "Warehouse code"
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      409  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /warehouses/{code} [delete]
+func (wh *Router) archive(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	code := c.Param("code")
+	if !wh.codeRegex.MatchString(code) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("code", "should consist of 2-20 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	err := wh.service.ArchiveWarehouse(c.Request.Context(), code, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":   "warehouse",
+			"func":      "archive",
+			"userLogin": login,
+			"code":      code,
+		}).WithError(err).Error("Error archiving warehouse")
+
+		errInf := wh.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Succesfull", "Warehouse archived"))
+}
+
+// @Summary      Returns stock of warehouse with pagination
+// @Description  returns current stock of user products in warehouse in order products were created
+// @Tags         warehouse
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param 		 code path      string true  "Warehouse code"
+// @Param   	 p query     int    true "Next page to retrieve" minimum(1)    maximum(50000)
+// @Param   	 n query     int    true "Number of products per page" minimum(1)    maximum(100)
+// @Success      200  {object}  response.JSONResult
+// @Failure      400  {object}  response.JSONResult
+// @Failure      404  {object}  response.JSONResult
+// @Failure      500  {object}  response.JSONResult
+// @Router       /warehouses/{code}/stock [get]
+func (wh *Router) warehouseStock(c *gin.Context) {
+	log := logrus.WithContext(c.Request.Context())
+
+	login := c.GetString(middleware.KeyUserID)
+
+	code := c.Param("code")
+	if !wh.codeRegex.MatchString(code) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("code", "should consist of 2-20 latin letters, numbers, '_' or '-'"))
+		return
+	}
+
+	page, err := strconv.Atoi(c.Query("p"))
+	if err != nil || (page < 1 || page > 50000) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("p", "should be between 1 and 50000"))
+		return
+	}
+
+	productsPerPage, err := strconv.Atoi(c.Query("n"))
+	if err != nil || (productsPerPage < 1 || productsPerPage > 100) {
+		c.JSON(http.StatusBadRequest, validator.ErrorMsg("n", "should be between 1 and 100"))
+		return
+	}
+
+	levels, err := wh.service.WarehouseStock(c.Request.Context(), code, page, productsPerPage, login)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"handler":    "warehouse",
+			"func":       "warehouseStock",
+			"userLogin":  login,
+			"code":       code,
+			"page":       page,
+			"numPerPage": productsPerPage,
+		}).WithError(err).Error("Error retrieving warehouse stock")
+
+		errInf := wh.errMapper.MapError(err)
+		c.JSON(errInf.StatusCode,
+			response.CreateJSONResult("Error", errInf.Msg))
+
+		return
+	}
+
+	res := make([]Level, len(levels))
+	for i, l := range levels {
+		res[i] = Level{
+			Barcode:   l.Barcode,
+			Name:      l.Name,
+			Stock:     l.Stock,
+			Reserved:  l.Reserved,
+			Available: l.Available,
+			Updated:   l.Updated,
+		}
+	}
+
+	c.JSON(http.StatusOK, response.CreateJSONResult("Stock", res))
+}
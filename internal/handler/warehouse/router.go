@@ -0,0 +1,56 @@
+package warehouse
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/AnisaForWork/user_orders/internal/handler/error/mapper"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Service used to call warehouse related service level logic
+type Service interface {
+	CreateWarehouse(ctx context.Context, w stock.Warehouse, login string) (*stock.Warehouse, error)
+	UserWarehouses(ctx context.Context, login string) ([]stock.Warehouse, error)
+	ArchiveWarehouse(ctx context.Context, code string, login string) error
+	WarehouseStock(ctx context.Context, code string, page, productsPerPage int, login string) ([]stock.Level, error)
+}
+
+type Router struct {
+	service   Service
+	errMapper mapper.ErrorMapper
+	codeRegex *regexp.Regexp
+}
+
+func NewRouter(service Service) *Router {
+	mapping := mapper.NewErrorMapper(
+		mapper.ErrorMap{
+			mysql.ErrNoRows:            mapper.ErrorInfo{StatusCode: http.StatusNotFound, Msg: "Can't find"},
+			stock.ErrWarehouseExists:   mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Warehouse with this code already exists"},
+			stock.ErrWarehouseNotEmpty: mapper.ErrorInfo{StatusCode: http.StatusConflict, Msg: "Warehouse still has stock or reservations, transfer or adjust it first"},
+		},
+	)
+
+	codeRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]{2,20}$`)
+
+	router := &Router{
+		service:   service,
+		errMapper: mapping,
+		codeRegex: codeRegex,
+	}
+
+	return router
+}
+
+func (wh *Router) InitRoutes() *gin.Engine {
+	r := gin.New()
+	r.POST("/", wh.create)
+	r.GET("/all", wh.userWarehouses)
+	r.DELETE("/:code", wh.archive)
+	r.GET("/:code/stock", wh.warehouseStock)
+	return r
+}
@@ -0,0 +1,285 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+var (
+	ErrInvalidCurrency  = errors.New("currency is not valid ISO 4217 code")
+	ErrInvalidAmount    = errors.New("amount is not valid decimal number")
+	ErrPrecision        = errors.New("amount has more fraction digits than currency allows")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrRounding         = errors.New("unknown rounding mode")
+	ErrInvalidRate      = errors.New("exchange rate is not positive decimal number")
+)
+
+// Money is amount in minor units(cents for USD, yen for JPY) of ISO 4217 currency
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// ParseCurrency validates ISO 4217 code and returns it in upper case
+func ParseCurrency(code string) (string, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", ErrInvalidCurrency
+	}
+	return unit.String(), nil
+}
+
+// Scale returns number of fraction digits of currency, 2 for unknown ones
+func Scale(cur string) int {
+	unit, err := currency.ParseISO(cur)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
+}
+
+// New returns money with amount in minor units of currency
+func New(amount int64, cur string) Money {
+	return Money{Amount: amount, Currency: cur}
+}
+
+// Major returns money with amount given in major units(dollars, euros) of currency
+func Major(units int64, cur string) Money {
+	amount := units
+	for i := 0; i < Scale(cur); i++ {
+		amount *= 10
+	}
+	return Money{Amount: amount, Currency: cur}
+}
+
+// Parse reads decimal amount("9.99", "-0.5", "10") of currency without rounding,
+// amount with more fraction digits than currency has is rejected
+func Parse(amount string, cur string) (Money, error) {
+	cur, err := ParseCurrency(cur)
+	if err != nil {
+		return Money{}, err
+	}
+
+	s := strings.TrimSpace(amount)
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if (whole == "" && frac == "") || (hasPoint && frac == "") || !digits(whole) || !digits(frac) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	scale := Scale(cur)
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > scale {
+		return Money{}, ErrPrecision
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	if whole == "" {
+		whole = "0"
+	}
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if neg {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: cur}, nil
+}
+
+// Decimal returns amount as decimal string with all fraction digits of currency, e.g. "9.90"
+func (m Money) Decimal() string {
+	scale := Scale(m.Currency)
+
+	abs := m.Amount
+	sign := ""
+	if abs < 0 {
+		abs = -abs
+		sign = "-"
+	}
+
+	s := strconv.FormatInt(abs, 10)
+	if scale == 0 {
+		return sign + s
+	}
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// String returns decimal amount with currency code, e.g. "9.99 EUR"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// jsonMoney is JSON form of money, amount is decimal string so it isn't rounded by float parsers
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes money as {"amount": "9.99", "currency": "EUR"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes money encoded by MarshalJSON, amount is checked against currency precision
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var j jsonMoney
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	res, err := Parse(j.Amount, j.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = res
+	return nil
+}
+
+// Float returns amount in major units, used only for formatting
+func (m Money) Float() float64 {
+	f, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return f
+}
+
+// Mul returns amount multiplied by n
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Rounding is the way converted amount is rounded to minor units
+type Rounding string
+
+// Rounding modes
+const (
+	RoundHalfUp   Rounding = "half_up"
+	RoundHalfEven Rounding = "half_even"
+	RoundDown     Rounding = "down" // towards zero
+	RoundUp       Rounding = "up"   // away from zero
+)
+
+// ParseRounding validates rounding mode, empty mode means half up
+func ParseRounding(mode string) (Rounding, error) {
+	switch r := Rounding(mode); r {
+	case "":
+		return RoundHalfUp, nil
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+		return r, nil
+	}
+	return "", ErrRounding
+}
+
+// Convert returns amount in currency to, rate is price of one major unit of m currency in major units of to
+func (m Money) Convert(rate *big.Rat, to string, mode Rounding) Money {
+	v := new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(Scale(m.Currency)))
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetInt(pow10(Scale(to))))
+
+	return Money{Amount: round(v, mode), Currency: to}
+}
+
+// RateScale is number of fraction digits exchange rates are kept with
+const RateScale = 12
+
+// ParseRate reads positive decimal exchange rate("1.0842"), rate with more than RateScale fraction digits is rejected
+func ParseRate(rate string) (*big.Rat, error) {
+	s := strings.TrimSpace(rate)
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || (hasPoint && frac == "") || !digits(whole) || !digits(frac) ||
+		len(strings.TrimRight(frac, "0")) > RateScale || len(strings.TrimLeft(whole, "0")) > 24-RateScale {
+		return nil, ErrInvalidRate
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return r, nil
+}
+
+// FormatRate returns rate as decimal string without trailing zeros, rate is rounded half up to RateScale digits
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(RateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// InverseRate returns rate of reverse pair rounded half up to RateScale digits, so it can be stored as it is used
+func InverseRate(rate *big.Rat) *big.Rat {
+	r, _ := new(big.Rat).SetString(FormatRate(new(big.Rat).Inv(rate)))
+	return r
+}
+
+// round returns v rounded to integer with given mode
+func round(v *big.Rat, mode Rounding) int64 {
+	q, r := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if r.Sign() == 0 {
+		return q.Int64()
+	}
+
+	// remainder has sign of v, compare doubled absolute remainder with denominator to find half
+	away := false
+	half := new(big.Int).Abs(r)
+	half.Mul(half, big.NewInt(2))
+	cmp := half.Cmp(v.Denom())
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		away = true
+	case RoundHalfEven:
+		away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+	default:
+		away = cmp >= 0
+	}
+
+	if away {
+		if v.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q.Int64()
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Conversion records amount before conversion to other currency and rate it was converted with
+type Conversion struct {
+	Original Money
+	Rate     *big.Rat
+}
+
+// MarshalJSON encodes conversion as {"original": {"amount": "9.99", "currency": "EUR"}, "rate": "1.0842"}
+func (c Conversion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Original Money  `json:"original"`
+		Rate     string `json:"rate"`
+	}{c.Original, FormatRate(c.Rate)})
+}
@@ -0,0 +1,200 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		amount string
+		cur    string
+		want   Money
+		err    error
+	}{
+		{"9.99", "usd", Money{999, "USD"}, nil},
+		{"10", "EUR", Money{1000, "EUR"}, nil},
+		{".5", "EUR", Money{50, "EUR"}, nil},
+		{"+1.50", "EUR", Money{150, "EUR"}, nil},
+		{"-0.5", "EUR", Money{-50, "EUR"}, nil},
+		{"-12.30", "EUR", Money{-1230, "EUR"}, nil},
+		{"1.2300", "EUR", Money{123, "EUR"}, nil},
+		{"1.234", "EUR", Money{}, ErrPrecision},
+		{"1.001", "USD", Money{}, ErrPrecision},
+		{"500", "JPY", Money{500, "JPY"}, nil},
+		{"500.0", "JPY", Money{500, "JPY"}, nil},
+		{"500.5", "JPY", Money{}, ErrPrecision},
+		{"-7", "KRW", Money{-7, "KRW"}, nil},
+		{"1.234", "BHD", Money{1234, "BHD"}, nil},
+		{"1.2345", "BHD", Money{}, ErrPrecision},
+		{"1.2345", "CLF", Money{12345, "CLF"}, nil},
+		{"1.23456", "CLF", Money{}, ErrPrecision},
+		{"15", "UYI", Money{15, "UYI"}, nil},
+		{"15.5", "UYI", Money{}, ErrPrecision},
+		{"", "EUR", Money{}, ErrInvalidAmount},
+		{"1.", "EUR", Money{}, ErrInvalidAmount},
+		{"-", "EUR", Money{}, ErrInvalidAmount},
+		{"1,5", "EUR", Money{}, ErrInvalidAmount},
+		{"1e3", "EUR", Money{}, ErrInvalidAmount},
+		{"99999999999999999999", "EUR", Money{}, ErrInvalidAmount},
+		{"1", "EURO", Money{}, ErrInvalidCurrency},
+	}
+
+	for _, c := range cases {
+		got, err := Parse(c.amount, c.cur)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("Parse(%q, %q) = %+v, %v; want %+v, %v", c.amount, c.cur, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	cases := []struct {
+		m    Money
+		want string
+	}{
+		{Money{999, "USD"}, "9.99"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{-5, "USD"}, "-0.05"},
+		{Money{-1230, "EUR"}, "-12.30"},
+		{Money{0, "EUR"}, "0.00"},
+		{Money{500, "JPY"}, "500"},
+		{Money{-7, "KRW"}, "-7"},
+		{Money{1, "BHD"}, "0.001"},
+		{Money{12345, "CLF"}, "1.2345"},
+		{Money{15, "UYI"}, "15"},
+	}
+
+	for _, c := range cases {
+		if got := c.m.Decimal(); got != c.want {
+			t.Errorf("%+v.Decimal() = %q, want %q", c.m, got, c.want)
+		}
+
+		back, err := Parse(c.want, c.m.Currency)
+		if err != nil || back != c.m {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v", c.want, back, err, c.m)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	cases := map[string]int{"USD": 2, "EUR": 2, "JPY": 0, "KRW": 0, "BHD": 3, "CLF": 4, "UYI": 0, "???": 2}
+
+	for cur, want := range cases {
+		if got := Scale(cur); got != want {
+			t.Errorf("Scale(%s) = %d, want %d", cur, got, want)
+		}
+	}
+}
+
+func TestMajor(t *testing.T) {
+	cases := []struct {
+		units int64
+		cur   string
+		want  int64
+	}{
+		{3, "USD", 300},
+		{3, "JPY", 3},
+		{3, "BHD", 3000},
+		{-3, "CLF", -30000},
+	}
+
+	for _, c := range cases {
+		if got := Major(c.units, c.cur); got.Amount != c.want {
+			t.Errorf("Major(%d, %s) = %d, want %d", c.units, c.cur, got.Amount, c.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		num, den int64
+		want     map[Rounding]int64
+	}{
+		{5, 2, map[Rounding]int64{RoundHalfUp: 3, RoundHalfEven: 2, RoundDown: 2, RoundUp: 3}},
+		{7, 2, map[Rounding]int64{RoundHalfUp: 4, RoundHalfEven: 4, RoundDown: 3, RoundUp: 4}},
+		{-5, 2, map[Rounding]int64{RoundHalfUp: -3, RoundHalfEven: -2, RoundDown: -2, RoundUp: -3}},
+		{-7, 2, map[Rounding]int64{RoundHalfUp: -4, RoundHalfEven: -4, RoundDown: -3, RoundUp: -4}},
+		{21, 10, map[Rounding]int64{RoundHalfUp: 2, RoundHalfEven: 2, RoundDown: 2, RoundUp: 3}},
+		{29, 10, map[Rounding]int64{RoundHalfUp: 3, RoundHalfEven: 3, RoundDown: 2, RoundUp: 3}},
+		{-21, 10, map[Rounding]int64{RoundHalfUp: -2, RoundHalfEven: -2, RoundDown: -2, RoundUp: -3}},
+		{4, 1, map[Rounding]int64{RoundHalfUp: 4, RoundHalfEven: 4, RoundDown: 4, RoundUp: 4}},
+		{0, 1, map[Rounding]int64{RoundHalfUp: 0, RoundHalfEven: 0, RoundDown: 0, RoundUp: 0}},
+	}
+
+	for _, c := range cases {
+		for mode, want := range c.want {
+			if got := round(big.NewRat(c.num, c.den), mode); got != want {
+				t.Errorf("round(%d/%d, %s) = %d, want %d", c.num, c.den, mode, got, want)
+			}
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rate := big.NewRat(1005, 1000) // 1.005
+
+	cases := []struct {
+		m    Money
+		to   string
+		mode Rounding
+		want int64
+	}{
+		// 1.50 EUR is 1.5075 USD
+		{Money{150, "EUR"}, "USD", RoundHalfUp, 151},
+		{Money{150, "EUR"}, "USD", RoundHalfEven, 151},
+		{Money{150, "EUR"}, "USD", RoundDown, 150},
+		{Money{150, "EUR"}, "USD", RoundUp, 151},
+		// 1.00 EUR is 1.005 USD, exactly half a cent
+		{Money{100, "EUR"}, "USD", RoundHalfUp, 101},
+		{Money{100, "EUR"}, "USD", RoundHalfEven, 100},
+		{Money{-100, "EUR"}, "USD", RoundHalfUp, -101},
+		{Money{-100, "EUR"}, "USD", RoundHalfEven, -100},
+		{Money{-150, "EUR"}, "USD", RoundDown, -150},
+		{Money{-150, "EUR"}, "USD", RoundUp, -151},
+		// to and from currencies without fraction digits
+		{Money{150, "EUR"}, "JPY", RoundHalfUp, 2},
+		{Money{150, "EUR"}, "JPY", RoundDown, 1},
+		{Money{500, "JPY"}, "EUR", RoundHalfUp, 50250},
+		{Money{150, "EUR"}, "CLF", RoundHalfUp, 15075},
+		{Money{150, "EUR"}, "UYI", RoundHalfEven, 2},
+	}
+
+	for _, c := range cases {
+		got := c.m.Convert(rate, c.to, c.mode)
+		if got.Amount != c.want || got.Currency != c.to {
+			t.Errorf("%s converted to %s with %s = %+v, want %d", c.m, c.to, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	for _, mode := range []string{"half_up", "half_even", "down", "up"} {
+		if got, err := ParseRounding(mode); err != nil || string(got) != mode {
+			t.Errorf("ParseRounding(%q) = %q, %v", mode, got, err)
+		}
+	}
+	if got, err := ParseRounding(""); err != nil || got != RoundHalfUp {
+		t.Errorf("ParseRounding(\"\") = %q, %v; want half_up", got, err)
+	}
+	if _, err := ParseRounding("ceiling"); !errors.Is(err, ErrRounding) {
+		t.Errorf("ParseRounding(\"ceiling\") returned %v, want ErrRounding", err)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	valid := map[string]string{"1.0842": "1.0842", "0.000000000001": "0.000000000001", "150": "150", "1.50": "1.5"}
+	for rate, want := range valid {
+		r, err := ParseRate(rate)
+		if err != nil || FormatRate(r) != want {
+			t.Errorf("ParseRate(%q) = %v, %v; want %s", rate, r, err, want)
+		}
+	}
+
+	for _, rate := range []string{"", "0", "0.0", "-1", "1.", ".5", "1.0000000000001", "abc", "1000000000000"} {
+		if _, err := ParseRate(rate); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("ParseRate(%q) returned %v, want ErrInvalidRate", rate, err)
+		}
+	}
+}
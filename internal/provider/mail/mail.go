@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported mailers
+const (
+	TypeSMTP   = "smtp"
+	TypeOutbox = "outbox"
+	TypeNone   = "none"
+)
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Attachment is file attached to message
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message is plain text email with attachments
+type Message struct {
+	From        string
+	To          string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// ValidAddress reports whether address is single email address without display name
+func ValidAddress(address string) bool {
+	a, err := mail.ParseAddress(address)
+	return err == nil && a.Name == "" && a.Address == address
+}
+
+// Bytes returns message in MIME format ready to be sent
+func (m *Message) Bytes() ([]byte, error) {
+	if !ValidAddress(m.From) || !ValidAddress(m.To) {
+		return nil, ErrInvalidAddress
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", uuid.NewString(), domain(m.From))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64(text, []byte(m.Text)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data encoded in base64 with lines of 76 chars as required by RFC 2045
+func writeBase64(w interface{ Write([]byte) (int, error) }, data []byte) error {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", enc[:76]); err != nil {
+			return err
+		}
+		enc = enc[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", enc)
+	return err
+}
+
+func domain(address string) string {
+	for i := len(address) - 1; i >= 0; i-- {
+		if address[i] == '@' {
+			return address[i+1:]
+		}
+	}
+	return "localhost"
+}
+
+// IsTransient reports whether sending can succeed later: network errors and 4xx SMTP replies
+func IsTransient(err error) bool {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) {
+		return tpErr.Code >= 400 && tpErr.Code < 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,123 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "service not available", err: &textproto.Error{Code: 421, Msg: "try again later"}, want: true},
+		{name: "mailbox busy", err: &textproto.Error{Code: 450, Msg: "mailbox busy"}, want: true},
+		{name: "wrapped greylisting", err: fmt.Errorf("rcpt: %w", &textproto.Error{Code: 451, Msg: "greylisted"}), want: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: true},
+		{name: "dns timeout", err: &net.DNSError{Err: "timeout", IsTimeout: true}, want: true},
+		{name: "no such user", err: &textproto.Error{Code: 550, Msg: "no such user"}},
+		{name: "rejected", err: &textproto.Error{Code: 554, Msg: "rejected"}},
+		{name: "invalid address", err: ErrInvalidAddress},
+		{name: "other error", err: errors.New("check not found")},
+	}
+
+	for _, c := range cases {
+		if got := IsTransient(c.err); got != c.want {
+			t.Errorf("%s: IsTransient(%v) = %v, want %v", c.name, c.err, got, c.want)
+		}
+	}
+}
+
+func TestValidAddress(t *testing.T) {
+	cases := map[string]bool{
+		"buyer@example.com":           true,
+		"first.last+tag@example.org":  true,
+		"":                            false,
+		"buyer":                       false,
+		"Buyer <buyer@example.com>":   false,
+		"buyer@example.com, x@y.com":  false,
+		"buyer@example.com\r\nBcc: x": false,
+	}
+
+	for addr, want := range cases {
+		if got := ValidAddress(addr); got != want {
+			t.Errorf("ValidAddress(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestOutboxMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewOutboxMailer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &Message{
+		From:        "checks@shop.com",
+		To:          "buyer@example.com",
+		Subject:     "Receipt for Stabilo pen",
+		Text:        "Your receipt is attached.",
+		Attachments: []Attachment{{Name: "check.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}},
+	}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := *msg
+	bad.To = "Buyer <buyer@example.com>"
+	if err := m.Send(context.Background(), &bad); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("Send to invalid address returned %v, want ErrInvalidAddress", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil || len(files) != 1 || !strings.HasSuffix(files[0], ".eml") {
+		t.Fatalf("outbox has files %v, want one message", files)
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	saved, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Header.Get("To") != msg.To || saved.Header.Get("Subject") != msg.Subject {
+		t.Fatalf("saved message is to %q about %q", saved.Header.Get("To"), saved.Header.Get("Subject"))
+	}
+
+	_, params, err := mime.ParseMediaType(saved.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(saved.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, p.Header.Get("Content-Type")+" "+p.FileName())
+	}
+	if strings.Join(parts, ", ") != "text/plain; charset=utf-8 , application/pdf check.pdf" {
+		t.Fatalf("saved message has parts %q", parts)
+	}
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMailer saves messages as .eml files in local directory instead of sending them,
+// used in development and tests
+type OutboxMailer struct {
+	dir string
+}
+
+// NewOutboxMailer returns mailer saving messages in dir, dir is created if it doesn't exist
+func NewOutboxMailer(dir string) (*OutboxMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &OutboxMailer{dir: dir}, nil
+}
+
+// Send saves message in outbox directory, file appears only when it's completely written
+func (m *OutboxMailer) Send(_ context.Context, msg *Message) (err error) {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+
+	tmp, err := os.CreateTemp(m.dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(m.dir, name))
+}
@@ -0,0 +1,355 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ReorderRule is db layer model of reorder threshold of product with its current stock, rule is alerted
+// after low stock alert was raised and until available stock is back at threshold
+type ReorderRule struct {
+	ID        int64     `db:"id" json:"id"`
+	Barcode   string    `db:"barcode" json:"barcode"`
+	Name      string    `db:"name" json:"name"`
+	Threshold int64     `db:"threshold" json:"threshold"`
+	Alerted   bool      `db:"alerted" json:"alerted"`
+	Stock     int64     `db:"stock" json:"stock"`
+	Reserved  int64     `db:"reserved" json:"reserved"`
+	Updated   time.Time `db:"updated" json:"updated"`
+}
+
+// ReorderCandidate is reorder rule evaluated by alerts with owner of product and its alert settings
+type ReorderCandidate struct {
+	ReorderRule
+	UserID int64  `db:"userId" json:"userId"`
+	Login  string `db:"login" json:"login"`
+	Email  string `db:"email" json:"email"`
+	AlertSettings
+}
+
+// StockAlert is db layer model of low stock alert shown in notification list of user
+type StockAlert struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"userId" json:"userId"`
+	Barcode   string    `db:"barcode" json:"barcode"`
+	Name      string    `db:"name" json:"name"`
+	Available int64     `db:"available" json:"available"`
+	Threshold int64     `db:"threshold" json:"threshold"`
+	Read      bool      `db:"isRead" json:"read"`
+	Created   time.Time `db:"created" json:"created"`
+}
+
+// AlertSettings is db layer model of channels low stock alerts of user are sent to besides notification list,
+// webhook requests are signed with WebhookSecret
+type AlertSettings struct {
+	Email         bool   `db:"alertEmail" json:"email"`
+	Webhook       string `db:"alertWebhook" json:"webhook"`
+	WebhookSecret string `db:"alertWebhookSecret" json:"-"`
+}
+
+// ReorderLine is stock of product with quantity sold since start of sales window, threshold is invalid
+// if product has no reorder rule
+type ReorderLine struct {
+	Barcode   string        `db:"barcode" json:"barcode"`
+	Name      string        `db:"name" json:"name"`
+	Threshold sql.NullInt64 `db:"threshold" json:"threshold"`
+	Stock     int64         `db:"stock" json:"stock"`
+	Reserved  int64         `db:"reserved" json:"reserved"`
+	Sold      int64         `db:"sold" json:"sold"`
+}
+
+const reorderRuleColumns = `reorder_rules.id, reorder_rules.barcode, products.name, reorder_rules.threshold,
+					reorder_rules.alerted, reorder_rules.updated,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_movements
+						WHERE stock_movements.barcode=reorder_rules.barcode) AS stock,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.barcode=reorder_rules.barcode AND stock_reservations.status='active') AS reserved`
+
+// SetReorderRule sets reorder threshold of user product and clears its alerted state so product is evaluated
+// against new threshold, returns ErrNoRows if user doesn't own product
+func (r *Repository) SetReorderRule(ctx context.Context, barcode string, threshold int64, login string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	lockQuery := `SELECT products.barcode FROM products
+					JOIN users ON users.id=products.userId AND users.login=?
+					WHERE products.barcode=? AND products.deleted=FALSE
+					FOR UPDATE`
+	query := `INSERT INTO reorder_rules (barcode, threshold) values (?,?)
+					ON DUPLICATE KEY UPDATE threshold=VALUES(threshold), alerted=FALSE`
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var code string
+	if err = tx.QueryRowContext(ctx, lockQuery, login, barcode).Scan(&code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRows
+		}
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, query, code, threshold); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteReorderRule removes reorder threshold of user product, returns ErrNoRows if product has no rule
+func (r *Repository) DeleteReorderRule(ctx context.Context, barcode string, login string) error {
+	query := `DELETE reorder_rules FROM reorder_rules
+				JOIN products ON products.barcode=reorder_rules.barcode
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE reorder_rules.barcode=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, login, barcode)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// ReorderRules returns reorder rules of user products in order products were created
+func (r *Repository) ReorderRules(ctx context.Context, login string) ([]ReorderRule, error) {
+	query := `SELECT ` + reorderRuleColumns + ` FROM reorder_rules
+				JOIN products ON products.barcode=reorder_rules.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				ORDER BY products.created, products.barcode`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rules := []ReorderRule{}
+
+	err := r.db.SelectContext(ctx, &rules, query, login)
+
+	return rules, err
+}
+
+// ReorderCandidates returns up to limit reorder rules of all users with id greater than afterID ordered by id
+func (r *Repository) ReorderCandidates(ctx context.Context, afterID int64, limit int) ([]ReorderCandidate, error) {
+	query := `SELECT ` + reorderRuleColumns + `, users.id AS userId, users.login, users.email,
+					users.alertEmail, users.alertWebhook, users.alertWebhookSecret
+				FROM reorder_rules
+				JOIN products ON products.barcode=reorder_rules.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId
+				WHERE reorder_rules.id>?
+				ORDER BY reorder_rules.id
+				LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	rules := []ReorderCandidate{}
+
+	err := r.db.SelectContext(ctx, &rules, query, afterID, limit)
+
+	return rules, err
+}
+
+// RaiseStockAlert marks reorder rule with given id alerted and adds alert to notification list of its owner,
+// returns ErrNoRows if rule was already alerted so every drop below threshold raises single alert
+func (r *Repository) RaiseStockAlert(ctx context.Context, ruleID int64, a StockAlert) (id int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	markQuery := "UPDATE reorder_rules SET alerted=TRUE WHERE id=? AND alerted=FALSE"
+	insertQuery := "INSERT INTO stock_alerts (userId, barcode, available, threshold) values (?,?,?,?)"
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, markQuery, ruleID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowC == 0 {
+		return 0, ErrNoRows
+	}
+
+	res, err = tx.ExecContext(ctx, insertQuery, a.UserID, a.Barcode, a.Available, a.Threshold)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+
+	return id, err
+}
+
+// ClearStockAlert clears alerted state of reorder rule with given id, next drop below threshold raises new alert
+func (r *Repository) ClearStockAlert(ctx context.Context, ruleID int64) error {
+	query := "UPDATE reorder_rules SET alerted=FALSE WHERE id=? AND alerted=TRUE"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, ruleID)
+
+	return err
+}
+
+// StockAlerts returns low stock alerts of user, newest first, only unread ones if unread is set
+func (r *Repository) StockAlerts(ctx context.Context, unread bool, amount int, offset int, login string) ([]StockAlert, error) {
+	query := `SELECT stock_alerts.id, stock_alerts.userId, stock_alerts.barcode, products.name, stock_alerts.available,
+					stock_alerts.threshold, stock_alerts.isRead, stock_alerts.created
+				FROM stock_alerts
+				JOIN users ON users.id=stock_alerts.userId AND users.login=?
+				JOIN products ON products.barcode=stock_alerts.barcode
+				WHERE (?=FALSE OR stock_alerts.isRead=FALSE)
+				ORDER BY stock_alerts.id DESC
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	alerts := []StockAlert{}
+
+	err := r.db.SelectContext(ctx, &alerts, query, login, unread, amount, offset)
+
+	return alerts, err
+}
+
+// ReadStockAlert marks low stock alert of user as read, returns ErrNoRows if user has no alert with given id
+func (r *Repository) ReadStockAlert(ctx context.Context, id int64, login string) error {
+	query := `UPDATE stock_alerts
+				JOIN users ON users.id=stock_alerts.userId AND users.login=?
+				SET stock_alerts.isRead=TRUE
+				WHERE stock_alerts.id=?`
+	existsQuery := `SELECT stock_alerts.id FROM stock_alerts
+				JOIN users ON users.id=stock_alerts.userId AND users.login=?
+				WHERE stock_alerts.id=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, login, id)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC > 0 {
+		return nil
+	}
+
+	// alert that was already read isn't changed by update
+	var found int64
+	if err := r.db.GetContext(ctx, &found, existsQuery, login, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRows
+		}
+		return err
+	}
+
+	return nil
+}
+
+// AlertSettings returns channels low stock alerts of user with given login are sent to
+func (r *Repository) AlertSettings(ctx context.Context, login string) (*AlertSettings, error) {
+	query := "SELECT alertEmail, alertWebhook, alertWebhookSecret FROM users WHERE login=? LIMIT 1"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var s AlertSettings
+
+	err := r.db.GetContext(ctx, &s, query, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// UpdateAlertSettings saves channels low stock alerts of user with given login are sent to
+func (r *Repository) UpdateAlertSettings(ctx context.Context, s AlertSettings, login string) error {
+	query := "UPDATE users SET alertEmail=?, alertWebhook=?, alertWebhookSecret=? WHERE login=?"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, s.Email, s.Webhook, s.WebhookSecret, login)
+
+	return err
+}
+
+// ReorderReport returns stock of user products that have reorder rule or movements of given kind since given time,
+// sold is quantity taken by those movements
+func (r *Repository) ReorderReport(ctx context.Context, kind string, since time.Time, login string) ([]ReorderLine, error) {
+	query := `SELECT products.barcode, products.name, reorder_rules.threshold,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_movements
+						WHERE stock_movements.barcode=products.barcode) AS stock,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.barcode=products.barcode AND stock_reservations.status='active') AS reserved,
+					(SELECT COALESCE(-SUM(quantity), 0) FROM stock_movements
+						WHERE stock_movements.barcode=products.barcode AND stock_movements.kind=?
+						AND stock_movements.created>=?) AS sold
+				FROM products
+				JOIN users ON users.id=products.userId AND users.login=?
+				LEFT JOIN reorder_rules ON reorder_rules.barcode=products.barcode
+				WHERE products.deleted=FALSE
+				HAVING threshold IS NOT NULL OR sold>0
+				ORDER BY products.created, products.barcode`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	lines := []ReorderLine{}
+
+	err := r.db.SelectContext(ctx, &lines, query, kind, since, login)
+
+	return lines, err
+}
@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ProductOwner is db layer model of product with login of its owner, used to explain why check can't be created
+type ProductOwner struct {
+	Barcode string `db:"barcode" json:"barcode"`
+	Login   string `db:"login" json:"login"`
+	Deleted bool   `db:"deleted" json:"deleted"`
+}
+
+// ProductFilter holds conditions products are chosen by, zero values are ignored,
+// costs are in minor units of currency and are compared only with products in that currency
+type ProductFilter struct {
+	NameContains string
+	Currency     string
+	MinCost      int64
+	MaxCost      int64
+}
+
+// ProductOwners returns owners of products with given barcodes regardless of who asks for them
+func (r *Repository) ProductOwners(ctx context.Context, barcodes []string) ([]ProductOwner, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	query, args, err := sqlx.In(`SELECT products.barcode, users.login, products.deleted FROM products
+					JOIN users ON users.id=products.userId
+					WHERE products.barcode IN (?)`, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []ProductOwner{}
+
+	err = r.db.SelectContext(ctx, &owners, r.db.Rebind(query), args...)
+
+	return owners, err
+}
+
+// FilteredProducts returns barcodes of user products matching filter, at most limit of them
+func (r *Repository) FilteredProducts(ctx context.Context, f ProductFilter, limit int, login string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	query := `SELECT barcode FROM products
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE deleted=FALSE
+					AND (?='' OR name LIKE ?)
+					AND (?='' OR currency=?)
+					AND (?=0 OR cost>=?)
+					AND (?=0 OR cost<=?)
+				ORDER BY products.created
+				LIMIT ?`
+
+	pattern := "%" + escapeLike(f.NameContains) + "%"
+
+	barcodes := []string{}
+
+	err := r.db.SelectContext(ctx, &barcodes, query, login,
+		f.NameContains, pattern, f.Currency, f.Currency, f.MinCost, f.MinCost, f.MaxCost, f.MaxCost, limit)
+
+	return barcodes, err
+}
+
+// escapeLike escapes wildcards of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
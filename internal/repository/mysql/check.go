@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const checkColumns = `prchecks.id, prchecks.filename, prchecks.barcode, prchecks.templateId, prchecks.templateVersion,
+					prchecks.created, prchecks.size, prchecks.sha256, prchecks.productName, prchecks.productCost,
+					prchecks.currency,
+					prchecks.receiptNumber, prchecks.sellerName, prchecks.sellerTaxId, prchecks.sellerAddress, prchecks.vatRate,
+					prchecks.netAmount, prchecks.taxAmount, prchecks.grossAmount,
+					prchecks.convCurrency, prchecks.convRate, prchecks.convCost, prchecks.archived`
+
+// ProductChecks returns checks generated for user product, newest first
+func (r *Repository) ProductChecks(ctx context.Context, barcode string, amount int, offset int, login string) ([]Check, error) {
+	query := `SELECT ` + checkColumns + ` FROM prchecks
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE prchecks.barcode=?
+				ORDER BY prchecks.created DESC, prchecks.id DESC
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	checks := []Check{}
+
+	err := r.db.SelectContext(ctx, &checks, query, login, barcode, amount, offset)
+
+	return checks, err
+}
+
+// UserCheck returns check if user owns checked product
+func (r *Repository) UserCheck(ctx context.Context, id int64, login string) (*Check, error) {
+	return r.userCheckBy(ctx, "id", id, login)
+}
+
+// UserCheckByHash returns check with given SHA-256 of file if user owns checked product
+func (r *Repository) UserCheckByHash(ctx context.Context, sha256 string, login string) (*Check, error) {
+	return r.userCheckBy(ctx, "sha256", sha256, login)
+}
+
+// UserCheckByName returns check with given file name if user owns checked product
+func (r *Repository) UserCheckByName(ctx context.Context, filename string, login string) (*Check, error) {
+	return r.userCheckBy(ctx, "filename", filename, login)
+}
+
+// userCheckBy returns check found by value of given column, column is never user input
+func (r *Repository) userCheckBy(ctx context.Context, column string, value interface{}, login string) (*Check, error) {
+	query := `SELECT ` + checkColumns + ` FROM prchecks
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE prchecks.` + column + `=?
+				ORDER BY prchecks.id DESC
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var ch Check
+
+	err := r.db.GetContext(ctx, &ch, query, login, value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &ch, nil
+}
+
+// DeleteCheck removes check record if user owns checked product and returns name of its stored file,
+// file is removed by caller after record is deleted so check is never listed without its file;
+// fiscal receipts(checks with receipt number) are never deleted, ErrReceiptIssued is returned for them
+func (r *Repository) DeleteCheck(ctx context.Context, id int64, login string) (filename string, err error) {
+	selector := `SELECT prchecks.filename, prchecks.receiptNumber FROM prchecks
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE prchecks.id=?
+				FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var receipt sql.NullInt64
+	err = tx.QueryRowContext(ctx, selector, login, id).Scan(&filename, &receipt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRows
+		}
+		return "", err
+	}
+
+	if receipt.Valid {
+		err = ErrReceiptIssued
+		return "", err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM prchecks WHERE id=?", id); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Coupon is db layer model of promotion code of user, value is basis points for percentage coupons
+// and amount for fixed ones, amounts are in minor units of currency which is set only for coupons with amounts
+type Coupon struct {
+	ID             int64          `db:"id" json:"id"`
+	Code           string         `db:"code" json:"code"`
+	Kind           string         `db:"kind" json:"kind"`
+	Value          int64          `db:"value" json:"value"`
+	Currency       sql.NullString `db:"currency" json:"currency"`
+	MinOrderValue  int64          `db:"minOrderValue" json:"minOrderValue"`
+	ValidFrom      sql.NullTime   `db:"validFrom" json:"validFrom"`
+	ValidTo        sql.NullTime   `db:"validTo" json:"validTo"`
+	MaxUses        sql.NullInt64  `db:"maxUses" json:"maxUses"`
+	MaxUsesPerUser sql.NullInt64  `db:"maxUsesPerUser" json:"maxUsesPerUser"`
+	Uses           int            `db:"uses" json:"uses"`
+	Disabled       bool           `db:"disabled" json:"disabled"`
+	Created        time.Time      `db:"created" json:"created"`
+}
+
+// CouponUsage is coupon with number of its uses by order customer
+type CouponUsage struct {
+	Coupon
+	UserUses int `db:"userUses" json:"userUses"`
+}
+
+// OrderDiscount is db layer model of coupon applied to order
+type OrderDiscount struct {
+	ID       int64     `db:"id" json:"id"`
+	CouponID int64     `db:"couponId" json:"couponId"`
+	Code     string    `db:"code" json:"code"`
+	Amount   int64     `db:"amount" json:"amount"`
+	Created  time.Time `db:"created" json:"created"`
+}
+
+const couponColumns = `coupons.id, coupons.code, coupons.kind, coupons.value, coupons.currency, coupons.minOrderValue,
+					coupons.validFrom, coupons.validTo, coupons.maxUses, coupons.maxUsesPerUser,
+					coupons.uses, coupons.disabled, coupons.created`
+
+// CreateCoupon adds coupon of user, returns ErrUniqConstrViolation if user already has coupon with the same code
+func (r *Repository) CreateCoupon(ctx context.Context, c Coupon, login string) (int64, error) {
+	query := `INSERT INTO coupons (userId, code, kind, value, currency, minOrderValue, validFrom, validTo, maxUses, maxUsesPerUser)
+				SELECT id, ?, ?, ?, ?, ?, ?, ?, ?, ? FROM users WHERE login=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, c.Code, c.Kind, c.Value, c.Currency, c.MinOrderValue,
+		c.ValidFrom, c.ValidTo, c.MaxUses, c.MaxUsesPerUser, login)
+	if err != nil {
+		errMsql, ok := err.(*mysql.MySQLError)
+		if ok && errMsql.Number == 1062 {
+			return 0, ErrUniqConstrViolation
+		}
+		return 0, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowC == 0 {
+		return 0, ErrNoRows
+	}
+
+	return res.LastInsertId()
+}
+
+// UserCoupons returns coupons of user, newest first
+func (r *Repository) UserCoupons(ctx context.Context, login string) ([]Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons
+				JOIN users ON users.id=coupons.userId AND users.login=?
+				ORDER BY coupons.id DESC
+				LIMIT 1000`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	coupons := []Coupon{}
+
+	err := r.db.SelectContext(ctx, &coupons, query, login)
+
+	return coupons, err
+}
+
+// DisableCoupon disables coupon of user, it can't be applied anymore but stays in order history
+func (r *Repository) DisableCoupon(ctx context.Context, code string, login string) error {
+	query := `UPDATE coupons
+				JOIN users ON users.id=coupons.userId AND users.login=?
+				SET coupons.disabled=TRUE
+				WHERE coupons.code=? AND coupons.disabled=FALSE`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, login, code)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// OrderDiscounts returns coupons applied to order in order they were applied
+func (r *Repository) OrderDiscounts(ctx context.Context, orderID int64) ([]OrderDiscount, error) {
+	query := `SELECT id, couponId, code, amount, created FROM order_discounts
+				WHERE orderId=?
+				ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	discounts := []OrderDiscount{}
+
+	err := r.db.SelectContext(ctx, &discounts, query, orderID)
+
+	return discounts, err
+}
@@ -0,0 +1,218 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Check delivery statuses
+const (
+	DeliveryQueued  = "queued"
+	DeliverySending = "sending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// CheckDelivery is db layer model of sending check by email
+type CheckDelivery struct {
+	ID        int64     `db:"id" json:"id"`
+	CheckID   int64     `db:"checkId" json:"checkId"`
+	Login     string    `db:"login" json:"login"`
+	FileName  string    `db:"filename" json:"filename"`
+	Recipient string    `db:"recipient" json:"recipient"`
+	Message   string    `db:"message" json:"message"`
+	Status    string    `db:"status" json:"status"`
+	Attempts  int       `db:"attempts" json:"attempts"`
+	Error     string    `db:"error" json:"error"`
+	NextRunAt time.Time `db:"nextRunAt" json:"nextRunAt"`
+	Created   time.Time `db:"created" json:"created"`
+	Updated   time.Time `db:"updated" json:"updated"`
+}
+
+const deliveryColumns = `check_deliveries.id, check_deliveries.checkId, users.login, prchecks.filename,
+					check_deliveries.recipient, check_deliveries.message, check_deliveries.status,
+					check_deliveries.attempts, check_deliveries.error, check_deliveries.nextRunAt,
+					check_deliveries.created, check_deliveries.updated`
+
+// CreateDelivery queues sending of check with given file name if user owns checked product, returns id of delivery
+func (r *Repository) CreateDelivery(ctx context.Context, d CheckDelivery, login string) (int64, error) {
+	query := `INSERT INTO check_deliveries (checkId, recipient, message)
+				SELECT prchecks.id, ?, ? FROM prchecks
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE prchecks.filename=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, d.Recipient, d.Message, login, d.FileName)
+	if err != nil {
+		return 0, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowC == 0 {
+		return 0, ErrNoRows
+	}
+
+	return res.LastInsertId()
+}
+
+// ClaimDelivery marks as sending and returns queued delivery which time to run has come, delivery is leased
+// to worker instance owner for given duration, returns ErrNoRows if there are no such deliveries
+func (r *Repository) ClaimDelivery(ctx context.Context, owner string, lease time.Duration) (d *CheckDelivery, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	selector := `SELECT ` + deliveryColumns + ` FROM check_deliveries
+				JOIN prchecks ON prchecks.id=check_deliveries.checkId
+				JOIN products ON products.barcode=prchecks.barcode
+				JOIN users ON users.id=products.userId
+				WHERE check_deliveries.status=? AND check_deliveries.nextRunAt<=NOW()
+				ORDER BY check_deliveries.nextRunAt
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED`
+	query := `UPDATE check_deliveries SET status=?, attempts=attempts+1, lockedBy=?, lockedUntil=NOW() + INTERVAL ? MICROSECOND
+				WHERE id=?`
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d = &CheckDelivery{}
+	err = tx.GetContext(ctx, d, selector, DeliveryQueued)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, query, DeliverySending, owner, lease.Microseconds(), d.ID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	d.Status = DeliverySending
+	d.Attempts++
+
+	return d, nil
+}
+
+// ExtendDeliveryLease prolongs lease of sending delivery held by worker instance owner,
+// returns ErrNoRows if delivery isn't sending or was leased by another instance
+func (r *Repository) ExtendDeliveryLease(ctx context.Context, id int64, owner string, lease time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE check_deliveries SET lockedUntil=NOW() + INTERVAL ? MICROSECOND
+				WHERE id=? AND status=? AND lockedBy=?`
+
+	res, err := r.db.ExecContext(ctx, query, lease.Microseconds(), id, DeliverySending, owner)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// FinishDelivery marks delivery leased by worker instance owner as sent
+func (r *Repository) FinishDelivery(ctx context.Context, id int64, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := "UPDATE check_deliveries SET status=?, error='', lockedUntil=NULL WHERE id=? AND lockedBy=?"
+
+	_, err := r.db.ExecContext(ctx, query, DeliverySent, id, owner)
+
+	return err
+}
+
+// RetryDelivery returns delivery leased by worker instance owner in queue, it will be claimed after given delay
+func (r *Repository) RetryDelivery(ctx context.Context, id int64, owner string, errMsg string, delay time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE check_deliveries SET status=?, error=?, nextRunAt=NOW() + INTERVAL ? MICROSECOND, lockedUntil=NULL
+				WHERE id=? AND lockedBy=?`
+
+	_, err := r.db.ExecContext(ctx, query, DeliveryQueued, truncate(errMsg, 255), delay.Microseconds(), id, owner)
+
+	return err
+}
+
+// FailDelivery marks delivery leased by worker instance owner as failed, it won't be sent again
+func (r *Repository) FailDelivery(ctx context.Context, id int64, owner string, errMsg string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := "UPDATE check_deliveries SET status=?, error=?, lockedUntil=NULL WHERE id=? AND lockedBy=?"
+
+	_, err := r.db.ExecContext(ctx, query, DeliveryFailed, truncate(errMsg, 255), id, owner)
+
+	return err
+}
+
+// RequeueExpiredDeliveries returns in queue sending deliveries which lease expired because their worker was stopped
+// or lost connection, deliveries of live workers keep being extended and aren't touched
+func (r *Repository) RequeueExpiredDeliveries(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE check_deliveries SET status=?, nextRunAt=NOW()
+				WHERE status=? AND (lockedUntil IS NULL OR lockedUntil<NOW())`
+
+	res, err := r.db.ExecContext(ctx, query, DeliveryQueued, DeliverySending)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// CheckDeliveries returns deliveries of check, newest first
+func (r *Repository) CheckDeliveries(ctx context.Context, checkID int64) ([]CheckDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM check_deliveries
+				JOIN prchecks ON prchecks.id=check_deliveries.checkId
+				JOIN products ON products.barcode=prchecks.barcode
+				JOIN users ON users.id=products.userId
+				WHERE check_deliveries.checkId=?
+				ORDER BY check_deliveries.id DESC
+				LIMIT 100`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	deliveries := []CheckDelivery{}
+
+	err := r.db.SelectContext(ctx, &deliveries, query, checkID)
+
+	return deliveries, err
+}
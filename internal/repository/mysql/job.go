@@ -0,0 +1,236 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Job statuses
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Job is db layer model of check generation job
+type Job struct {
+	ID        string         `db:"id" json:"id"`
+	Login     string         `db:"login" json:"login"`
+	Barcode   string         `db:"barcode" json:"barcode"`
+	Template  string         `db:"template" json:"template"`
+	Status    string         `db:"status" json:"status"`
+	Attempts  int            `db:"attempts" json:"attempts"`
+	FileName  sql.NullString `db:"filename" json:"filename"`
+	Error     string         `db:"error" json:"error"`
+	NextRunAt time.Time      `db:"nextRunAt" json:"nextRunAt"`
+	Created   time.Time      `db:"created" json:"created"`
+	Updated   time.Time      `db:"updated" json:"updated"`
+}
+
+// CreateJob inserts queued job for user with given login
+func (r *Repository) CreateJob(ctx context.Context, j Job, login string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `INSERT INTO jobs (id, userId, barcode, template)
+				SELECT ?, id, ?, ? FROM users WHERE login=?`
+
+	res, err := r.db.ExecContext(ctx, query, j.ID, j.Barcode, j.Template, login)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// QueuedJobs returns number of user jobs waiting for execution
+func (r *Repository) QueuedJobs(ctx context.Context, login string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM jobs
+				JOIN users ON users.id=jobs.userId
+				WHERE users.login=? AND jobs.status=?`
+
+	var n int
+	err := r.db.QueryRowContext(ctx, query, login, JobQueued).Scan(&n)
+
+	return n, err
+}
+
+// ClaimJob marks as running and returns queued job which time to run has come, job is leased
+// to worker instance owner for given duration, returns ErrNoRows if there are no such jobs
+func (r *Repository) ClaimJob(ctx context.Context, owner string, lease time.Duration) (j *Job, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	selector := `SELECT jobs.id, users.login, jobs.barcode, jobs.template, jobs.status, jobs.attempts,
+					jobs.filename, jobs.error, jobs.nextRunAt, jobs.created, jobs.updated
+				FROM jobs
+				JOIN users ON users.id=jobs.userId
+				WHERE jobs.status=? AND jobs.nextRunAt<=NOW()
+				ORDER BY jobs.nextRunAt
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED`
+	query := `UPDATE jobs SET status=?, attempts=attempts+1, lockedBy=?, lockedUntil=NOW() + INTERVAL ? MICROSECOND
+				WHERE id=?`
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	j = &Job{}
+	err = tx.GetContext(ctx, j, selector, JobQueued)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, query, JobRunning, owner, lease.Microseconds(), j.ID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	j.Status = JobRunning
+	j.Attempts++
+
+	return j, nil
+}
+
+// ExtendJobLease prolongs lease of running job held by worker instance owner,
+// returns ErrNoRows if job isn't running or was leased by another instance
+func (r *Repository) ExtendJobLease(ctx context.Context, id string, owner string, lease time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE jobs SET lockedUntil=NOW() + INTERVAL ? MICROSECOND
+				WHERE id=? AND status=? AND lockedBy=?`
+
+	res, err := r.db.ExecContext(ctx, query, lease.Microseconds(), id, JobRunning, owner)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// FinishJob marks job leased by worker instance owner as done and saves name of generated check
+func (r *Repository) FinishJob(ctx context.Context, id string, owner string, filename string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := "UPDATE jobs SET status=?, filename=?, error='', lockedUntil=NULL WHERE id=? AND lockedBy=?"
+
+	_, err := r.db.ExecContext(ctx, query, JobDone, filename, id, owner)
+
+	return err
+}
+
+// RetryJob returns job leased by worker instance owner in queue, it will be claimed after given delay
+func (r *Repository) RetryJob(ctx context.Context, id string, owner string, errMsg string, delay time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE jobs SET status=?, error=?, nextRunAt=NOW() + INTERVAL ? MICROSECOND, lockedUntil=NULL
+				WHERE id=? AND lockedBy=?`
+
+	_, err := r.db.ExecContext(ctx, query, JobQueued, truncate(errMsg, 255), delay.Microseconds(), id, owner)
+
+	return err
+}
+
+// FailJob marks job leased by worker instance owner as failed, job won't be executed again
+func (r *Repository) FailJob(ctx context.Context, id string, owner string, errMsg string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := "UPDATE jobs SET status=?, error=?, lockedUntil=NULL WHERE id=? AND lockedBy=?"
+
+	_, err := r.db.ExecContext(ctx, query, JobFailed, truncate(errMsg, 255), id, owner)
+
+	return err
+}
+
+// RequeueExpiredJobs returns in queue running jobs which lease expired because their worker was stopped
+// or lost connection, jobs of live workers keep being extended and aren't touched
+func (r *Repository) RequeueExpiredJobs(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `UPDATE jobs SET status=?, nextRunAt=NOW()
+				WHERE status=? AND (lockedUntil IS NULL OR lockedUntil<NOW())`
+
+	res, err := r.db.ExecContext(ctx, query, JobQueued, JobRunning)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// UserJob returns job if it was created by user with given login
+func (r *Repository) UserJob(ctx context.Context, id string, login string) (*Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	query := `SELECT jobs.id, users.login, jobs.barcode, jobs.template, jobs.status, jobs.attempts,
+					jobs.filename, jobs.error, jobs.nextRunAt, jobs.created, jobs.updated
+				FROM jobs
+				JOIN users ON users.id=jobs.userId AND users.login=?
+				WHERE jobs.id=?
+				LIMIT 1`
+
+	var j Job
+
+	err := r.db.GetContext(ctx, &j, query, login, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
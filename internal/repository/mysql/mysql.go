@@ -23,6 +23,7 @@ const (
 var (
 	ErrUniqConstrViolation = errors.New("unique constraints violation")
 	ErrNoRows              = errors.New("no rows in table returned")
+	ErrReceiptIssued       = errors.New("issued fiscal receipt can't be deleted")
 )
 
 // NewMysqlD connects to Mysql with URL scheme [username[:password]@][protocol[(address)]]/dbname[?param1=value1&...&paramN=valueN]
@@ -57,6 +58,11 @@ func NewMysqlRepository(db *sqlx.DB) *Repository {
 	return repo
 }
 
+// DB returns connection pool of repository, used by check store keeping checks in the same database
+func (r *Repository) DB() *sqlx.DB {
+	return r.db
+}
+
 // Close for graceful shutdown
 func (r *Repository) Close() error {
 	return r.db.Close()
@@ -107,21 +113,23 @@ func (r *Repository) UserRegistered(ctx context.Context, login string, pwd []byt
 	return r.db.QueryRowContext(ctx, query, login, pwd).Scan(&checker)
 }
 
+// Product is db layer model of user product, cost is in minor units of currency
 type Product struct {
-	Barcode string    `json:"barcode" `
-	Name    string    `json:"name" `
-	Descr   string    `json:"descr" `
-	Cost    int       `json:"cost" `
-	UserID  int64     `json:"userId"`
-	Deleted bool      `json:"deleted"`
-	Created time.Time `json:"created"`
+	Barcode  string    `json:"barcode" `
+	Name     string    `json:"name" `
+	Descr    string    `json:"descr" `
+	Cost     int64     `json:"cost" `
+	Currency string    `json:"currency"`
+	UserID   int64     `json:"userId"`
+	Deleted  bool      `json:"deleted"`
+	Created  time.Time `json:"created"`
 }
 
 func (r *Repository) Create(ctx context.Context, pr Product, login string) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
 	defer cancel()
 
-	query := "INSERT INTO products (barcode, name, descr, cost,userId) values (?,?,?,?,?) "
+	query := "INSERT INTO products (barcode, name, descr, cost, currency, userId) values (?,?,?,?,?,?) "
 	selector := "SELECT id FROM users WHERE login = ?"
 
 	var tx *sqlx.Tx
@@ -143,7 +151,7 @@ func (r *Repository) Create(ctx context.Context, pr Product, login string) (err
 		return ErrNoRows
 	}
 
-	res, err := r.db.ExecContext(ctx, query, pr.Barcode, pr.Name, pr.Descr, pr.Cost, id)
+	res, err := r.db.ExecContext(ctx, query, pr.Barcode, pr.Name, pr.Descr, pr.Cost, pr.Currency, id)
 
 	if err != nil {
 		errMsql, ok := err.(*mysql.MySQLError)
@@ -166,7 +174,7 @@ func (r *Repository) Create(ctx context.Context, pr Product, login string) (err
 
 func (r *Repository) UserProducts(ctx context.Context, amount int, offset int, usrID string) ([]Product, error) {
 
-	query := `SELECT barcode, name, cost FROM products 
+	query := `SELECT barcode, name, cost, currency FROM products 
 				JOIN users ON users.id=products.userId AND users.login=? 
 				WHERE deleted=FALSE
 				ORDER BY products.created 
@@ -183,7 +191,7 @@ func (r *Repository) UserProducts(ctx context.Context, amount int, offset int, u
 }
 
 func (r *Repository) UserProduct(ctx context.Context, barcode string, login string) (pr *Product, prchecks []string, err error) {
-	queryPr := `SELECT barcode, name, cost, descr,products.created FROM products 
+	queryPr := `SELECT barcode, name, cost, currency, descr,products.created FROM products 
 					JOIN users ON users.id=products.userId AND users.login=? 
 					WHERE barcode=? AND deleted=FALSE 
 					LIMIT 1`
@@ -252,7 +260,7 @@ func (r *Repository) Delete(ctx context.Context, barcode string, login string) e
 	return nil
 }
 func (r *Repository) ProductInfoForCheck(ctx context.Context, barcode string, login string) (*Product, error) {
-	queryPr := `SELECT barcode, name, cost FROM products 
+	queryPr := `SELECT barcode, name, cost, currency FROM products 
 					JOIN users ON users.id=products.userId AND users.login=? 
 					WHERE barcode=? AND deleted=FALSE 
 					LIMIT 1`
@@ -268,55 +276,34 @@ func (r *Repository) ProductInfoForCheck(ctx context.Context, barcode string, lo
 
 	return &pr, nil
 }
-func (r *Repository) UpdateCheckInfo(ctx context.Context, filename string, barcode string, login string) (err error) {
-	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
-	defer cancel()
-
-	query := "INSERT INTO prchecks (filename,barcode) values (?,?) "
-	selector := `SELECT 1 FROM products 
-					JOIN users ON users.id=products.userId AND users.login=? 
-					WHERE barcode=? AND deleted=FALSE 
-					LIMIT 1`
-
-	var tx *sqlx.Tx
-	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if err != nil {
-		return err
-	}
-	var checkUser int
-
-	err = tx.QueryRow(selector, login, barcode).Scan(&checkUser)
-
-	if err != nil {
-		return ErrNoRows
-	}
-
-	res, err := r.db.ExecContext(ctx, query, filename, barcode)
-
-	if err != nil {
-		errMsql, ok := err.(*mysql.MySQLError)
-		if ok && errMsql.Number == 1062 {
-			return ErrUniqConstrViolation
-		}
-		return err
-	}
-
-	_, err = res.LastInsertId()
 
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-
-	return err
+// Check is db layer model of generated product check
+type Check struct {
+	ID              int64          `db:"id" json:"id"`
+	FileName        string         `db:"filename" json:"filename"`
+	Barcode         string         `db:"barcode" json:"barcode"`
+	TemplateID      sql.NullInt64  `db:"templateId" json:"templateId"`
+	TemplateVersion int            `db:"templateVersion" json:"templateVersion"`
+	Created         time.Time      `db:"created" json:"created"`
+	Size            int64          `db:"size" json:"size"`
+	SHA256          string         `db:"sha256" json:"sha256"`
+	ProductName     string         `db:"productName" json:"productName"`
+	ProductCost     int64          `db:"productCost" json:"productCost"`
+	Currency        string         `db:"currency" json:"currency"`
+	ReceiptNumber   sql.NullInt64  `db:"receiptNumber" json:"receiptNumber"`
+	SellerName      string         `db:"sellerName" json:"sellerName"`
+	SellerTaxID     string         `db:"sellerTaxId" json:"sellerTaxId"`
+	SellerAddress   string         `db:"sellerAddress" json:"sellerAddress"`
+	VATRate         int            `db:"vatRate" json:"vatRate"`
+	NetAmount       int64          `db:"netAmount" json:"netAmount"`
+	TaxAmount       int64          `db:"taxAmount" json:"taxAmount"`
+	GrossAmount     int64          `db:"grossAmount" json:"grossAmount"`
+	ConvCurrency    sql.NullString `db:"convCurrency" json:"convCurrency"`
+	ConvRate        sql.NullString `db:"convRate" json:"convRate"`
+	ConvCost        sql.NullInt64  `db:"convCost" json:"convCost"`
+	Archived        sql.NullTime   `db:"archived" json:"archived"`
 }
+
 func (r *Repository) CheckOwnership(ctx context.Context, filename string, login string) error {
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
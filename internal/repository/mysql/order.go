@@ -0,0 +1,327 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Order is db layer model of user order, total is sum of item prices multiplied by quantities without discount,
+// amounts are in minor units of order currency
+type Order struct {
+	ID       int64     `db:"id" json:"id"`
+	Customer string    `db:"customer" json:"customer"`
+	Status   string    `db:"status" json:"status"`
+	Discount int64     `db:"discount" json:"discount"`
+	Total    int64     `db:"total" json:"total"`
+	Currency string    `db:"currency" json:"currency"`
+	Items    int       `db:"items" json:"items"`
+	Created  time.Time `db:"created" json:"created"`
+	Updated  time.Time `db:"updated" json:"updated"`
+}
+
+// OrderEvent is db layer model of order status change made by actor(user login)
+type OrderEvent struct {
+	ID         int64     `db:"id" json:"id"`
+	FromStatus string    `db:"fromStatus" json:"fromStatus"`
+	ToStatus   string    `db:"toStatus" json:"toStatus"`
+	Actor      string    `db:"actor" json:"actor"`
+	Created    time.Time `db:"created" json:"created"`
+}
+
+// OrderItem is db layer model of order line, name and price are snapshot of product at order creation,
+// currency of product is kept only in order
+type OrderItem struct {
+	ID          int64  `db:"id" json:"id"`
+	OrderID     int64  `db:"orderId" json:"orderId"`
+	Barcode     string `db:"barcode" json:"barcode"`
+	ProductName string `db:"productName" json:"productName"`
+	Price       int64  `db:"price" json:"price"`
+	Currency    string `db:"-" json:"-"`
+	Quantity    int    `db:"quantity" json:"quantity"`
+}
+
+// CreateOrder creates order with given status of user products with given quantities for customer, product names and prices
+// are taken in the same transaction, returns ErrNoRows if any product doesn't exist or isn't owned by user.
+// Coupons with given codes are looked up among coupons of seller(user owning ordered products), their per user uses
+// are uses by customer; they stay locked while price checks items with prices and currencies
+// and decides which discounts order gets, returned discounts are recorded and counted as coupon uses
+func (r *Repository) CreateOrder(ctx context.Context, status string, customer string, items []OrderItem, codes []string,
+	price func(items []OrderItem, coupons []CouponUsage) ([]OrderDiscount, error), login string) (id int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	userQuery := "SELECT id FROM users WHERE login = ?"
+	orderQuery := "INSERT INTO orders (userId, customer, status, discount, total, currency) values (?,?,?,?,?,?)"
+	itemQuery := "INSERT INTO order_items (orderId, barcode, productName, price, quantity) values (?,?,?,?,?)"
+	eventQuery := "INSERT INTO order_events (orderId, toStatus, actor) values (?,?,?)"
+	discountQuery := "INSERT INTO order_discounts (orderId, couponId, userId, customer, code, amount) values (?,?,?,?,?,?)"
+	usesQuery := "UPDATE coupons SET uses=uses+1 WHERE id=?"
+
+	barcodes := make([]string, len(items))
+	for i, it := range items {
+		barcodes[i] = it.Barcode
+	}
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// orders are created by seller of ordered products
+	var sellerID int64
+	if err = tx.QueryRowContext(ctx, userQuery, login).Scan(&sellerID); err != nil {
+		return 0, ErrNoRows
+	}
+
+	productsQuery, args, err := sqlx.In(`SELECT barcode, name, cost, currency FROM products
+					WHERE userId=? AND deleted=FALSE AND barcode IN (?)
+					LOCK IN SHARE MODE`, sellerID, barcodes)
+	if err != nil {
+		return 0, err
+	}
+
+	prods := []Product{}
+	if err = tx.SelectContext(ctx, &prods, tx.Rebind(productsQuery), args...); err != nil {
+		return 0, err
+	}
+
+	byBarcode := make(map[string]Product, len(prods))
+	for _, p := range prods {
+		byBarcode[p.Barcode] = p
+	}
+
+	var total int64
+	for i := range items {
+		p, ok := byBarcode[items[i].Barcode]
+		if !ok {
+			return 0, ErrNoRows
+		}
+		items[i].ProductName = p.Name
+		items[i].Price = p.Cost
+		items[i].Currency = p.Currency
+		total += p.Cost * int64(items[i].Quantity)
+	}
+
+	coupons := []CouponUsage{}
+	if len(codes) > 0 {
+		var couponsQuery string
+		couponsQuery, args, err = sqlx.In(`SELECT `+couponColumns+`,
+					(SELECT COUNT(*) FROM order_discounts
+						WHERE order_discounts.couponId=coupons.id AND order_discounts.customer=?
+							AND order_discounts.released IS NULL) AS userUses
+					FROM coupons
+					WHERE coupons.userId=? AND coupons.code IN (?)
+					FOR UPDATE`, customer, sellerID, codes)
+		if err != nil {
+			return 0, err
+		}
+
+		if err = tx.SelectContext(ctx, &coupons, tx.Rebind(couponsQuery), args...); err != nil {
+			return 0, err
+		}
+	}
+
+	discounts, err := price(items, coupons)
+	if err != nil {
+		return 0, err
+	}
+
+	var discount int64
+	for _, d := range discounts {
+		discount += d.Amount
+	}
+
+	res, err := tx.ExecContext(ctx, orderQuery, sellerID, customer, status, discount, total, items[0].Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range items {
+		items[i].OrderID = id
+		_, err = tx.ExecContext(ctx, itemQuery, id, items[i].Barcode, items[i].ProductName, items[i].Price, items[i].Quantity)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, d := range discounts {
+		if _, err = tx.ExecContext(ctx, discountQuery, id, d.CouponID, sellerID, customer, d.Code, d.Amount); err != nil {
+			return 0, err
+		}
+		if _, err = tx.ExecContext(ctx, usesQuery, d.CouponID); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, eventQuery, id, status, login); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+
+	return id, err
+}
+
+// UserOrders returns orders of user with number of items, newest first
+func (r *Repository) UserOrders(ctx context.Context, amount int, offset int, login string) ([]Order, error) {
+	query := `SELECT orders.id, orders.customer, orders.status, orders.discount, orders.total, orders.currency, orders.created, orders.updated,
+					(SELECT COUNT(*) FROM order_items WHERE order_items.orderId=orders.id) AS items
+				FROM orders
+				JOIN users ON users.id=orders.userId AND users.login=?
+				ORDER BY orders.created DESC, orders.id DESC
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	orders := []Order{}
+
+	err := r.db.SelectContext(ctx, &orders, query, login, amount, offset)
+
+	return orders, err
+}
+
+// UserOrder returns order with its items if user owns it
+func (r *Repository) UserOrder(ctx context.Context, id int64, login string) (*Order, []OrderItem, error) {
+	queryOrd := `SELECT orders.id, orders.customer, orders.status, orders.discount, orders.total, orders.currency, orders.created, orders.updated,
+					(SELECT COUNT(*) FROM order_items WHERE order_items.orderId=orders.id) AS items
+				FROM orders
+				JOIN users ON users.id=orders.userId AND users.login=?
+				WHERE orders.id=?
+				LIMIT 1`
+	queryItems := `SELECT id, orderId, barcode, productName, price, quantity FROM order_items
+				WHERE orderId=?
+				ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	var ord Order
+
+	err := r.db.GetContext(ctx, &ord, queryOrd, login, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNoRows
+		}
+		return nil, nil, err
+	}
+
+	items := []OrderItem{}
+
+	err = r.db.SelectContext(ctx, &items, queryItems, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ord, items, nil
+}
+
+// OrderEvents returns status changes of order, oldest first
+func (r *Repository) OrderEvents(ctx context.Context, orderID int64) ([]OrderEvent, error) {
+	query := `SELECT id, fromStatus, toStatus, actor, created FROM order_events
+				WHERE orderId=?
+				ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	events := []OrderEvent{}
+
+	err := r.db.SelectContext(ctx, &events, query, orderID)
+
+	return events, err
+}
+
+// TransitionOrder changes status of user order to given one and records it in order history in one transaction,
+// order row, products of its lines and its active reservations stay locked while check decides whether transition
+// from current status is allowed and how it changes stock in warehouses of user, returned movements are recorded
+// with order and user as actor together with new and closed reservations of order.
+// With releaseDiscounts coupons applied to order are released and their uses are given back
+func (r *Repository) TransitionOrder(ctx context.Context, id int64, to string, releaseDiscounts bool,
+	check func(from string, st *OrderStock) (*StockChange, error), login string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	lockQuery := `SELECT orders.status, orders.userId FROM orders
+					JOIN users ON users.id=orders.userId AND users.login=?
+					WHERE orders.id=?
+					FOR UPDATE`
+	updateQuery := "UPDATE orders SET status=? WHERE id=?"
+	eventQuery := "INSERT INTO order_events (orderId, fromStatus, toStatus, actor) values (?,?,?,?)"
+	usesQuery := `UPDATE coupons
+					JOIN (SELECT couponId, COUNT(*) AS n FROM order_discounts
+							WHERE orderId=? AND released IS NULL
+							GROUP BY couponId) released ON released.couponId=coupons.id
+					SET coupons.uses=coupons.uses-released.n`
+	releaseQuery := "UPDATE order_discounts SET released=CURRENT_TIMESTAMP WHERE orderId=? AND released IS NULL"
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var from string
+	var userID int64
+	if err = tx.QueryRowContext(ctx, lockQuery, login, id).Scan(&from, &userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRows
+		}
+		return err
+	}
+
+	st, err := orderStock(ctx, tx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	change, err := check(from, st)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, updateQuery, to, id); err != nil {
+		return err
+	}
+
+	if err = applyStockChange(ctx, tx, id, change, login); err != nil {
+		return err
+	}
+
+	if releaseDiscounts {
+		if _, err = tx.ExecContext(ctx, usesQuery, id); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, releaseQuery, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, eventQuery, id, from, to, login); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+
+	return err
+}
@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ExchangeRate is db layer model of exchange rate, rate is decimal price of one major unit of base currency
+// in major units of quote currency, it is kept as string so it isn't rounded
+type ExchangeRate struct {
+	ID            int64     `db:"id" json:"id"`
+	Base          string    `db:"base" json:"base"`
+	Quote         string    `db:"quote" json:"quote"`
+	Rate          string    `db:"rate" json:"rate"`
+	EffectiveFrom time.Time `db:"effectiveFrom" json:"effectiveFrom"`
+	Created       time.Time `db:"created" json:"created"`
+}
+
+// SaveExchangeRates adds rates, rate of pair that already has one with the same effective date replaces it
+func (r *Repository) SaveExchangeRates(ctx context.Context, rates []ExchangeRate) (err error) {
+	query := `INSERT INTO exchange_rates (base, quote, rate, effectiveFrom) VALUES (?,?,?,?)
+				ON DUPLICATE KEY UPDATE rate=VALUES(rate), created=CURRENT_TIMESTAMP`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, rt := range rates {
+		if _, err = tx.ExecContext(ctx, query, rt.Base, rt.Quote, rt.Rate, rt.EffectiveFrom); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+
+	return err
+}
+
+// ExchangeRates returns all rates ordered by pair, newest first inside pair
+func (r *Repository) ExchangeRates(ctx context.Context) ([]ExchangeRate, error) {
+	query := `SELECT id, base, quote, rate, effectiveFrom, created FROM exchange_rates
+				ORDER BY base, quote, effectiveFrom DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	rates := []ExchangeRate{}
+
+	err := r.db.SelectContext(ctx, &rates, query)
+
+	return rates, err
+}
+
+// ExchangeRate returns rate between currencies effective at given time, it is either rate of base to quote
+// or of quote to base, direct one is preferred when both have the same effective date
+func (r *Repository) ExchangeRate(ctx context.Context, base, quote string, at time.Time) (*ExchangeRate, error) {
+	query := `SELECT id, base, quote, rate, effectiveFrom, created FROM exchange_rates
+				WHERE ((base=? AND quote=?) OR (base=? AND quote=?)) AND effectiveFrom<=?
+				ORDER BY effectiveFrom DESC, base=? DESC
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var rt ExchangeRate
+
+	err := r.db.GetContext(ctx, &rt, query, base, quote, quote, base, at, base)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &rt, nil
+}
@@ -0,0 +1,210 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// Seller is db layer model of user seller profile printed on checks, VATRate is in basis points
+type Seller struct {
+	Name    string        `db:"sellerName" json:"sellerName"`
+	TaxID   string        `db:"sellerTaxId" json:"sellerTaxId"`
+	Address string        `db:"sellerAddress" json:"sellerAddress"`
+	VATRate sql.NullInt64 `db:"vatRate" json:"vatRate"`
+}
+
+// UserSeller returns seller profile of user with given login
+func (r *Repository) UserSeller(ctx context.Context, login string) (*Seller, error) {
+	query := "SELECT sellerName, sellerTaxId, sellerAddress, vatRate FROM users WHERE login=? LIMIT 1"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var s Seller
+
+	err := r.db.GetContext(ctx, &s, query, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// UpdateUserSeller saves seller profile of user with given login
+func (r *Repository) UpdateUserSeller(ctx context.Context, s Seller, login string) error {
+	query := "UPDATE users SET sellerName=?, sellerTaxId=?, sellerAddress=?, vatRate=? WHERE login=?"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, s.Name, s.TaxID, s.Address, s.VATRate, login)
+
+	return err
+}
+
+// Receipt number statuses
+const (
+	ReceiptReserved = "reserved"
+	ReceiptIssued   = "issued"
+	ReceiptVoid     = "void"
+)
+
+// ReceiptReservationTTL is time reserved receipt number waits for its check,
+// older reservations were left by stopped process and are voided
+const ReceiptReservationTTL = 10 * time.Minute
+
+// ReserveReceiptNumber takes next receipt number of seller and records it as reserved. Sequence row of seller
+// is locked only in this transaction, check is created with reserved number after commit and number is either
+// issued with registered check or voided, so receipt numbers of seller have no gaps
+func (r *Repository) ReserveReceiptNumber(ctx context.Context, login string) (number int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	userQuery := "SELECT id FROM users WHERE login = ?"
+	seqInit := "INSERT IGNORE INTO receipt_sequences (userId) values (?)"
+	seqLock := "SELECT lastNumber FROM receipt_sequences WHERE userId=? FOR UPDATE"
+	seqUpdate := "UPDATE receipt_sequences SET lastNumber=? WHERE userId=?"
+	voidStale := `UPDATE receipt_numbers SET status=?, error='reservation expired'
+					WHERE userId=? AND status=? AND created<NOW() - INTERVAL ? MICROSECOND`
+	query := "INSERT INTO receipt_numbers (userId, number, status) values (?,?,?)"
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var userID int64
+	if err = tx.QueryRowContext(ctx, userQuery, login).Scan(&userID); err != nil {
+		return 0, ErrNoRows
+	}
+
+	if _, err = tx.ExecContext(ctx, seqInit, userID); err != nil {
+		return 0, err
+	}
+
+	var last int64
+	if err = tx.QueryRowContext(ctx, seqLock, userID).Scan(&last); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, voidStale, ReceiptVoid, userID, ReceiptReserved, ReceiptReservationTTL.Microseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, query, userID, last+1, ReceiptReserved); err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, seqUpdate, last+1, userID); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return last + 1, nil
+}
+
+// RegisterCheck saves check created with reserved receipt number and marks number as issued,
+// returns ErrNoRows if product isn't owned by user anymore or reservation was voided
+func (r *Repository) RegisterCheck(ctx context.Context, login string, ch *Check) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	userQuery := "SELECT id FROM users WHERE login = ?"
+	ownerQuery := `SELECT 1 FROM products
+					WHERE barcode=? AND userId=? AND deleted=FALSE
+					LIMIT 1`
+	query := `INSERT INTO prchecks (filename,barcode,templateId,templateVersion,size,sha256,productName,productCost,
+				currency,receiptNumber,sellerName,sellerTaxId,sellerAddress,vatRate,netAmount,taxAmount,grossAmount,
+				convCurrency,convRate,convCost)
+				values (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
+	issue := "UPDATE receipt_numbers SET status=?, checkId=? WHERE userId=? AND number=? AND status=?"
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var userID int64
+	if err = tx.QueryRowContext(ctx, userQuery, login).Scan(&userID); err != nil {
+		return ErrNoRows
+	}
+
+	var owner int
+	if err = tx.QueryRowContext(ctx, ownerQuery, ch.Barcode, userID).Scan(&owner); err != nil {
+		return ErrNoRows
+	}
+
+	res, err := tx.ExecContext(ctx, query, ch.FileName, ch.Barcode, ch.TemplateID, ch.TemplateVersion,
+		ch.Size, ch.SHA256, ch.ProductName, ch.ProductCost,
+		ch.Currency, ch.ReceiptNumber, ch.SellerName, ch.SellerTaxID, ch.SellerAddress, ch.VATRate, ch.NetAmount, ch.TaxAmount, ch.GrossAmount,
+		ch.ConvCurrency, ch.ConvRate, ch.ConvCost)
+	if err != nil {
+		errMsql, ok := err.(*mysql.MySQLError)
+		if ok && errMsql.Number == 1062 {
+			return ErrUniqConstrViolation
+		}
+		return err
+	}
+
+	checkID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	res, err = tx.ExecContext(ctx, issue, ReceiptIssued, checkID, userID, ch.ReceiptNumber, ReceiptReserved)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	err = tx.Commit()
+
+	return err
+}
+
+// VoidReceiptNumber marks reserved receipt number of seller as void, reason is why check with it wasn't created
+func (r *Repository) VoidReceiptNumber(ctx context.Context, login string, number int64, reason string) error {
+	query := `UPDATE receipt_numbers
+				JOIN users ON users.id=receipt_numbers.userId AND users.login=?
+				SET receipt_numbers.status=?, receipt_numbers.error=?
+				WHERE receipt_numbers.number=? AND receipt_numbers.status=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, login, ReceiptVoid, truncate(reason, 255), number, ReceiptReserved)
+
+	return err
+}
@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StockReservation is db layer model of goods of order line held in warehouse, reservation is active
+// until order is paid, cancelled or reservation expires
+type StockReservation struct {
+	ID          int64        `db:"id" json:"id"`
+	OrderID     int64        `db:"orderId" json:"orderId"`
+	OrderItemID int64        `db:"orderItemId" json:"orderItemId"`
+	Barcode     string       `db:"barcode" json:"barcode"`
+	WarehouseID int64        `db:"warehouseId" json:"warehouseId"`
+	Quantity    int64        `db:"quantity" json:"quantity"`
+	Status      string       `db:"status" json:"status"`
+	Expires     time.Time    `db:"expires" json:"expires"`
+	Created     time.Time    `db:"created" json:"created"`
+	Closed      sql.NullTime `db:"closed" json:"closed"`
+}
+
+// StockChange is stock movements and reservations made by order status change, closed reservations
+// are active reservations of order with their new status
+type StockChange struct {
+	Movements []StockMovement
+	Reserve   []StockReservation
+	Close     []StockReservation
+}
+
+// ReservationActive is status of reservation that holds goods, only active reservations are closed
+const ReservationActive = "active"
+
+const stockReservationColumns = `id, orderId, orderItemId, barcode, warehouseId, quantity, status, expires, created, closed`
+
+// ExpireStockReservations marks up to limit active reservations that expired before given time with given status,
+// goods they held become available; returns number of expired reservations
+func (r *Repository) ExpireStockReservations(ctx context.Context, status string, before time.Time, limit int) (int64, error) {
+	query := `UPDATE stock_reservations SET status=?, closed=CURRENT_TIMESTAMP
+				WHERE status=? AND expires<=?
+				ORDER BY id
+				LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, status, ReservationActive, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// applyStockChange records stock change of order with user as actor
+func applyStockChange(ctx context.Context, tx *sqlx.Tx, orderID int64, ch *StockChange, login string) error {
+	reserveQuery := `INSERT INTO stock_reservations (orderId, orderItemId, barcode, warehouseId, quantity, status, expires)
+					values (?,?,?,?,?,?,?)`
+	closeQuery := "UPDATE stock_reservations SET status=?, closed=CURRENT_TIMESTAMP WHERE id=? AND orderId=? AND status=?"
+
+	if ch == nil {
+		return nil
+	}
+
+	for _, m := range ch.Movements {
+		if _, err := tx.ExecContext(ctx, stockMovementInsert, m.Barcode, m.WarehouseID, m.Kind, m.Quantity, orderID, nil, m.Note, login); err != nil {
+			return err
+		}
+	}
+
+	for _, res := range ch.Close {
+		if _, err := tx.ExecContext(ctx, closeQuery, res.Status, res.ID, orderID, ReservationActive); err != nil {
+			return err
+		}
+	}
+
+	for _, res := range ch.Reserve {
+		_, err := tx.ExecContext(ctx, reserveQuery, orderID, res.OrderItemID, res.Barcode, res.WarehouseID,
+			res.Quantity, ReservationActive, res.Expires)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reservedStock returns quantities held by active reservations of products in warehouse with given id
+// or in all warehouses when it's zero
+func reservedStock(ctx context.Context, tx *sqlx.Tx, warehouseID int64, barcodes []string) ([]WarehouseStock, error) {
+	query, args, err := sqlx.In(`SELECT warehouseId, barcode, COALESCE(SUM(quantity), 0) AS reserved
+					FROM stock_reservations
+					WHERE status=? AND (?=0 OR warehouseId=?) AND barcode IN (?)
+					GROUP BY warehouseId, barcode
+					LOCK IN SHARE MODE`, ReservationActive, warehouseID, warehouseID, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := []WarehouseStock{}
+	err = tx.SelectContext(ctx, &reserved, tx.Rebind(query), args...)
+
+	return reserved, err
+}
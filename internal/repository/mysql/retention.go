@@ -0,0 +1,141 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StoredCheck holds check record fields needed to clean up its file, fiscal receipts have receipt number
+type StoredCheck struct {
+	ID            int64         `db:"id"`
+	FileName      string        `db:"filename"`
+	Created       time.Time     `db:"created"`
+	ReceiptNumber sql.NullInt64 `db:"receiptNumber"`
+}
+
+// ExpiredChecks returns checks with stored files created before given time, oldest first
+func (r *Repository) ExpiredChecks(ctx context.Context, before time.Time, limit int) ([]StoredCheck, error) {
+	query := `SELECT id, filename, created, receiptNumber FROM prchecks
+				WHERE created<? AND archived IS NULL
+				ORDER BY created, id
+				LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	checks := []StoredCheck{}
+
+	err := r.db.SelectContext(ctx, &checks, query, before, limit)
+
+	return checks, err
+}
+
+// OverQuotaChecks returns checks of users that have more than quota checks with stored files,
+// all but newest quota checks of user are returned
+func (r *Repository) OverQuotaChecks(ctx context.Context, quota int, limit int) ([]StoredCheck, error) {
+	query := `SELECT id, filename, created, receiptNumber FROM (
+					SELECT prchecks.id, prchecks.filename, prchecks.created, prchecks.receiptNumber,
+						ROW_NUMBER() OVER (PARTITION BY products.userId ORDER BY prchecks.created DESC, prchecks.id DESC) AS n
+					FROM prchecks
+					JOIN products ON products.barcode=prchecks.barcode
+					WHERE prchecks.archived IS NULL
+				) ranked
+				WHERE n>?
+				ORDER BY created, id
+				LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	checks := []StoredCheck{}
+
+	err := r.db.SelectContext(ctx, &checks, query, quota, limit)
+
+	return checks, err
+}
+
+// ChecksAfter returns checks with stored files with id greater than given one created before given time, ordered by id
+func (r *Repository) ChecksAfter(ctx context.Context, afterID int64, before time.Time, limit int) ([]StoredCheck, error) {
+	query := `SELECT id, filename, created, receiptNumber FROM prchecks
+				WHERE id>? AND created<? AND archived IS NULL
+				ORDER BY id
+				LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	checks := []StoredCheck{}
+
+	err := r.db.SelectContext(ctx, &checks, query, afterID, before, limit)
+
+	return checks, err
+}
+
+// ReferencedCheckFiles returns those of given file names that have check records, files of archived checks aren't referenced
+func (r *Repository) ReferencedCheckFiles(ctx context.Context, names []string) ([]string, error) {
+	res := []string{}
+	if len(names) == 0 {
+		return res, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT filename FROM prchecks WHERE filename IN (?) AND archived IS NULL`, names)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	err = r.db.SelectContext(ctx, &res, r.db.Rebind(query), args...)
+
+	return res, err
+}
+
+// RemoveChecks deletes check records regardless of owner, fiscal receipts are never deleted and have to be archived,
+// returns number of deleted records
+func (r *Repository) RemoveChecks(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(`DELETE FROM prchecks WHERE id IN (?) AND receiptNumber IS NULL`, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ArchiveChecks marks check records regardless of owner as archived, their files aren't stored anymore,
+// returns number of archived records
+func (r *Repository) ArchiveChecks(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(`UPDATE prchecks SET archived=NOW() WHERE id IN (?) AND archived IS NULL`, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// UserSettings is db layer model of user locale settings
+type UserSettings struct {
+	Locale   string `db:"locale" json:"locale"`
+	Currency string `db:"currency" json:"currency"`
+	TimeZone string `db:"timeZone" json:"timeZone"`
+}
+
+// UserSettings returns locale settings of user with given login
+func (r *Repository) UserSettings(ctx context.Context, login string) (*UserSettings, error) {
+	query := "SELECT locale, currency, timeZone FROM users WHERE login=? LIMIT 1"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var s UserSettings
+
+	err := r.db.GetContext(ctx, &s, query, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// UpdateUserSettings saves locale settings of user with given login
+func (r *Repository) UpdateUserSettings(ctx context.Context, s UserSettings, login string) error {
+	query := "UPDATE users SET locale=?, currency=?, timeZone=? WHERE login=?"
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, s.Locale, s.Currency, s.TimeZone, login)
+
+	return err
+}
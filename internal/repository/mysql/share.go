@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CheckShare is db layer model of public link to check
+type CheckShare struct {
+	ID           int64         `db:"id" json:"id"`
+	FileName     string        `db:"filename" json:"filename"`
+	Expires      time.Time     `db:"expires" json:"expires"`
+	MaxDownloads sql.NullInt64 `db:"maxDownloads" json:"maxDownloads"`
+	Downloads    int           `db:"downloads" json:"downloads"`
+	Revoked      bool          `db:"revoked" json:"revoked"`
+	Created      time.Time     `db:"created" json:"created"`
+}
+
+// SharedCheck holds check file name and login of its owner found by share link
+type SharedCheck struct {
+	FileName string `db:"filename"`
+	Login    string `db:"login"`
+}
+
+const shareColumns = `check_shares.id, prchecks.filename, check_shares.expires, check_shares.maxDownloads,
+					check_shares.downloads, check_shares.revoked, check_shares.created`
+
+// CreateCheckShare adds share link to check with given file name if user owns checked product, returns id of link
+func (r *Repository) CreateCheckShare(ctx context.Context, sh CheckShare, login string) (int64, error) {
+	query := `INSERT INTO check_shares (checkId, expires, maxDownloads)
+				SELECT prchecks.id, ?, ? FROM prchecks
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE prchecks.filename=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, sh.Expires, sh.MaxDownloads, login, sh.FileName)
+	if err != nil {
+		return 0, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowC == 0 {
+		return 0, ErrNoRows
+	}
+
+	return res.LastInsertId()
+}
+
+// UserCheckShares returns share links of user checks, only links of check with given file name if it's set
+func (r *Repository) UserCheckShares(ctx context.Context, filename string, login string) ([]CheckShare, error) {
+	query := `SELECT ` + shareColumns + ` FROM check_shares
+				JOIN prchecks ON prchecks.id=check_shares.checkId
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE ?='' OR prchecks.filename=?
+				ORDER BY check_shares.id DESC
+				LIMIT 100`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	shares := []CheckShare{}
+
+	err := r.db.SelectContext(ctx, &shares, query, login, filename, filename)
+
+	return shares, err
+}
+
+// RevokeCheckShare disables share link if user owns shared check
+func (r *Repository) RevokeCheckShare(ctx context.Context, id int64, login string) error {
+	query := `UPDATE check_shares
+				JOIN prchecks ON prchecks.id=check_shares.checkId
+				JOIN products ON products.barcode=prchecks.barcode
+				JOIN users ON users.id=products.userId AND users.login=?
+				SET check_shares.revoked=TRUE
+				WHERE check_shares.id=? AND check_shares.revoked=FALSE`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, login, id)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// UseCheckShare takes one download of share link if link isn't revoked, isn't expired at given time
+// and has downloads left, returns shared check; download is taken by single conditional update
+// so concurrent requests can't use link more times than it allows, returns ErrNoRows if link isn't usable
+func (r *Repository) UseCheckShare(ctx context.Context, id int64, at time.Time) (*SharedCheck, error) {
+	use := `UPDATE check_shares
+				JOIN prchecks ON prchecks.id=check_shares.checkId
+				JOIN products ON products.barcode=prchecks.barcode AND products.deleted=FALSE
+				SET check_shares.downloads=check_shares.downloads+1
+				WHERE check_shares.id=? AND check_shares.revoked=FALSE AND check_shares.expires>?
+					AND (check_shares.maxDownloads IS NULL OR check_shares.downloads<check_shares.maxDownloads)`
+	query := `SELECT prchecks.filename, users.login FROM check_shares
+				JOIN prchecks ON prchecks.id=check_shares.checkId
+				JOIN products ON products.barcode=prchecks.barcode
+				JOIN users ON users.id=products.userId
+				WHERE check_shares.id=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, use, id, at)
+	if err != nil {
+		return nil, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowC == 0 {
+		return nil, ErrNoRows
+	}
+
+	var sh SharedCheck
+
+	err = r.db.GetContext(ctx, &sh, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &sh, nil
+}
+
+// ReturnCheckShareDownload gives back download taken by UseCheckShare when shared check couldn't be prepared
+func (r *Repository) ReturnCheckShareDownload(ctx context.Context, id int64) error {
+	query := `UPDATE check_shares SET downloads=downloads-1 WHERE id=? AND downloads>0`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, id)
+
+	return err
+}
@@ -0,0 +1,361 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StockMovement is db layer model of stock ledger entry, quantity is signed change of product stock in warehouse,
+// order is set for movements made by order status changes and transfer for movements between warehouses
+type StockMovement struct {
+	ID          int64         `db:"id" json:"id"`
+	Barcode     string        `db:"barcode" json:"barcode"`
+	WarehouseID int64         `db:"warehouseId" json:"warehouseId"`
+	Warehouse   string        `db:"warehouse" json:"warehouse"`
+	Kind        string        `db:"kind" json:"kind"`
+	Quantity    int64         `db:"quantity" json:"quantity"`
+	OrderID     sql.NullInt64 `db:"orderId" json:"orderId"`
+	TransferID  sql.NullInt64 `db:"transferId" json:"transferId"`
+	Note        string        `db:"note" json:"note"`
+	Actor       string        `db:"actor" json:"actor"`
+	Created     time.Time     `db:"created" json:"created"`
+}
+
+// StockLevel is current stock of product with time of its last movement, reserved is part of stock held
+// by active reservations
+type StockLevel struct {
+	Barcode  string       `db:"barcode" json:"barcode"`
+	Name     string       `db:"name" json:"name"`
+	Stock    int64        `db:"stock" json:"stock"`
+	Reserved int64        `db:"reserved" json:"reserved"`
+	Updated  sql.NullTime `db:"updated" json:"updated"`
+}
+
+// WarehouseLevel is current stock of product in warehouse
+type WarehouseLevel struct {
+	Code     string `db:"code" json:"code"`
+	Name     string `db:"name" json:"name"`
+	Stock    int64  `db:"stock" json:"stock"`
+	Reserved int64  `db:"reserved" json:"reserved"`
+}
+
+// WarehouseStock is stock of product in warehouse, reserved is part of it held by active reservations
+// and sold is quantity order took from it and didn't return yet
+type WarehouseStock struct {
+	WarehouseID int64  `db:"warehouseId" json:"warehouseId"`
+	Barcode     string `db:"barcode" json:"barcode"`
+	Stock       int64  `db:"stock" json:"stock"`
+	Reserved    int64  `db:"reserved" json:"reserved"`
+	Sold        int64  `db:"sold" json:"sold"`
+}
+
+// Available returns stock that isn't held by reservations
+func (s WarehouseStock) Available() int64 {
+	return s.Stock - s.Reserved
+}
+
+// OrderStock is stock of products of order lines, warehouses are active warehouses of user ordered by priority,
+// levels hold stock of order products in every warehouse that has their movements or reservations,
+// reservations are active reservations of order
+type OrderStock struct {
+	Items        []OrderItem
+	Warehouses   []Warehouse
+	Levels       []WarehouseStock
+	Reservations []StockReservation
+}
+
+const stockMovementColumns = `stock_movements.id, stock_movements.barcode, stock_movements.warehouseId, warehouses.code AS warehouse,
+					stock_movements.kind, stock_movements.quantity, stock_movements.orderId, stock_movements.transferId,
+					stock_movements.note, stock_movements.actor, stock_movements.created`
+
+const stockMovementInsert = `INSERT INTO stock_movements (barcode, warehouseId, kind, quantity, orderId, transferId, note, actor)
+					values (?,?,?,?,?,?,?,?)`
+
+// AddStockMovement records movement of user product in active warehouse of user with code m.Warehouse(warehouse with
+// lowest priority when it's empty), product row stays locked while check decides whether movement can be applied
+// to current stock of warehouse with given code with part of it held by reservations, returns ErrNoRows
+// if user doesn't own product or warehouse
+func (r *Repository) AddStockMovement(ctx context.Context, m StockMovement, check func(warehouse string, stock, reserved int64) error, login string) (id int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	lockQuery := `SELECT products.barcode FROM products
+					JOIN users ON users.id=products.userId AND users.login=?
+					WHERE products.barcode=? AND products.deleted=FALSE
+					FOR UPDATE`
+	warehouseQuery := `SELECT warehouses.id, warehouses.code FROM warehouses
+					JOIN users ON users.id=warehouses.userId AND users.login=?
+					WHERE warehouses.archived=FALSE AND (?='' OR warehouses.code=?)
+					ORDER BY warehouses.priority, warehouses.id
+					LIMIT 1
+					LOCK IN SHARE MODE`
+	stockQuery := `SELECT COALESCE(SUM(quantity), 0) FROM stock_movements
+					WHERE barcode=? AND warehouseId=?
+					LOCK IN SHARE MODE`
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var barcode string
+	if err = tx.QueryRowContext(ctx, lockQuery, login, m.Barcode).Scan(&barcode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, err
+	}
+
+	var warehouseID int64
+	var warehouse string
+	if err = tx.QueryRowContext(ctx, warehouseQuery, login, m.Warehouse, m.Warehouse).Scan(&warehouseID, &warehouse); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, err
+	}
+
+	var stock int64
+	if err = tx.QueryRowContext(ctx, stockQuery, barcode, warehouseID).Scan(&stock); err != nil {
+		return 0, err
+	}
+
+	reserved, err := reservedStock(ctx, tx, warehouseID, []string{barcode})
+	if err != nil {
+		return 0, err
+	}
+
+	var held int64
+	if len(reserved) > 0 {
+		held = reserved[0].Reserved
+	}
+
+	if err = check(warehouse, stock, held); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, stockMovementInsert, barcode, warehouseID, m.Kind, m.Quantity, m.OrderID, m.TransferID, m.Note, login)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+
+	return id, err
+}
+
+// ProductStock returns current stock of user product
+func (r *Repository) ProductStock(ctx context.Context, barcode string, login string) (*StockLevel, error) {
+	query := `SELECT products.barcode, products.name,
+					COALESCE(SUM(stock_movements.quantity), 0) AS stock, MAX(stock_movements.created) AS updated,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.barcode=products.barcode AND stock_reservations.status='active') AS reserved
+				FROM products
+				JOIN users ON users.id=products.userId AND users.login=?
+				LEFT JOIN stock_movements ON stock_movements.barcode=products.barcode
+				WHERE products.barcode=? AND products.deleted=FALSE
+				GROUP BY products.barcode, products.name`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var lvl StockLevel
+
+	err := r.db.GetContext(ctx, &lvl, query, login, barcode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &lvl, nil
+}
+
+// UserStock returns current stock of user products in order products were created
+func (r *Repository) UserStock(ctx context.Context, amount int, offset int, login string) ([]StockLevel, error) {
+	query := `SELECT products.barcode, products.name,
+					COALESCE(SUM(stock_movements.quantity), 0) AS stock, MAX(stock_movements.created) AS updated,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.barcode=products.barcode AND stock_reservations.status='active') AS reserved
+				FROM products
+				JOIN users ON users.id=products.userId AND users.login=?
+				LEFT JOIN stock_movements ON stock_movements.barcode=products.barcode
+				WHERE products.deleted=FALSE
+				GROUP BY products.barcode, products.name, products.created
+				ORDER BY products.created, products.barcode
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	levels := []StockLevel{}
+
+	err := r.db.SelectContext(ctx, &levels, query, login, amount, offset)
+
+	return levels, err
+}
+
+// ProductWarehouseStock returns current stock of user product in every active warehouse of user ordered by priority
+func (r *Repository) ProductWarehouseStock(ctx context.Context, barcode string, login string) ([]WarehouseLevel, error) {
+	query := `SELECT warehouses.code, warehouses.name, COALESCE(SUM(stock_movements.quantity), 0) AS stock,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.warehouseId=warehouses.id AND stock_reservations.barcode=?
+							AND stock_reservations.status='active') AS reserved
+				FROM warehouses
+				JOIN users ON users.id=warehouses.userId AND users.login=?
+				LEFT JOIN stock_movements ON stock_movements.warehouseId=warehouses.id AND stock_movements.barcode=?
+				WHERE warehouses.archived=FALSE
+				GROUP BY warehouses.id, warehouses.code, warehouses.name, warehouses.priority
+				ORDER BY warehouses.priority, warehouses.id`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	levels := []WarehouseLevel{}
+
+	err := r.db.SelectContext(ctx, &levels, query, barcode, login, barcode)
+
+	return levels, err
+}
+
+// WarehouseStock returns current stock of user products in warehouse of user with given code
+// in order products were created
+func (r *Repository) WarehouseStock(ctx context.Context, code string, amount int, offset int, login string) ([]StockLevel, error) {
+	query := `SELECT products.barcode, products.name,
+					COALESCE(SUM(stock_movements.quantity), 0) AS stock, MAX(stock_movements.created) AS updated,
+					(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+						WHERE stock_reservations.warehouseId=warehouses.id AND stock_reservations.barcode=products.barcode
+							AND stock_reservations.status='active') AS reserved
+				FROM products
+				JOIN users ON users.id=products.userId AND users.login=?
+				JOIN warehouses ON warehouses.userId=users.id AND warehouses.code=?
+				LEFT JOIN stock_movements ON stock_movements.barcode=products.barcode AND stock_movements.warehouseId=warehouses.id
+				WHERE products.deleted=FALSE
+				GROUP BY products.barcode, products.name, products.created, warehouses.id
+				ORDER BY products.created, products.barcode
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	levels := []StockLevel{}
+
+	err := r.db.SelectContext(ctx, &levels, query, login, code, amount, offset)
+
+	return levels, err
+}
+
+// StockMovements returns movements of user product, newest first
+func (r *Repository) StockMovements(ctx context.Context, barcode string, amount int, offset int, login string) ([]StockMovement, error) {
+	query := `SELECT ` + stockMovementColumns + ` FROM stock_movements
+				JOIN warehouses ON warehouses.id=stock_movements.warehouseId
+				JOIN products ON products.barcode=stock_movements.barcode AND products.deleted=FALSE
+				JOIN users ON users.id=products.userId AND users.login=?
+				WHERE stock_movements.barcode=?
+				ORDER BY stock_movements.id DESC
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	movements := []StockMovement{}
+
+	err := r.db.SelectContext(ctx, &movements, query, login, barcode, amount, offset)
+
+	return movements, err
+}
+
+// orderStock locks products of order lines and active reservations of order and returns stock of products
+// in warehouses of user with quantities held by reservations and quantities order took from it
+func orderStock(ctx context.Context, tx *sqlx.Tx, orderID int64, userID int64) (*OrderStock, error) {
+	itemsQuery := `SELECT order_items.id, order_items.barcode, order_items.quantity FROM order_items
+					JOIN products ON products.barcode=order_items.barcode
+					WHERE order_items.orderId=?
+					ORDER BY order_items.barcode
+					FOR UPDATE`
+	warehousesQuery := `SELECT ` + warehouseColumns + ` FROM warehouses
+					WHERE userId=? AND archived=FALSE
+					ORDER BY priority, id
+					LOCK IN SHARE MODE`
+	reservationsQuery := `SELECT ` + stockReservationColumns + ` FROM stock_reservations
+					WHERE orderId=? AND status=?
+					ORDER BY id
+					FOR UPDATE`
+
+	st := &OrderStock{
+		Items:        []OrderItem{},
+		Warehouses:   []Warehouse{},
+		Levels:       []WarehouseStock{},
+		Reservations: []StockReservation{},
+	}
+	if err := tx.SelectContext(ctx, &st.Items, itemsQuery, orderID); err != nil {
+		return nil, err
+	}
+	if len(st.Items) == 0 {
+		return st, nil
+	}
+
+	if err := tx.SelectContext(ctx, &st.Warehouses, warehousesQuery, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.SelectContext(ctx, &st.Reservations, reservationsQuery, orderID, ReservationActive); err != nil {
+		return nil, err
+	}
+
+	barcodes := make([]string, len(st.Items))
+	for i, it := range st.Items {
+		barcodes[i] = it.Barcode
+	}
+
+	stockQuery, args, err := sqlx.In(`SELECT warehouseId, barcode, COALESCE(SUM(quantity), 0) AS stock,
+					-COALESCE(SUM(IF(orderId=?, quantity, 0)), 0) AS sold
+					FROM stock_movements
+					WHERE barcode IN (?)
+					GROUP BY warehouseId, barcode
+					LOCK IN SHARE MODE`, orderID, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.SelectContext(ctx, &st.Levels, tx.Rebind(stockQuery), args...); err != nil {
+		return nil, err
+	}
+
+	reserved, err := reservedStock(ctx, tx, 0, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reserved {
+		found := false
+		for i := range st.Levels {
+			if st.Levels[i].WarehouseID == r.WarehouseID && st.Levels[i].Barcode == r.Barcode {
+				st.Levels[i].Reserved = r.Reserved
+				found = true
+				break
+			}
+		}
+		if !found {
+			st.Levels = append(st.Levels, r)
+		}
+	}
+
+	return st, nil
+}
@@ -0,0 +1,224 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// Template is db layer model of one version of user check template
+type Template struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Version   int       `db:"version" json:"version"`
+	FileName  string    `db:"filename" json:"filename"`
+	FontFile  string    `db:"fontFile" json:"fontFile"`
+	Layout    []byte    `db:"layout" json:"layout"`
+	IsDefault bool      `db:"isDefault" json:"isDefault"`
+	Created   time.Time `db:"created" json:"created"`
+}
+
+// CreateTemplateVersion adds new version of user template, template is created if it doesn't exist
+// returns number of created version
+func (r *Repository) CreateTemplateVersion(ctx context.Context, tpl Template, login string) (version int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	selector := "SELECT id FROM users WHERE login = ?"
+	upsert := `INSERT INTO templates (name, userId) values (?,?)
+				ON DUPLICATE KEY UPDATE id=LAST_INSERT_ID(id)`
+	lastVersion := `SELECT COALESCE(MAX(version), 0) FROM template_versions
+						WHERE templateId=? FOR UPDATE`
+	query := `INSERT INTO template_versions (templateId, version, filename, fontFile, layout)
+				values (?,?,?,?,?)`
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var userID int64
+	if err = tx.QueryRowContext(ctx, selector, login).Scan(&userID); err != nil {
+		return 0, ErrNoRows
+	}
+
+	res, err := tx.ExecContext(ctx, upsert, tpl.Name, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	tplID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.QueryRowContext(ctx, lastVersion, tplID).Scan(&version); err != nil {
+		return 0, err
+	}
+	version++
+
+	_, err = tx.ExecContext(ctx, query, tplID, version, tpl.FileName, tpl.FontFile, tpl.Layout)
+	if err != nil {
+		errMsql, ok := err.(*mysql.MySQLError)
+		if ok && errMsql.Number == 1062 {
+			return 0, ErrUniqConstrViolation
+		}
+		return 0, err
+	}
+
+	err = tx.Commit()
+
+	return version, err
+}
+
+// UserTemplates returns latest versions of all user templates
+func (r *Repository) UserTemplates(ctx context.Context, login string) ([]Template, error) {
+	query := `SELECT templates.id, templates.name, v.version, v.filename, v.fontFile, v.layout, v.created,
+					COALESCE(users.templateId = templates.id, FALSE) AS isDefault
+				FROM templates
+				JOIN users ON users.id=templates.userId AND users.login=?
+				JOIN template_versions v ON v.templateId=templates.id
+					AND v.version=(SELECT MAX(version) FROM template_versions WHERE templateId=templates.id)
+				ORDER BY templates.name`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	tpls := []Template{}
+
+	err := r.db.SelectContext(ctx, &tpls, query, login)
+
+	return tpls, err
+}
+
+// UserTemplate returns given version of user template, if version is 0 returns latest version
+func (r *Repository) UserTemplate(ctx context.Context, name string, version int, login string) (*Template, error) {
+	query := `SELECT templates.id, templates.name, v.version, v.filename, v.fontFile, v.layout, v.created,
+					COALESCE(users.templateId = templates.id, FALSE) AS isDefault
+				FROM templates
+				JOIN users ON users.id=templates.userId AND users.login=?
+				JOIN template_versions v ON v.templateId=templates.id
+				WHERE templates.name=? AND (v.version=? OR
+					(?=0 AND v.version=(SELECT MAX(version) FROM template_versions WHERE templateId=templates.id)))
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var tpl Template
+
+	err := r.db.GetContext(ctx, &tpl, query, login, name, version, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+// TemplateVersion returns version of template by its id, used to render checks with template they were created with
+func (r *Repository) TemplateVersion(ctx context.Context, id int64, version int) (*Template, error) {
+	query := `SELECT templates.id, templates.name, v.version, v.filename, v.fontFile, v.layout, v.created,
+					FALSE AS isDefault
+				FROM templates
+				JOIN template_versions v ON v.templateId=templates.id
+				WHERE templates.id=? AND v.version=?
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var tpl Template
+
+	err := r.db.GetContext(ctx, &tpl, query, id, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+// DefaultTemplate returns latest version of template user chose as default
+func (r *Repository) DefaultTemplate(ctx context.Context, login string) (*Template, error) {
+	query := `SELECT templates.id, templates.name, v.version, v.filename, v.fontFile, v.layout, v.created,
+					TRUE AS isDefault
+				FROM templates
+				JOIN users ON users.templateId=templates.id AND users.login=?
+				JOIN template_versions v ON v.templateId=templates.id
+					AND v.version=(SELECT MAX(version) FROM template_versions WHERE templateId=templates.id)
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var tpl Template
+
+	err := r.db.GetContext(ctx, &tpl, query, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+// SetDefaultTemplate makes template with given name default for user,
+// empty name resets user choice
+func (r *Repository) SetDefaultTemplate(ctx context.Context, name string, login string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var res sql.Result
+	var err error
+	if name == "" {
+		res, err = r.db.ExecContext(ctx, "UPDATE users SET templateId=NULL WHERE login=?", login)
+	} else {
+		query := `UPDATE users
+					JOIN templates ON templates.userId=users.id AND templates.name=?
+					SET users.templateId=templates.id
+					WHERE users.login=?`
+		res, err = r.db.ExecContext(ctx, query, name, login)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		var exists int
+		err = r.db.QueryRowContext(ctx, `SELECT 1 FROM templates
+				JOIN users ON users.id=templates.userId AND users.login=?
+				WHERE templates.name=? LIMIT 1`, login, name).Scan(&exists)
+		if err != nil {
+			return ErrNoRows
+		}
+	}
+
+	return nil
+}
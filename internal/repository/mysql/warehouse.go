@@ -0,0 +1,368 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// Warehouse is db layer model of storage location of user, warehouses with lower priority are used first
+type Warehouse struct {
+	ID       int64     `db:"id" json:"id"`
+	Code     string    `db:"code" json:"code"`
+	Name     string    `db:"name" json:"name"`
+	Address  string    `db:"address" json:"address"`
+	Priority int       `db:"priority" json:"priority"`
+	Archived bool      `db:"archived" json:"archived"`
+	Created  time.Time `db:"created" json:"created"`
+}
+
+// Transfer is db layer model of document moving products between warehouses of user
+type Transfer struct {
+	ID      int64     `db:"id" json:"id"`
+	From    string    `db:"fromCode" json:"from"`
+	To      string    `db:"toCode" json:"to"`
+	Note    string    `db:"note" json:"note"`
+	Actor   string    `db:"actor" json:"actor"`
+	Items   int       `db:"items" json:"items"`
+	Created time.Time `db:"created" json:"created"`
+}
+
+// TransferItem is db layer model of transfer line
+type TransferItem struct {
+	ID         int64  `db:"id" json:"id"`
+	TransferID int64  `db:"transferId" json:"transferId"`
+	Barcode    string `db:"barcode" json:"barcode"`
+	Quantity   int64  `db:"quantity" json:"quantity"`
+}
+
+const warehouseColumns = `warehouses.id, warehouses.code, warehouses.name, warehouses.address, warehouses.priority,
+					warehouses.archived, warehouses.created`
+
+const transferColumns = `transfers.id, fromW.code AS fromCode, toW.code AS toCode, transfers.note, transfers.actor, transfers.created,
+					(SELECT COUNT(*) FROM transfer_items WHERE transfer_items.transferId=transfers.id) AS items`
+
+// CreateWarehouse adds warehouse of user, returns ErrUniqConstrViolation if user already has warehouse
+// with the same code, archived ones included
+func (r *Repository) CreateWarehouse(ctx context.Context, w Warehouse, login string) (int64, error) {
+	query := `INSERT INTO warehouses (userId, code, name, address, priority)
+				SELECT id, ?, ?, ?, ? FROM users WHERE login=?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, query, w.Code, w.Name, w.Address, w.Priority, login)
+	if err != nil {
+		errMsql, ok := err.(*mysql.MySQLError)
+		if ok && errMsql.Number == 1062 {
+			return 0, ErrUniqConstrViolation
+		}
+		return 0, err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowC == 0 {
+		return 0, ErrNoRows
+	}
+
+	return res.LastInsertId()
+}
+
+// UserWarehouses returns active warehouses of user ordered by priority
+func (r *Repository) UserWarehouses(ctx context.Context, login string) ([]Warehouse, error) {
+	query := `SELECT ` + warehouseColumns + ` FROM warehouses
+				JOIN users ON users.id=warehouses.userId AND users.login=?
+				WHERE warehouses.archived=FALSE
+				ORDER BY warehouses.priority, warehouses.id
+				LIMIT 1000`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	warehouses := []Warehouse{}
+
+	err := r.db.SelectContext(ctx, &warehouses, query, login)
+
+	return warehouses, err
+}
+
+// UserWarehouse returns warehouse of user with given code, archived ones included
+func (r *Repository) UserWarehouse(ctx context.Context, code string, login string) (*Warehouse, error) {
+	query := `SELECT ` + warehouseColumns + ` FROM warehouses
+				JOIN users ON users.id=warehouses.userId AND users.login=?
+				WHERE warehouses.code=?
+				LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var w Warehouse
+
+	err := r.db.GetContext(ctx, &w, query, login, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// ArchiveWarehouse archives active warehouse of user, warehouse row stays locked while check decides
+// whether warehouse with given number of products with non zero stock or active reservations can be archived
+func (r *Repository) ArchiveWarehouse(ctx context.Context, code string, check func(products int) error, login string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	lockQuery := `SELECT warehouses.id FROM warehouses
+					JOIN users ON users.id=warehouses.userId AND users.login=?
+					WHERE warehouses.code=? AND warehouses.archived=FALSE
+					FOR UPDATE`
+	stockQuery := `SELECT COUNT(*) FROM (
+						SELECT barcode FROM stock_movements
+						WHERE warehouseId=?
+						GROUP BY barcode
+						HAVING SUM(quantity)<>0
+						UNION
+						SELECT barcode FROM stock_reservations
+						WHERE warehouseId=? AND status=?
+					) AS stocked`
+	archiveQuery := "UPDATE warehouses SET archived=TRUE WHERE id=?"
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, lockQuery, login, code).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRows
+		}
+		return err
+	}
+
+	var products int
+	if err = tx.QueryRowContext(ctx, stockQuery, id, id, ReservationActive).Scan(&products); err != nil {
+		return err
+	}
+
+	if err = check(products); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, archiveQuery, id); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+
+	return err
+}
+
+// CreateTransfer records transfer of user products between two active warehouses of user with codes t.From and t.To,
+// lines and movements are recorded in the same transaction. Products stay locked while check decides with stock of
+// source warehouse and its part held by reservations whether transfer is allowed and which movements between warehouses with given ids it makes,
+// returned movements are recorded with transfer and user as actor; returns ErrNoRows if user doesn't own
+// any of warehouses or products
+func (r *Repository) CreateTransfer(ctx context.Context, t Transfer, items []TransferItem,
+	check func(fromID, toID int64, stock []WarehouseStock) ([]StockMovement, error), login string) (id int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	userQuery := "SELECT id FROM users WHERE login = ?"
+	warehouseQuery := `SELECT id FROM warehouses
+					WHERE userId=? AND code=? AND archived=FALSE
+					LOCK IN SHARE MODE`
+	transferQuery := "INSERT INTO transfers (userId, fromWarehouseId, toWarehouseId, note, actor) values (?,?,?,?,?)"
+	itemQuery := "INSERT INTO transfer_items (transferId, barcode, quantity) values (?,?,?)"
+
+	barcodes := make([]string, len(items))
+	for i, it := range items {
+		barcodes[i] = it.Barcode
+	}
+
+	var tx *sqlx.Tx
+	tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var userID int64
+	if err = tx.QueryRowContext(ctx, userQuery, login).Scan(&userID); err != nil {
+		return 0, ErrNoRows
+	}
+
+	var fromID, toID int64
+	if err = tx.QueryRowContext(ctx, warehouseQuery, userID, t.From).Scan(&fromID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, err
+	}
+	if err = tx.QueryRowContext(ctx, warehouseQuery, userID, t.To).Scan(&toID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRows
+		}
+		return 0, err
+	}
+
+	productsQuery, args, err := sqlx.In(`SELECT barcode FROM products
+					WHERE userId=? AND deleted=FALSE AND barcode IN (?)
+					ORDER BY barcode
+					FOR UPDATE`, userID, barcodes)
+	if err != nil {
+		return 0, err
+	}
+
+	locked := []string{}
+	if err = tx.SelectContext(ctx, &locked, tx.Rebind(productsQuery), args...); err != nil {
+		return 0, err
+	}
+	if len(locked) != len(items) {
+		return 0, ErrNoRows
+	}
+
+	stockQuery, args, err := sqlx.In(`SELECT warehouseId, barcode, COALESCE(SUM(quantity), 0) AS stock, 0 AS sold
+					FROM stock_movements
+					WHERE warehouseId=? AND barcode IN (?)
+					GROUP BY warehouseId, barcode
+					LOCK IN SHARE MODE`, fromID, barcodes)
+	if err != nil {
+		return 0, err
+	}
+
+	levels := []WarehouseStock{}
+	if err = tx.SelectContext(ctx, &levels, tx.Rebind(stockQuery), args...); err != nil {
+		return 0, err
+	}
+
+	reserved, err := reservedStock(ctx, tx, fromID, barcodes)
+	if err != nil {
+		return 0, err
+	}
+
+	byBarcode := make(map[string]WarehouseStock, len(levels))
+	for _, l := range levels {
+		byBarcode[l.Barcode] = l
+	}
+	for _, r := range reserved {
+		l := byBarcode[r.Barcode]
+		l.Reserved = r.Reserved
+		byBarcode[r.Barcode] = l
+	}
+	stock := make([]WarehouseStock, len(items))
+	for i, it := range items {
+		stock[i] = WarehouseStock{
+			WarehouseID: fromID,
+			Barcode:     it.Barcode,
+			Stock:       byBarcode[it.Barcode].Stock,
+			Reserved:    byBarcode[it.Barcode].Reserved,
+		}
+	}
+
+	movements, err := check(fromID, toID, stock)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, transferQuery, userID, fromID, toID, t.Note, login)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, it := range items {
+		if _, err = tx.ExecContext(ctx, itemQuery, id, it.Barcode, it.Quantity); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, m := range movements {
+		if _, err = tx.ExecContext(ctx, stockMovementInsert, m.Barcode, m.WarehouseID, m.Kind, m.Quantity, nil, id, t.Note, login); err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+
+	return id, err
+}
+
+// UserTransfers returns transfers of user with number of lines, newest first
+func (r *Repository) UserTransfers(ctx context.Context, amount int, offset int, login string) ([]Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM transfers
+				JOIN users ON users.id=transfers.userId AND users.login=?
+				JOIN warehouses AS fromW ON fromW.id=transfers.fromWarehouseId
+				JOIN warehouses AS toW ON toW.id=transfers.toWarehouseId
+				ORDER BY transfers.id DESC
+				LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	transfers := []Transfer{}
+
+	err := r.db.SelectContext(ctx, &transfers, query, login, amount, offset)
+
+	return transfers, err
+}
+
+// UserTransfer returns transfer with its lines if user owns it
+func (r *Repository) UserTransfer(ctx context.Context, id int64, login string) (*Transfer, []TransferItem, error) {
+	queryTransfer := `SELECT ` + transferColumns + ` FROM transfers
+				JOIN users ON users.id=transfers.userId AND users.login=?
+				JOIN warehouses AS fromW ON fromW.id=transfers.fromWarehouseId
+				JOIN warehouses AS toW ON toW.id=transfers.toWarehouseId
+				WHERE transfers.id=?
+				LIMIT 1`
+	queryItems := `SELECT id, transferId, barcode, quantity FROM transfer_items
+				WHERE transferId=?
+				ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut*3)
+	defer cancel()
+
+	var t Transfer
+
+	err := r.db.GetContext(ctx, &t, queryTransfer, login, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNoRows
+		}
+		return nil, nil, err
+	}
+
+	items := []TransferItem{}
+
+	err = r.db.SelectContext(ctx, &items, queryItems, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &t, items, nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// blobTimeOut limits queries of single objects, content is read and written with three times longer limit
+const blobTimeOut = time.Second * 3
+
+// BlobStore keeps checks as BLOBs in mysql check_blobs table
+type BlobStore struct {
+	db *sqlx.DB
+}
+
+// NewBlobStore returns check store that uses given connection pool
+func NewBlobStore(db *sqlx.DB) *BlobStore {
+	return &BlobStore{db: db}
+}
+
+type blobInfo struct {
+	Name     string    `db:"name"`
+	Size     int64     `db:"size"`
+	Modified time.Time `db:"modified"`
+}
+
+// Put inserts object content, stored object is never replaced
+func (s *BlobStore) Put(ctx context.Context, name string, r io.Reader) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, blobTimeOut*3)
+	defer cancel()
+
+	query := "INSERT INTO check_blobs (name, data, size) values (?,?,?)"
+
+	_, err = s.db.ExecContext(ctx, query, name, data, len(data))
+	if err != nil {
+		errMsql, ok := err.(*mysql.MySQLError)
+		if ok && errMsql.Number == 1062 {
+			return ErrExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get returns object content
+func (s *BlobStore) Get(ctx context.Context, name string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, blobTimeOut*3)
+	defer cancel()
+
+	query := "SELECT name, size, modified, data FROM check_blobs WHERE name=?"
+
+	var res struct {
+		blobInfo
+		Data []byte `db:"data"`
+	}
+
+	err := s.db.GetContext(ctx, &res, query, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	return NewBytesObject(res.Data), res.objectInfo(), nil
+}
+
+// Delete removes object
+func (s *BlobStore) Delete(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, blobTimeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM check_blobs WHERE name=?", name)
+	if err != nil {
+		return err
+	}
+
+	rowC, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowC == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Stat returns information about object without reading its content
+func (s *BlobStore) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, blobTimeOut)
+	defer cancel()
+
+	var info blobInfo
+
+	err := s.db.GetContext(ctx, &info, "SELECT name, size, modified FROM check_blobs WHERE name=?", name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return info.objectInfo(), nil
+}
+
+// List calls fn for every stored object, objects are read in batches ordered by name
+func (s *BlobStore) List(ctx context.Context, fn func(info ObjectInfo) error) error {
+	const batch = 1000
+
+	query := "SELECT name, size, modified FROM check_blobs WHERE name>? ORDER BY name LIMIT ?"
+
+	last := ""
+	for {
+		infos := []blobInfo{}
+
+		qctx, cancel := context.WithTimeout(ctx, blobTimeOut*3)
+		err := s.db.SelectContext(qctx, &infos, query, last, batch)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		for _, info := range infos {
+			if err := fn(*info.objectInfo()); err != nil {
+				return err
+			}
+		}
+
+		if len(infos) < batch {
+			return nil
+		}
+		last = infos[len(infos)-1].Name
+	}
+}
+
+func (i blobInfo) objectInfo() *ObjectInfo {
+	return &ObjectInfo{
+		Name:    i.Name,
+		Size:    i.Size,
+		ModTime: i.Modified,
+	}
+}
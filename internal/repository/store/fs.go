@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FSStore keeps checks as files in local directory
+type FSStore struct {
+	dir string
+}
+
+// NewFSStore returns store that keeps checks in given directory, directory is created if needed
+func NewFSStore(dir string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FSStore{dir: dir}, nil
+}
+
+// Put writes object into temporary file and links it under its name when it is completely written,
+// link fails if file with this name already exists so stored file is never replaced;
+// temporary file is removed whether object is stored or not
+func (s *FSStore) Put(_ context.Context, name string, r io.Reader) (err error) {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer func() {
+		if err != nil {
+			tmp.Close()
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Link(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			err = ErrExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get opens stored file
+func (s *FSStore) Get(_ context.Context, name string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	if !ValidName(name) {
+		return nil, nil, ErrInvalidName
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, nil, mapFSError(err)
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, fileInfo(st), nil
+}
+
+// Delete removes stored file
+func (s *FSStore) Delete(_ context.Context, name string) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	return mapFSError(os.Remove(filepath.Join(s.dir, name)))
+}
+
+// Stat returns information about stored file
+func (s *FSStore) Stat(_ context.Context, name string) (*ObjectInfo, error) {
+	if !ValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	st, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, mapFSError(err)
+	}
+
+	return fileInfo(st), nil
+}
+
+// List calls fn for every stored file, temporary files of unfinished writes are skipped
+func (s *FSStore) List(ctx context.Context, fn func(info ObjectInfo) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		st, err := e.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+
+		if err := fn(*fileInfo(st)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fileInfo(st fs.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Name:    st.Name(),
+		Size:    st.Size(),
+		ModTime: st.ModTime(),
+	}
+}
+
+func mapFSError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
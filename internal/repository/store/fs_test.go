@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFSStorePutDoesNotReplace(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewFSStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("second")); !errors.Is(err, ErrExists) {
+		t.Fatalf("second Put returned %v, want ErrExists", err)
+	}
+
+	f, _, err := st.Get(ctx, "doc_1.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "first" {
+		t.Fatalf("stored file was replaced with %q", b)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("store directory has %d entries, temporary files were left", len(entries))
+	}
+}
+
+func TestFSStoreNotFound(t *testing.T) {
+	st, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, _, err := st.Get(ctx, "doc_1.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get returned %v, want ErrNotFound", err)
+	}
+	if err := st.Delete(ctx, "doc_1.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Delete returned %v, want ErrNotFound", err)
+	}
+}
+
+// failingReader returns error after part of content is read
+type failingReader struct {
+	read bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if !r.read {
+		r.read = true
+		return copy(p, "partial"), nil
+	}
+	return 0, errors.New("connection reset")
+}
+
+func TestFSStorePutRemovesTemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewFSStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Put(context.Background(), "doc_1.pdf", &failingReader{}); err == nil {
+		t.Fatal("Put of failed content succeeded")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("store directory has %d entries after failed write, want none", len(entries))
+	}
+}
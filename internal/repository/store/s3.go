@@ -0,0 +1,448 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+)
+
+const (
+	s3Service      = "s3"
+	s3Algorithm    = "AWS4-HMAC-SHA256"
+	s3TimeFormat   = "20060102T150405Z"
+	s3DateFormat   = "20060102"
+	s3EmptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Store keeps checks in bucket of S3 compatible storage(AWS S3, MinIO, etc.),
+// requests are signed with AWS Signature Version 4
+type S3Store struct {
+	client    http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+}
+
+// NewS3Store returns store that keeps checks in configured bucket
+func NewS3Store(cfg *config.S3) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	s := &S3Store{
+		client:    http.Client{Timeout: cfg.Timeout},
+		endpoint:  endpoint,
+		region:    cfg.Region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+	}
+	return s, nil
+}
+
+// Put uploads object into bucket only if there is no object with this name, conditional write
+// with If-None-Match is answered with 412 when object exists
+func (s *S3Store) Put(ctx context.Context, name string, r io.Reader) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, name, body, http.Header{"If-None-Match": {"*"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrExists
+	}
+
+	return checkS3Response(resp)
+}
+
+// Get returns object content streamed from bucket, content isn't kept in memory
+func (s *S3Store) Get(ctx context.Context, name string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	if !ValidName(name) {
+		return nil, nil, ErrInvalidName
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkS3Response(resp); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+
+	if resp.ContentLength < 0 {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("s3 responded without content length of %s", name)
+	}
+
+	info := objectInfo(name, resp)
+	info.Size = resp.ContentLength
+
+	obj := &s3Object{
+		s:    s,
+		ctx:  ctx,
+		name: name,
+		size: resp.ContentLength,
+		body: resp.Body,
+	}
+	return obj, info, nil
+}
+
+// s3Object reads object content from response body, when it is read from other offset
+// than body is at, body is closed and object is requested again from that offset with range request
+type s3Object struct {
+	s       *S3Store
+	ctx     context.Context
+	name    string
+	size    int64
+	off     int64 // offset of next read
+	body    io.ReadCloser
+	bodyOff int64 // offset body is at
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.off >= o.size {
+		return 0, io.EOF
+	}
+
+	if o.body != nil && o.bodyOff != o.off {
+		o.body.Close()
+		o.body = nil
+	}
+	if o.body == nil {
+		if err := o.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := o.body.Read(p)
+	o.off += int64(n)
+	o.bodyOff = o.off
+	if err == io.EOF && o.off < o.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// open requests object content from current offset
+func (o *s3Object) open() error {
+	header := http.Header{"Range": {"bytes=" + strconv.FormatInt(o.off, 10) + "-"}}
+	resp, err := o.s.do(o.ctx, http.MethodGet, o.name, nil, header)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		if err := checkS3Response(resp); err != nil {
+			return err
+		}
+		return fmt.Errorf("s3 responded with status code %d to range request", resp.StatusCode)
+	}
+
+	o.body, o.bodyOff = resp.Body, o.off
+	return nil
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.off
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	o.off = offset
+	return offset, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+// Delete removes object from bucket
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkS3Response(resp)
+}
+
+// Stat returns information about object in bucket
+func (s *S3Store) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	if !ValidName(name) {
+		return nil, ErrInvalidName
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkS3Response(resp); err != nil {
+		return nil, err
+	}
+
+	return objectInfo(name, resp), nil
+}
+
+// s3ListResult is page of ListObjectsV2 response
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List calls fn for every object in bucket, objects are listed with ListObjectsV2 page by page,
+// keys with path elements weren't stored by this service and are skipped
+func (s *S3Store) List(ctx context.Context, fn func(info ObjectInfo) error) error {
+	var token string
+
+	for {
+		q := url.Values{"list-type": {"2"}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		u := s.bucketURL()
+		u.RawQuery = s3Query(q)
+
+		page, err := s.list(ctx, u)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range page.Contents {
+			if !ValidName(obj.Key) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(ObjectInfo{Name: obj.Key, Size: obj.Size, ModTime: obj.LastModified}); err != nil {
+				return err
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+func (s *S3Store) list(ctx context.Context, u *url.URL) (*s3ListResult, error) {
+	resp, err := s.send(ctx, http.MethodGet, u, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkS3Response(resp); err != nil {
+		return nil, err
+	}
+
+	var page s3ListResult
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("invalid s3 list response: %w", err)
+	}
+
+	return &page, nil
+}
+
+func (s *S3Store) bucketURL() *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	}
+	return &u
+}
+
+func (s *S3Store) objectURL(name string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + name
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+	}
+	return &u
+}
+
+func (s *S3Store) do(ctx context.Context, method string, name string, body []byte, header http.Header) (*http.Response, error) {
+	return s.send(ctx, method, s.objectURL(name), body, header)
+}
+
+func (s *S3Store) send(ctx context.Context, method string, u *url.URL, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	if body != nil {
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	s.sign(req, body, time.Now().UTC())
+
+	return s.client.Do(req)
+}
+
+// sign adds AWS Signature Version 4 headers to request
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := s3EmptyPayload
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format(s3DateFormat)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headerNames := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		headerNames = append(headerNames, strings.ToLower(k))
+	}
+	sort.Strings(headerNames)
+
+	var canonHeaders strings.Builder
+	for _, k := range headerNames {
+		canonHeaders.WriteString(k)
+		canonHeaders.WriteByte(':')
+		canonHeaders.WriteString(strings.TrimSpace(req.Header.Get(k)))
+		canonHeaders.WriteByte('\n')
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3Query(req.URL.Query()),
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region, s3Service, "aws4_request"}, "/")
+	canonHash := sha256.Sum256([]byte(canonRequest))
+	strToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, strToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+// s3Query encodes query sorted by keys with spaces escaped as %20, as canonical request requires
+func s3Query(q url.Values) string {
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func checkS3Response(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 responded with status code %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+func objectInfo(name string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{Name: name}
+
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+
+	if mod, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = mod
+	}
+
+	return info
+}
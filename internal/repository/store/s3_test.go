@@ -0,0 +1,394 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+)
+
+// fakeS3 is in-memory bucket answering like MinIO with path style addressing,
+// it checks signature of every request, supports conditional PUT with If-None-Match: *,
+// GET of open byte range and ListObjectsV2 with pages of pageSize keys
+type fakeS3 struct {
+	bucket   string
+	signer   *S3Store
+	mu       sync.Mutex
+	objects  map[string][]byte
+	puts     int
+	gets     []string // ranges of GET requests, empty for whole object
+	pageSize int
+	lists    int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.validSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+
+	if r.URL.Path == "/"+f.bucket && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query().Get("continuation-token"))
+		return
+	}
+
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, ok := f.objects[name]
+	switch r.Method {
+	case http.MethodPut:
+		f.puts++
+		if r.Header.Get("If-None-Match") == "*" && ok {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			io.WriteString(w, "<Error><Code>PreconditionFailed</Code></Error>")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.objects[name] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Last-Modified", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat))
+		status := http.StatusOK
+		if r.Method == http.MethodGet {
+			rng := r.Header.Get("Range")
+			f.gets = append(f.gets, rng)
+			if rng != "" {
+				from, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+				w.Header().Set("Content-Range", "bytes "+strconv.Itoa(from)+"-"+strconv.Itoa(len(data)-1)+"/"+strconv.Itoa(len(data)))
+				data, status = data[from:], http.StatusPartialContent
+			}
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list writes page of keys following the token in lexicographic order, token is the last key of previous page
+func (f *fakeS3) list(w http.ResponseWriter, token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lists++
+
+	keys := make([]string, 0, len(f.objects))
+	for k := range f.objects {
+		if k > token {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	truncated := len(keys) > f.pageSize
+	if truncated {
+		keys = keys[:f.pageSize]
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>` + f.bucket + `</Name>`)
+	b.WriteString("<IsTruncated>" + strconv.FormatBool(truncated) + "</IsTruncated>")
+	if truncated {
+		b.WriteString("<NextContinuationToken>" + keys[len(keys)-1] + "</NextContinuationToken>")
+	}
+	for _, k := range keys {
+		b.WriteString("<Contents><Key>" + k + "</Key><LastModified>2024-01-02T03:04:05.000Z</LastModified>")
+		b.WriteString("<Size>" + strconv.Itoa(len(f.objects[k])) + "</Size></Contents>")
+	}
+	b.WriteString("</ListBucketResult>")
+
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, b.String())
+}
+
+// validSignature signs copy of received request with the same credentials and time and compares signatures
+func (f *fakeS3) validSignature(r *http.Request) bool {
+	got := r.Header.Get("Authorization")
+	at, err := time.Parse(s3TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil || got == "" {
+		return false
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	req, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	for _, k := range []string{"Content-Type", "If-None-Match", "Range"} {
+		if v := r.Header.Get(k); v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+	f.signer.sign(req, body, at)
+
+	return req.Header.Get("Authorization") == got
+}
+
+func newTestS3(t *testing.T) (*S3Store, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{bucket: "checks", objects: map[string][]byte{}, pageSize: 1000}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	st, err := NewS3Store(&config.S3{
+		Endpoint:  srv.URL,
+		Region:    "us-east-1",
+		Bucket:    "checks",
+		PathStyle: true,
+		Timeout:   5 * time.Second,
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// server verifies signatures with its own copy of credentials
+	signer := *st
+	fake.signer = &signer
+
+	return st, fake
+}
+
+func TestS3StoreRoundTrip(t *testing.T) {
+	st, _ := newTestS3(t)
+	ctx := context.Background()
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("%PDF-1")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	f, info, err := st.Get(ctx, "doc_1.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "%PDF-1" || info.Size != 6 || info.ModTime.IsZero() {
+		t.Fatalf("Get returned %q, %+v", b, info)
+	}
+
+	info, err = st.Stat(ctx, "doc_1.pdf")
+	if err != nil || info.Size != 6 {
+		t.Fatalf("Stat returned %+v, %v", info, err)
+	}
+
+	if err := st.Delete(ctx, "doc_1.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, _, err := st.Get(ctx, "doc_1.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of deleted object returned %v, want ErrNotFound", err)
+	}
+	if _, err := st.Stat(ctx, "doc_1.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat of deleted object returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3StoreGetStreams(t *testing.T) {
+	st, fake := newTestS3(t)
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	if err := st.Put(ctx, "doc_1.pdf", bytes.NewReader(content)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	f, info, err := st.Get(ctx, "doc_1.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer f.Close()
+	if info.Size != int64(len(content)) {
+		t.Fatalf("Get returned size %d, want %d", info.Size, len(content))
+	}
+
+	// size is found by seeking to end, object is read from start with body of Get
+	if end, err := f.Seek(0, io.SeekEnd); err != nil || end != int64(len(content)) {
+		t.Fatalf("Seek to end returned %d, %v", end, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(f, head); err != nil || string(head) != "0123456789" {
+		t.Fatalf("read %q, %v", head, err)
+	}
+
+	// read from other offset requests rest of object from it
+	if _, err := f.Seek(99995, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil || string(tail) != "56789" {
+		t.Fatalf("read %q, %v after seek", tail, err)
+	}
+
+	if want := []string{"", "bytes=99995-"}; strings.Join(fake.gets, ",") != strings.Join(want, ",") {
+		t.Fatalf("store made GET requests %q, want %q", fake.gets, want)
+	}
+}
+
+func TestS3StoreServeRange(t *testing.T) {
+	st, _ := newTestS3(t)
+	ctx := context.Background()
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("%PDF-1.4 content")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	f, info, err := st.Get(ctx, "doc_1.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer f.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/doc_1.pdf", nil)
+	req.Header.Set("Range", "bytes=9-")
+	w := httptest.NewRecorder()
+	http.ServeContent(w, req, info.Name, info.ModTime, f)
+
+	if w.Code != http.StatusPartialContent || w.Body.String() != "content" {
+		t.Fatalf("range response is %d %q, want %d %q", w.Code, w.Body.String(), http.StatusPartialContent, "content")
+	}
+}
+
+func TestS3StorePutDoesNotReplace(t *testing.T) {
+	st, fake := newTestS3(t)
+	ctx := context.Background()
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := st.Put(ctx, "doc_1.pdf", strings.NewReader("second")); !errors.Is(err, ErrExists) {
+		t.Fatalf("second Put returned %v, want ErrExists", err)
+	}
+
+	if got := string(fake.objects["doc_1.pdf"]); got != "first" {
+		t.Fatalf("stored object was replaced with %q", got)
+	}
+	if fake.puts != 2 {
+		t.Fatalf("server got %d PUT requests, want 2", fake.puts)
+	}
+}
+
+func TestS3StoreBadCredentials(t *testing.T) {
+	st, _ := newTestS3(t)
+	st.secretKey = "wrong"
+
+	err := st.Put(context.Background(), "doc_1.pdf", strings.NewReader("x"))
+	if err == nil || errors.Is(err, ErrExists) || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Put with wrong secret returned %v, want 403 error", err)
+	}
+}
+
+func TestS3StoreInvalidName(t *testing.T) {
+	st, _ := newTestS3(t)
+
+	for _, name := range []string{"", ".", "..", "a/b.pdf", "../doc.pdf"} {
+		if err := st.Put(context.Background(), name, strings.NewReader("x")); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Put(%q) returned %v, want ErrInvalidName", name, err)
+		}
+	}
+}
+
+func TestS3StoreObjectURL(t *testing.T) {
+	cases := []struct {
+		endpoint  string
+		pathStyle bool
+		want      string
+	}{
+		{"http://localhost:9000", true, "http://localhost:9000/checks/doc.pdf"},
+		{"http://localhost:9000/", true, "http://localhost:9000/checks/doc.pdf"},
+		{"https://s3.eu-west-1.amazonaws.com", false, "https://checks.s3.eu-west-1.amazonaws.com/doc.pdf"},
+	}
+
+	for _, c := range cases {
+		st, err := NewS3Store(&config.S3{Endpoint: c.endpoint, Bucket: "checks", PathStyle: c.pathStyle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := st.objectURL("doc.pdf").String(); got != c.want {
+			t.Errorf("objectURL for %s = %s, want %s", c.endpoint, got, c.want)
+		}
+	}
+}
+
+func TestS3StoreList(t *testing.T) {
+	st, fake := newTestS3(t)
+	fake.pageSize = 2
+	fake.objects["doc_1.pdf"] = []byte("1")
+	fake.objects["doc_2.pdf"] = []byte("22")
+	fake.objects["doc_3 copy.pdf"] = []byte("333")
+	fake.objects["other/doc_4.pdf"] = []byte("4444")
+	fake.objects["doc_5.pdf"] = []byte("55555")
+
+	var got []ObjectInfo
+	err := st.List(context.Background(), func(info ObjectInfo) error {
+		got = append(got, info)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	want := []string{"doc_1.pdf", "doc_2.pdf", "doc_3 copy.pdf", "doc_5.pdf"}
+	if len(got) != len(want) {
+		t.Fatalf("List returned %+v, want %v", got, want)
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, info := range got {
+		if info.Name != want[i] || info.Size != int64(len(fake.objects[want[i]])) || !info.ModTime.Equal(modTime) {
+			t.Errorf("object %d is %+v, want %s", i, info, want[i])
+		}
+	}
+	if fake.lists != 3 {
+		t.Fatalf("server got %d list requests, want 3", fake.lists)
+	}
+}
+
+func TestS3StoreListStops(t *testing.T) {
+	st, fake := newTestS3(t)
+	fake.objects["doc_1.pdf"] = []byte("1")
+	fake.objects["doc_2.pdf"] = []byte("2")
+
+	stop := errors.New("stop")
+	calls := 0
+	err := st.List(context.Background(), func(info ObjectInfo) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("List returned %v after %d calls, want stop after 1", err, calls)
+	}
+}
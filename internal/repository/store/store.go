@@ -0,0 +1,63 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Supported storage backends
+const (
+	TypeFS    = "fs"
+	TypeS3    = "s3"
+	TypeMysql = "mysql"
+)
+
+var (
+	ErrNotFound    = errors.New("object not found in storage")
+	ErrInvalidName = errors.New("invalid object name")
+	ErrExists      = errors.New("object with this name already exists in storage")
+)
+
+// ObjectInfo holds information about stored object
+type ObjectInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// CheckStore used to keep generated checks, Put never replaces stored object and returns ErrExists instead
+type CheckStore interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadSeekCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, name string) error
+	Stat(ctx context.Context, name string) (*ObjectInfo, error)
+}
+
+// Lister is implemented by stores that can enumerate stored objects
+type Lister interface {
+	List(ctx context.Context, fn func(info ObjectInfo) error) error
+}
+
+// ValidName reports whether name can be used as object name(no path elements)
+func ValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+// BytesObject wraps in-memory object content to satisfy io.ReadSeekCloser
+type BytesObject struct {
+	*bytes.Reader
+}
+
+// NewBytesObject returns in-memory object with given content
+func NewBytesObject(b []byte) *BytesObject {
+	return &BytesObject{Reader: bytes.NewReader(b)}
+}
+
+// Close does nothing, content is kept in memory
+func (o *BytesObject) Close() error {
+	return nil
+}
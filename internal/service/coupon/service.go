@@ -0,0 +1,251 @@
+package coupon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	CreateCoupon(ctx context.Context, c mysql.Coupon, login string) (int64, error)
+	UserCoupons(ctx context.Context, login string) ([]mysql.Coupon, error)
+	DisableCoupon(ctx context.Context, code string, login string) error
+}
+
+// Kind is the way coupon reduces order price
+type Kind string
+
+// Coupon kinds, percentage coupon takes Percent basis points off order, fixed one takes Amount
+const (
+	KindPercent Kind = "percent"
+	KindFixed   Kind = "fixed"
+)
+
+// MaxPerOrder is max number of coupons applied to one order
+const MaxPerOrder = 3
+
+var (
+	ErrUnknownKind     = errors.New("unknown coupon kind")
+	ErrValue           = errors.New("coupon value is out of range")
+	ErrValidity        = errors.New("coupon validity window is empty")
+	ErrCurrency        = errors.New("coupon amounts are in different currencies")
+	ErrLimit           = errors.New("coupon usage limit should be positive")
+	ErrCouponExists    = errors.New("coupon with this code already exists")
+	ErrCouponNotFound  = errors.New("coupon doesn't exist")
+	ErrCouponInactive  = errors.New("coupon is disabled or out of its validity window")
+	ErrCouponUsedUp    = errors.New("coupon usage limit is reached")
+	ErrCouponPerUser   = errors.New("coupon usage limit of customer is reached")
+	ErrCouponCustomer  = errors.New("coupon limited per customer requires order customer")
+	ErrCouponMinOrder  = errors.New("order value is below coupon minimum")
+	ErrCouponCurrency  = errors.New("coupon currency differs from order currency")
+	ErrDuplicateCoupon = errors.New("coupon is applied more than once")
+	ErrTooManyCoupons  = errors.New("too many coupons applied to order")
+)
+
+// Coupon is service level model of promotion code, nil limits and bounds and zero minimum mean there are none
+type Coupon struct {
+	Code           string
+	Kind           Kind
+	Percent        int // basis points
+	Amount         money.Money
+	MinOrderValue  money.Money
+	ValidFrom      *time.Time
+	ValidTo        *time.Time
+	MaxUses        *int
+	MaxUsesPerUser *int
+	Uses           int
+	Disabled       bool
+	Created        time.Time
+}
+
+// CService struct implements coupon functionality
+type CService struct {
+	Repo Repository
+}
+
+func NewService(repo Repository) *CService {
+	s := &CService{
+		Repo: repo,
+	}
+	return s
+}
+
+// NormalizeCode returns code in the form it is stored in, codes are case insensitive
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreateCoupon validates and saves coupon of user
+func (s *CService) CreateCoupon(ctx context.Context, c Coupon, login string) (*Coupon, error) {
+	c.Code = NormalizeCode(c.Code)
+
+	var value int64
+	switch c.Kind {
+	case KindPercent:
+		if c.Percent < 1 || c.Percent > 10000 {
+			return nil, ErrValue
+		}
+		value = int64(c.Percent)
+		c.Amount = money.Money{}
+	case KindFixed:
+		if c.Amount.Amount < 1 {
+			return nil, ErrValue
+		}
+		value = c.Amount.Amount
+		c.Percent = 0
+	default:
+		return nil, ErrUnknownKind
+	}
+
+	if c.MinOrderValue.Amount < 0 {
+		return nil, ErrValue
+	}
+
+	var cur sql.NullString
+	for _, m := range []money.Money{c.Amount, c.MinOrderValue} {
+		if m.Amount == 0 {
+			continue
+		}
+		if cur.Valid && cur.String != m.Currency {
+			return nil, ErrCurrency
+		}
+		cur = sql.NullString{String: m.Currency, Valid: true}
+	}
+
+	if c.ValidFrom != nil && c.ValidTo != nil && !c.ValidTo.After(*c.ValidFrom) {
+		return nil, ErrValidity
+	}
+	if (c.MaxUses != nil && *c.MaxUses < 1) || (c.MaxUsesPerUser != nil && *c.MaxUsesPerUser < 1) {
+		return nil, ErrLimit
+	}
+
+	dbModel := mysql.Coupon{
+		Code:           c.Code,
+		Kind:           string(c.Kind),
+		Value:          value,
+		Currency:       cur,
+		MinOrderValue:  c.MinOrderValue.Amount,
+		ValidFrom:      nullTime(c.ValidFrom),
+		ValidTo:        nullTime(c.ValidTo),
+		MaxUses:        nullInt(c.MaxUses),
+		MaxUsesPerUser: nullInt(c.MaxUsesPerUser),
+	}
+
+	if _, err := s.Repo.CreateCoupon(ctx, dbModel, login); err != nil {
+		if errors.Is(err, mysql.ErrUniqConstrViolation) {
+			return nil, ErrCouponExists
+		}
+		return nil, err
+	}
+
+	c.Uses = 0
+	c.Disabled = false
+	c.Created = time.Now()
+	return &c, nil
+}
+
+// UserCoupons returns coupons of user with number of their uses, newest first
+func (s *CService) UserCoupons(ctx context.Context, login string) ([]Coupon, error) {
+	coupons, err := s.Repo.UserCoupons(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Coupon, len(coupons))
+	for i, c := range coupons {
+		res[i] = newCoupon(c)
+	}
+	return res, nil
+}
+
+// DisableCoupon stops coupon of user from being applied to new orders
+func (s *CService) DisableCoupon(ctx context.Context, code string, login string) error {
+	return s.Repo.DisableCoupon(ctx, NormalizeCode(code), login)
+}
+
+// Apply checks that coupon can be applied at time now to order with given subtotal in minor units of currency
+// and returns its discount, remaining is price left after coupons applied before this one, discount never exceeds it
+func Apply(c mysql.CouponUsage, currency string, subtotal, remaining int64, now time.Time) (int64, error) {
+	if c.Disabled || (c.ValidFrom.Valid && now.Before(c.ValidFrom.Time)) || (c.ValidTo.Valid && !now.Before(c.ValidTo.Time)) {
+		return 0, ErrCouponInactive
+	}
+	if c.MaxUses.Valid && int64(c.Uses) >= c.MaxUses.Int64 {
+		return 0, ErrCouponUsedUp
+	}
+	if c.MaxUsesPerUser.Valid && int64(c.UserUses) >= c.MaxUsesPerUser.Int64 {
+		return 0, ErrCouponPerUser
+	}
+	if c.Currency.Valid && c.Currency.String != currency {
+		return 0, ErrCouponCurrency
+	}
+	if subtotal < c.MinOrderValue {
+		return 0, ErrCouponMinOrder
+	}
+
+	var amount int64
+	switch Kind(c.Kind) {
+	case KindPercent:
+		// half up rounding of basis points
+		amount = (remaining*c.Value + 5000) / 10000
+	case KindFixed:
+		amount = c.Value
+	default:
+		return 0, ErrUnknownKind
+	}
+
+	if amount > remaining {
+		amount = remaining
+	}
+	return amount, nil
+}
+
+func newCoupon(c mysql.Coupon) Coupon {
+	res := Coupon{
+		Code:          c.Code,
+		Kind:          Kind(c.Kind),
+		MinOrderValue: money.New(c.MinOrderValue, c.Currency.String),
+		Uses:          c.Uses,
+		Disabled:      c.Disabled,
+		Created:       c.Created,
+	}
+	if res.Kind == KindPercent {
+		res.Percent = int(c.Value)
+	} else {
+		res.Amount = money.New(c.Value, c.Currency.String)
+	}
+	if c.ValidFrom.Valid {
+		res.ValidFrom = &c.ValidFrom.Time
+	}
+	if c.ValidTo.Valid {
+		res.ValidTo = &c.ValidTo.Time
+	}
+	if c.MaxUses.Valid {
+		v := int(c.MaxUses.Int64)
+		res.MaxUses = &v
+	}
+	if c.MaxUsesPerUser.Valid {
+		v := int(c.MaxUsesPerUser.Int64)
+		res.MaxUsesPerUser = &v
+	}
+	return res
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func nullInt(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
@@ -0,0 +1,117 @@
+package coupon
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+func testCoupon(kind Kind, value int64) mysql.CouponUsage {
+	return mysql.CouponUsage{Coupon: mysql.Coupon{Code: "SALE", Kind: string(kind), Value: value}}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		coupon    func(c *mysql.CouponUsage)
+		kind      Kind
+		value     int64
+		subtotal  int64
+		remaining int64
+		want      int64
+		err       error
+	}{
+		{name: "percent", kind: KindPercent, value: 1000, subtotal: 10000, remaining: 10000, want: 1000},
+		{name: "percent of remaining", kind: KindPercent, value: 1000, subtotal: 10000, remaining: 5000, want: 500},
+		{name: "percent rounded half up", kind: KindPercent, value: 1250, subtotal: 1000, remaining: 1004, want: 126},
+		{name: "percent rounded down", kind: KindPercent, value: 1250, subtotal: 1000, remaining: 1003, want: 125},
+		{name: "whole price", kind: KindPercent, value: 10000, subtotal: 999, remaining: 999, want: 999},
+		{name: "fixed", kind: KindFixed, value: 300, subtotal: 1000, remaining: 1000, want: 300},
+		{name: "fixed capped by remaining", kind: KindFixed, value: 300, subtotal: 1000, remaining: 200, want: 200},
+		{name: "nothing remaining", kind: KindFixed, value: 300, subtotal: 1000, remaining: 0, want: 0},
+		{name: "unknown kind", kind: "bogo", value: 1, subtotal: 1000, remaining: 1000, err: ErrUnknownKind},
+		{
+			name:   "disabled",
+			coupon: func(c *mysql.CouponUsage) { c.Disabled = true },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponInactive,
+		},
+		{
+			name:   "not valid yet",
+			coupon: func(c *mysql.CouponUsage) { c.ValidFrom = sql.NullTime{Time: now.Add(time.Second), Valid: true} },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponInactive,
+		},
+		{
+			name:   "valid from now",
+			coupon: func(c *mysql.CouponUsage) { c.ValidFrom = sql.NullTime{Time: now, Valid: true} },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, want: 300,
+		},
+		{
+			name:   "expired",
+			coupon: func(c *mysql.CouponUsage) { c.ValidTo = sql.NullTime{Time: now, Valid: true} },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponInactive,
+		},
+		{
+			name: "used up",
+			coupon: func(c *mysql.CouponUsage) {
+				c.MaxUses = sql.NullInt64{Int64: 5, Valid: true}
+				c.Uses = 5
+			},
+			kind: KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponUsedUp,
+		},
+		{
+			name: "last use",
+			coupon: func(c *mysql.CouponUsage) {
+				c.MaxUses = sql.NullInt64{Int64: 5, Valid: true}
+				c.Uses = 4
+			},
+			kind: KindFixed, value: 300, subtotal: 1000, remaining: 1000, want: 300,
+		},
+		{
+			name: "used up by user",
+			coupon: func(c *mysql.CouponUsage) {
+				c.MaxUsesPerUser = sql.NullInt64{Int64: 1, Valid: true}
+				c.UserUses = 1
+			},
+			kind: KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponPerUser,
+		},
+		{
+			name:   "other currency",
+			coupon: func(c *mysql.CouponUsage) { c.Currency = sql.NullString{String: "EUR", Valid: true} },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, err: ErrCouponCurrency,
+		},
+		{
+			name:   "same currency",
+			coupon: func(c *mysql.CouponUsage) { c.Currency = sql.NullString{String: "USD", Valid: true} },
+			kind:   KindFixed, value: 300, subtotal: 1000, remaining: 1000, want: 300,
+		},
+		{
+			name:   "below minimum",
+			coupon: func(c *mysql.CouponUsage) { c.MinOrderValue = 1001 },
+			kind:   KindPercent, value: 1000, subtotal: 1000, remaining: 1000, err: ErrCouponMinOrder,
+		},
+		{
+			name:   "minimum compared with subtotal",
+			coupon: func(c *mysql.CouponUsage) { c.MinOrderValue = 1000 },
+			kind:   KindPercent, value: 1000, subtotal: 1000, remaining: 100, want: 10,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cp := testCoupon(c.kind, c.value)
+			if c.coupon != nil {
+				c.coupon(&cp)
+			}
+
+			got, err := Apply(cp, "USD", c.subtotal, c.remaining, now)
+			if !errors.Is(err, c.err) || got != c.want {
+				t.Fatalf("Apply = %d, %v; want %d, %v", got, err, c.want, c.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/provider/mail"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/worker"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	CreateDelivery(ctx context.Context, d mysql.CheckDelivery, login string) (int64, error)
+	ClaimDelivery(ctx context.Context, owner string, lease time.Duration) (*mysql.CheckDelivery, error)
+	ExtendDeliveryLease(ctx context.Context, id int64, owner string, lease time.Duration) error
+	FinishDelivery(ctx context.Context, id int64, owner string) error
+	RetryDelivery(ctx context.Context, id int64, owner string, errMsg string, delay time.Duration) error
+	FailDelivery(ctx context.Context, id int64, owner string, errMsg string) error
+	RequeueExpiredDeliveries(ctx context.Context) (int64, error)
+	UserCheckByName(ctx context.Context, filename string, login string) (*mysql.Check, error)
+}
+
+// Checks used to get content of check
+type Checks interface {
+	UserProductCheck(ctx context.Context, fileName string, format render.Format, login string) (*product.CheckFile, error)
+}
+
+// Locales used to get user settings for formatting email
+type Locales interface {
+	Locale(ctx context.Context, login string) (*locale.Settings, error)
+}
+
+// Mailer used to send emails
+type Mailer interface {
+	Send(ctx context.Context, msg *mail.Message) error
+}
+
+var (
+	ErrNoMailer         = errors.New("sending checks by email is not configured")
+	ErrInvalidRecipient = errors.New("recipient should be email address")
+)
+
+var (
+	subjectTmpl = template.Must(template.New("subject").Parse(
+		`Receipt for {{.Product}}{{with .Seller}} from {{.}}{{end}}`))
+	textTmpl = template.Must(template.New("text").Parse(`{{if .Message}}{{.Message}}
+
+{{end}}Your receipt for {{.Product}} is attached.
+
+Amount: {{.Amount}}
+Date: {{.Date}}{{with .Receipt}}
+Receipt No.: {{.}}{{end}}{{with .Seller}}
+Seller: {{.}}{{end}}{{with .TaxID}}
+Tax ID: {{.}}{{end}}
+`))
+)
+
+// emailData is data available in email templates
+type emailData struct {
+	Message string
+	Product string
+	Amount  string
+	Date    string
+	Receipt string
+	Seller  string
+	TaxID   string
+}
+
+// DService struct implements sending checks by email, deliveries are sent on worker pool
+type DService struct {
+	Repo    Repository
+	Checks  Checks
+	Locales Locales
+	Mailer  Mailer
+	From    string
+	Pool    *worker.Pool
+}
+
+// NewService returns delivery service, mailer can be nil if checks shouldn't be sent
+func NewService(repo Repository, checks Checks, locales Locales, mailer Mailer, cfg *config.Mail) *DService {
+	s := &DService{
+		Repo:    repo,
+		Checks:  checks,
+		Locales: locales,
+		Mailer:  mailer,
+		From:    cfg.From,
+	}
+	s.Pool = worker.NewPool("deliveries", &queue{s: s}, worker.Settings{
+		Workers:      cfg.Workers,
+		MaxAttempts:  cfg.MaxAttempts,
+		Backoff:      cfg.Backoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		PollInterval: cfg.PollInterval,
+		Lease:        cfg.Lease,
+	})
+	return s
+}
+
+// SendCheck queues sending of check to recipient with optional message, returns queued delivery
+func (s *DService) SendCheck(ctx context.Context, fileName string, recipient string, message string, login string) (*product.Delivery, error) {
+	if s.Mailer == nil {
+		return nil, ErrNoMailer
+	}
+
+	if !mail.ValidAddress(recipient) {
+		return nil, ErrInvalidRecipient
+	}
+
+	d := mysql.CheckDelivery{
+		FileName:  fileName,
+		Recipient: recipient,
+		Message:   strings.TrimSpace(message),
+	}
+
+	id, err := s.Repo.CreateDelivery(ctx, d, login)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Pool.WakeUp()
+
+	now := time.Now()
+	res := &product.Delivery{
+		ID:        id,
+		Recipient: recipient,
+		Status:    mysql.DeliveryQueued,
+		Created:   now,
+		Updated:   now,
+	}
+	return res, nil
+}
+
+// RunDeliveries sends queued checks on pool of workers until context is canceled
+func (s *DService) RunDeliveries(ctx context.Context) {
+	if s.Mailer == nil {
+		return
+	}
+	s.Pool.Run(ctx)
+}
+
+// queue is queue of checks sent by email
+type queue struct {
+	s *DService
+}
+
+func (q *queue) Claim(ctx context.Context, owner string, lease time.Duration) (worker.Task, error) {
+	d, err := q.s.Repo.ClaimDelivery(ctx, owner, lease)
+	if err != nil {
+		return nil, err
+	}
+	return &task{s: q.s, d: d}, nil
+}
+
+func (q *queue) RequeueExpired(ctx context.Context) (int64, error) {
+	return q.s.Repo.RequeueExpiredDeliveries(ctx)
+}
+
+// Permanent reports whether sending can't succeed on retry, only transient mail errors are retried
+func (q *queue) Permanent(err error) bool {
+	return !mail.IsTransient(err)
+}
+
+// task is claimed delivery of check
+type task struct {
+	s *DService
+	d *mysql.CheckDelivery
+}
+
+func (t *task) Run(ctx context.Context) error {
+	return t.s.send(ctx, t.d)
+}
+
+func (t *task) Attempts() int {
+	return t.d.Attempts
+}
+
+func (t *task) Fields() log.Fields {
+	return log.Fields{"delivery": t.d.ID, "check": t.d.FileName, "userLogin": t.d.Login}
+}
+
+func (t *task) Extend(ctx context.Context, owner string, lease time.Duration) error {
+	return t.s.Repo.ExtendDeliveryLease(ctx, t.d.ID, owner, lease)
+}
+
+func (t *task) Finish(ctx context.Context, owner string) error {
+	return t.s.Repo.FinishDelivery(ctx, t.d.ID, owner)
+}
+
+func (t *task) Retry(ctx context.Context, owner string, errMsg string, delay time.Duration) error {
+	return t.s.Repo.RetryDelivery(ctx, t.d.ID, owner, errMsg, delay)
+}
+
+func (t *task) Fail(ctx context.Context, owner string, errMsg string) error {
+	return t.s.Repo.FailDelivery(ctx, t.d.ID, owner, errMsg)
+}
+
+// send renders email for check and sends it with PDF check attached
+func (s *DService) send(ctx context.Context, d *mysql.CheckDelivery) error {
+	ch, err := s.Repo.UserCheckByName(ctx, d.FileName, d.Login)
+	if err != nil {
+		return err
+	}
+
+	st, err := s.Locales.Locale(ctx, d.Login)
+	if err != nil {
+		return err
+	}
+
+	f, err := s.Checks.UserProductCheck(ctx, d.FileName, render.FormatPDF, d.Login)
+	if err != nil {
+		return err
+	}
+	pdf, err := io.ReadAll(f.Content)
+	f.Content.Close()
+	if err != nil {
+		return err
+	}
+
+	data := emailData{
+		Message: d.Message,
+		Product: ch.ProductName,
+		Amount:  st.Money(money.New(ch.ProductCost, ch.Currency)),
+		Date:    st.Date(ch.Created),
+		Seller:  ch.SellerName,
+		TaxID:   ch.SellerTaxID,
+	}
+	if ch.ReceiptNumber.Valid {
+		data.Receipt = strconv.FormatInt(ch.ReceiptNumber.Int64, 10)
+	}
+
+	var subject, text bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return err
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return err
+	}
+
+	msg := &mail.Message{
+		From:    s.From,
+		To:      d.Recipient,
+		Subject: subject.String(),
+		Text:    text.String(),
+		Attachments: []mail.Attachment{
+			{Name: f.Name, ContentType: f.ContentType, Data: pdf},
+		},
+	}
+
+	return s.Mailer.Send(ctx, msg)
+}
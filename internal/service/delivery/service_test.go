@@ -0,0 +1,153 @@
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/provider/mail"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+)
+
+// deliveryRepo keeps created deliveries and knows one check
+type deliveryRepo struct {
+	Repository
+	created []mysql.CheckDelivery
+}
+
+func (r *deliveryRepo) CreateDelivery(ctx context.Context, d mysql.CheckDelivery, login string) (int64, error) {
+	r.created = append(r.created, d)
+	return int64(len(r.created)), nil
+}
+
+func (r *deliveryRepo) UserCheckByName(ctx context.Context, filename string, login string) (*mysql.Check, error) {
+	if filename != "check.pdf" {
+		return nil, mysql.ErrNoRows
+	}
+	ch := &mysql.Check{
+		FileName:      filename,
+		ProductName:   "Stabilo pen",
+		ProductCost:   1250,
+		Currency:      "EUR",
+		SellerName:    "Stationery Ltd",
+		ReceiptNumber: sql.NullInt64{Int64: 17, Valid: true},
+		Created:       time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	return ch, nil
+}
+
+type testChecks struct{}
+
+func (testChecks) UserProductCheck(ctx context.Context, fileName string, format render.Format, login string) (*product.CheckFile, error) {
+	f := &product.CheckFile{
+		Name:        fileName,
+		ContentType: "application/pdf",
+		Content:     store.NewBytesObject([]byte("%PDF-1.4")),
+	}
+	return f, nil
+}
+
+type testLocales struct{}
+
+func (testLocales) Locale(ctx context.Context, login string) (*locale.Settings, error) {
+	return locale.Default(), nil
+}
+
+type testMailer struct {
+	sent []*mail.Message
+}
+
+func (m *testMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func testDeliveries(mailer Mailer) (*DService, *deliveryRepo) {
+	repo := &deliveryRepo{}
+	s := NewService(repo, testChecks{}, testLocales{}, mailer, &config.Mail{From: "checks@shop.com", Workers: 1})
+	return s, repo
+}
+
+func TestSendCheck(t *testing.T) {
+	ctx := context.Background()
+
+	s, _ := testDeliveries(nil)
+	if _, err := s.SendCheck(ctx, "check.pdf", "buyer@example.com", "", "owner"); !errors.Is(err, ErrNoMailer) {
+		t.Fatalf("SendCheck without mailer returned %v, want ErrNoMailer", err)
+	}
+
+	s, repo := testDeliveries(&testMailer{})
+	for _, to := range []string{"buyer", "Buyer <buyer@example.com>", "a@b.com, c@d.com"} {
+		if _, err := s.SendCheck(ctx, "check.pdf", to, "", "owner"); !errors.Is(err, ErrInvalidRecipient) {
+			t.Errorf("SendCheck to %q returned %v, want ErrInvalidRecipient", to, err)
+		}
+	}
+
+	d, err := s.SendCheck(ctx, "check.pdf", "buyer@example.com", "  Thank you!\n", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != mysql.DeliveryQueued || len(repo.created) != 1 || repo.created[0].Message != "Thank you!" {
+		t.Fatalf("SendCheck queued %+v, returned %+v", repo.created, d)
+	}
+}
+
+func TestDeliveryTask(t *testing.T) {
+	mailer := &testMailer{}
+	s, _ := testDeliveries(mailer)
+
+	d := &mysql.CheckDelivery{ID: 1, FileName: "check.pdf", Recipient: "buyer@example.com", Message: "Thank you!", Login: "owner"}
+	if err := (&task{s: s, d: d}).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(mailer.sent))
+	}
+	msg := mailer.sent[0]
+	if msg.From != "checks@shop.com" || msg.To != "buyer@example.com" || msg.Subject != "Receipt for Stabilo pen from Stationery Ltd" {
+		t.Errorf("email is from %q to %q about %q", msg.From, msg.To, msg.Subject)
+	}
+	for _, line := range []string{"Thank you!", "Receipt No.: 17", "Seller: Stationery Ltd"} {
+		if !strings.Contains(msg.Text, line) {
+			t.Errorf("email text %q has no %q", msg.Text, line)
+		}
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Name != "check.pdf" || string(msg.Attachments[0].Data) != "%PDF-1.4" {
+		t.Errorf("email has attachments %+v, want check PDF", msg.Attachments)
+	}
+
+	d.FileName = "deleted.pdf"
+	if err := (&task{s: s, d: d}).Run(context.Background()); !errors.Is(err, mysql.ErrNoRows) {
+		t.Fatalf("delivery of deleted check returned %v, want ErrNoRows", err)
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	q := &queue{}
+	cases := []struct {
+		err       error
+		permanent bool
+	}{
+		{err: &textproto.Error{Code: 421, Msg: "try again later"}},
+		{err: &textproto.Error{Code: 451, Msg: "greylisted"}},
+		{err: &textproto.Error{Code: 550, Msg: "no such user"}, permanent: true},
+		{err: mail.ErrInvalidAddress, permanent: true},
+		{err: mysql.ErrNoRows, permanent: true},
+	}
+
+	for _, c := range cases {
+		if got := q.Permanent(c.err); got != c.permanent {
+			t.Errorf("Permanent(%v) = %v, want %v", c.err, got, c.permanent)
+		}
+	}
+}
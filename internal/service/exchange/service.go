@@ -0,0 +1,310 @@
+package exchange
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	SaveExchangeRates(ctx context.Context, rates []mysql.ExchangeRate) error
+	ExchangeRates(ctx context.Context) ([]mysql.ExchangeRate, error)
+	ExchangeRate(ctx context.Context, base, quote string, at time.Time) (*mysql.ExchangeRate, error)
+}
+
+// Format is format of rates list
+type Format string
+
+// Supported formats of rates list
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// DateLayout is layout of effective date of rate
+const DateLayout = "2006-01-02"
+
+// maxRates limits number of rates in one list
+const maxRates = 10000
+
+var (
+	ErrNotAdmin      = errors.New("user is not allowed to change exchange rates")
+	ErrUnknownFormat = errors.New("unknown format of rates list")
+	ErrRatesFormat   = errors.New("rates list is malformed")
+	ErrNoRates       = errors.New("rates list is empty")
+	ErrTooManyRates  = errors.New("rates list is too long")
+	ErrSamePair      = errors.New("rate base and quote currencies are the same")
+	ErrEffectiveDate = errors.New("effective date should be in YYYY-MM-DD format")
+	ErrNoRate        = errors.New("no exchange rate between currencies")
+)
+
+// RateError describes invalid rate in list, Index is 1-based position of rate in list, CSV header isn't counted
+type RateError struct {
+	Index int
+	Err   error
+}
+
+func (e *RateError) Error() string {
+	return fmt.Sprintf("rate %d: %s", e.Index, e.Err)
+}
+
+func (e *RateError) Unwrap() error {
+	return e.Err
+}
+
+// Rate is price of one major unit of Base currency in major units of Quote currency,
+// it is used from EffectiveFrom until the next rate of the same pair
+type Rate struct {
+	Base          string
+	Quote         string
+	Rate          *big.Rat
+	EffectiveFrom time.Time
+	Created       time.Time
+}
+
+// XService struct implements exchange rates and currency conversion
+type XService struct {
+	Repo     Repository
+	Rounding money.Rounding
+	Admins   map[string]bool
+}
+
+// NewService returns exchange service, unknown rounding mode falls back to half up
+func NewService(repo Repository, cfg *config.Rates) *XService {
+	rounding, err := money.ParseRounding(cfg.Rounding)
+	if err != nil {
+		log.WithFields(log.Fields{"rounding": cfg.Rounding}).WithError(err).Warn("Converted amounts are rounded half up")
+		rounding = money.RoundHalfUp
+	}
+
+	admins := make(map[string]bool, len(cfg.Admins))
+	for _, login := range cfg.Admins {
+		admins[login] = true
+	}
+
+	s := &XService{
+		Repo:     repo,
+		Rounding: rounding,
+		Admins:   admins,
+	}
+	return s
+}
+
+// LoadRatesFile saves rates from CSV or JSON file, format is chosen by file extension
+func (s *XService) LoadRatesFile(ctx context.Context, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	format := Format(strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")))
+
+	return s.saveRates(ctx, f, format)
+}
+
+// ImportExchangeRates saves rates list sent by admin, rates replace ones of the same pair and effective date
+func (s *XService) ImportExchangeRates(ctx context.Context, list io.Reader, format Format, login string) (int, error) {
+	if !s.Admins[login] {
+		return 0, ErrNotAdmin
+	}
+
+	return s.saveRates(ctx, list, format)
+}
+
+// ExchangeRates returns all rates ordered by pair, newest first inside pair
+func (s *XService) ExchangeRates(ctx context.Context) ([]Rate, error) {
+	rates, err := s.Repo.ExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Rate, 0, len(rates))
+	for _, rt := range rates {
+		r, err := money.ParseRate(rt.Rate)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Rate{
+			Base:          rt.Base,
+			Quote:         rt.Quote,
+			Rate:          r,
+			EffectiveFrom: rt.EffectiveFrom,
+			Created:       rt.Created,
+		})
+	}
+	return res, nil
+}
+
+// ExchangeRate returns multiplier from one currency to another effective at given time,
+// rate of reverse pair is inverted when there is no direct one
+func (s *XService) ExchangeRate(ctx context.Context, from, to string, at time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	rt, err := s.Repo.ExchangeRate(ctx, from, to, at)
+	if err != nil {
+		if errors.Is(err, mysql.ErrNoRows) {
+			return nil, ErrNoRate
+		}
+		return nil, err
+	}
+
+	r, err := money.ParseRate(rt.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	if rt.Base != from {
+		r = money.InverseRate(r)
+		if r.Sign() == 0 {
+			return nil, ErrNoRate
+		}
+	}
+	return r, nil
+}
+
+// Converter returns function converting amounts from one currency to another with the same rate,
+// so all amounts of order are converted consistently
+func (s *XService) Converter(ctx context.Context, from, to string, at time.Time) (func(m money.Money) money.Money, *big.Rat, error) {
+	rate, err := s.ExchangeRate(ctx, from, to, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conv := func(m money.Money) money.Money {
+		return m.Convert(rate, to, s.Rounding)
+	}
+	return conv, rate, nil
+}
+
+func (s *XService) saveRates(ctx context.Context, list io.Reader, format Format) (int, error) {
+	rates, err := ParseRates(list, format)
+	if err != nil {
+		return 0, err
+	}
+
+	dbModels := make([]mysql.ExchangeRate, len(rates))
+	for i, rt := range rates {
+		dbModels[i] = mysql.ExchangeRate{
+			Base:          rt.Base,
+			Quote:         rt.Quote,
+			Rate:          money.FormatRate(rt.Rate),
+			EffectiveFrom: rt.EffectiveFrom,
+		}
+	}
+
+	if err := s.Repo.SaveExchangeRates(ctx, dbModels); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// jsonRate is JSON form of rate, rate can be number or decimal string
+type jsonRate struct {
+	Base          string      `json:"base"`
+	Quote         string      `json:"quote"`
+	Rate          json.Number `json:"rate"`
+	EffectiveFrom string      `json:"effectiveFrom"`
+}
+
+// ParseRates reads and validates rates list, CSV has columns base,quote,rate,effectiveFrom and optional header,
+// JSON is array of objects with the same fields, effective dates are in DateLayout
+func ParseRates(list io.Reader, format Format) ([]Rate, error) {
+	var records []jsonRate
+	switch format {
+	case FormatCSV:
+		r := csv.NewReader(list)
+		r.FieldsPerRecord = 4
+		r.TrimLeadingSpace = true
+		r.Comment = '#'
+		for {
+			rec, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, ErrRatesFormat
+			}
+			if len(records) == 0 && strings.EqualFold(rec[0], "base") {
+				continue
+			}
+			records = append(records, jsonRate{Base: rec[0], Quote: rec[1], Rate: json.Number(rec[2]), EffectiveFrom: rec[3]})
+			if len(records) > maxRates {
+				return nil, ErrTooManyRates
+			}
+		}
+	case FormatJSON:
+		if err := json.NewDecoder(io.LimitReader(list, maxRates*200)).Decode(&records); err != nil {
+			return nil, ErrRatesFormat
+		}
+		if len(records) > maxRates {
+			return nil, ErrTooManyRates
+		}
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if len(records) == 0 {
+		return nil, ErrNoRates
+	}
+
+	res := make([]Rate, len(records))
+	for i, rec := range records {
+		rt, err := parseRate(rec)
+		if err != nil {
+			return nil, &RateError{Index: i + 1, Err: err}
+		}
+		res[i] = rt
+	}
+	return res, nil
+}
+
+func parseRate(rec jsonRate) (Rate, error) {
+	base, err := money.ParseCurrency(strings.TrimSpace(rec.Base))
+	if err != nil {
+		return Rate{}, err
+	}
+	quote, err := money.ParseCurrency(strings.TrimSpace(rec.Quote))
+	if err != nil {
+		return Rate{}, err
+	}
+	if base == quote {
+		return Rate{}, ErrSamePair
+	}
+
+	r, err := money.ParseRate(rec.Rate.String())
+	if err != nil {
+		return Rate{}, err
+	}
+
+	// rates are stored as dates, local midnight keeps the day when it is written to db
+	from, err := time.ParseInLocation(DateLayout, strings.TrimSpace(rec.EffectiveFrom), time.Local)
+	if err != nil {
+		return Rate{}, ErrEffectiveDate
+	}
+
+	res := Rate{
+		Base:          base,
+		Quote:         quote,
+		Rate:          r,
+		EffectiveFrom: from,
+	}
+	return res, nil
+}
@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+func TestParseRates(t *testing.T) {
+	cases := []struct {
+		name   string
+		list   string
+		format Format
+		want   []string // base/quote=rate@date
+		err    error
+	}{
+		{
+			name:   "csv with header and comment",
+			list:   "base,quote,rate,effectiveFrom\n# yesterday\nusd, eur, 0.92, 2024-03-01\nEUR,JPY,161.5,2024-03-02\n",
+			format: FormatCSV,
+			want:   []string{"USD/EUR=0.92@2024-03-01", "EUR/JPY=161.5@2024-03-02"},
+		},
+		{
+			name:   "json with number and string rates",
+			list:   `[{"base":"USD","quote":"GBP","rate":0.79,"effectiveFrom":"2024-03-01"},{"base":"gbp","quote":"usd","rate":"1.2658","effectiveFrom":"2024-03-01"}]`,
+			format: FormatJSON,
+			want:   []string{"USD/GBP=0.79@2024-03-01", "GBP/USD=1.2658@2024-03-01"},
+		},
+		{name: "unknown format", list: "USD,EUR,1,2024-03-01", format: "xml", err: ErrUnknownFormat},
+		{name: "empty csv", list: "base,quote,rate,effectiveFrom\n", format: FormatCSV, err: ErrNoRates},
+		{name: "empty json", list: "[]", format: FormatJSON, err: ErrNoRates},
+		{name: "csv with missing column", list: "USD,EUR,0.92\n", format: FormatCSV, err: ErrRatesFormat},
+		{name: "malformed json", list: `{"base":"USD"}`, format: FormatJSON, err: ErrRatesFormat},
+		{name: "same currencies", list: "USD,usd,1,2024-03-01\n", format: FormatCSV, err: ErrSamePair},
+		{name: "unknown currency", list: "USD,EURO,1,2024-03-01\n", format: FormatCSV, err: money.ErrInvalidCurrency},
+		{name: "zero rate", list: "USD,EUR,0,2024-03-01\n", format: FormatCSV, err: money.ErrInvalidRate},
+		{name: "date with time", list: "USD,EUR,0.9,2024-03-01T10:00:00Z\n", format: FormatCSV, err: ErrEffectiveDate},
+	}
+
+	for _, c := range cases {
+		rates, err := ParseRates(strings.NewReader(c.list), c.format)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: ParseRates returned %v, want %v", c.name, err, c.err)
+			continue
+		}
+
+		got := make([]string, len(rates))
+		for i, r := range rates {
+			got[i] = r.Base + "/" + r.Quote + "=" + money.FormatRate(r.Rate) + "@" + r.EffectiveFrom.Format(DateLayout)
+		}
+		if strings.Join(got, " ") != strings.Join(c.want, " ") {
+			t.Errorf("%s: ParseRates = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseRatesPosition(t *testing.T) {
+	_, err := ParseRates(strings.NewReader("base,quote,rate,effectiveFrom\nUSD,EUR,0.9,2024-03-01\nUSD,EUR,-1,2024-03-02\n"), FormatCSV)
+
+	var rateErr *RateError
+	if !errors.As(err, &rateErr) || rateErr.Index != 2 || !errors.Is(err, money.ErrInvalidRate) {
+		t.Fatalf("ParseRates returned %v, want invalid rate at position 2", err)
+	}
+}
+
+// ratesRepo keeps one rate of every pair
+type ratesRepo struct {
+	Repository
+	rates []mysql.ExchangeRate
+}
+
+func (r *ratesRepo) ExchangeRate(ctx context.Context, base, quote string, at time.Time) (*mysql.ExchangeRate, error) {
+	for _, rt := range r.rates {
+		if (rt.Base == base && rt.Quote == quote) || (rt.Base == quote && rt.Quote == base) {
+			return &rt, nil
+		}
+	}
+	return nil, mysql.ErrNoRows
+}
+
+func TestExchangeRate(t *testing.T) {
+	s := &XService{Repo: &ratesRepo{rates: []mysql.ExchangeRate{
+		{Base: "USD", Quote: "EUR", Rate: "0.8"},
+		{Base: "EUR", Quote: "JPY", Rate: "160"},
+	}}, Rounding: money.RoundHalfUp}
+
+	cases := []struct {
+		from, to string
+		want     *big.Rat
+		err      error
+	}{
+		{from: "USD", to: "USD", want: big.NewRat(1, 1)},
+		{from: "USD", to: "EUR", want: big.NewRat(4, 5)},
+		{from: "EUR", to: "USD", want: big.NewRat(5, 4)},
+		{from: "JPY", to: "EUR", want: big.NewRat(1, 160)},
+		{from: "USD", to: "GBP", err: ErrNoRate},
+	}
+
+	for _, c := range cases {
+		got, err := s.ExchangeRate(context.Background(), c.from, c.to, time.Now())
+		if !errors.Is(err, c.err) || (c.want != nil && (got == nil || got.Cmp(c.want) != 0)) {
+			t.Errorf("ExchangeRate(%s, %s) = %v, %v; want %v, %v", c.from, c.to, got, err, c.want, c.err)
+		}
+	}
+
+	// all amounts of order are converted with the same rate and rounding
+	conv, rate, err := s.Converter(context.Background(), "EUR", "USD", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := conv(money.New(999, "EUR")); got != money.New(1249, "USD") || rate.Cmp(big.NewRat(5, 4)) != 0 {
+		t.Fatalf("converted 9.99 EUR to %v with rate %v, want 12.49 USD with rate 1.25", got, rate)
+	}
+}
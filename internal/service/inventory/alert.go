@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RunAlerts evaluates reorder thresholds every interval until context is canceled, zero interval disables alerts
+func (s *IService) RunAlerts(ctx context.Context) {
+	if s.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.EvaluateAlerts(ctx)
+		if err != nil {
+			log.WithFields(log.Fields{"raised": n}).WithError(err).Error("Error evaluating low stock alerts")
+			continue
+		}
+		if n > 0 {
+			log.WithFields(log.Fields{"raised": n}).Info("Low stock alerts raised")
+		}
+	}
+}
+
+// EvaluateAlerts checks available stock of all products with reorder threshold in batches, raises alert
+// for products that dropped below threshold since last evaluation and clears alerted state of products
+// that are back at threshold; returns number of raised alerts
+func (s *IService) EvaluateAlerts(ctx context.Context) (int, error) {
+	var raised int
+	var after int64
+	for {
+		rules, err := s.Repo.ReorderCandidates(ctx, after, s.BatchSize)
+		if err != nil {
+			return raised, err
+		}
+
+		for i := range rules {
+			r := &rules[i]
+			available := r.Stock - r.Reserved
+
+			switch {
+			case available < r.Threshold && !r.Alerted:
+				ok, err := s.raise(ctx, r, available)
+				if err != nil {
+					return raised, err
+				}
+				if ok {
+					raised++
+				}
+			case available >= r.Threshold && r.Alerted:
+				if err := s.Repo.ClearStockAlert(ctx, r.ID); err != nil {
+					return raised, err
+				}
+			}
+		}
+
+		if len(rules) < s.BatchSize {
+			return raised, nil
+		}
+		after = rules[len(rules)-1].ID
+	}
+}
+
+// raise adds alert to notification list of product owner and sends it to channels, delivery errors are only logged
+// since alert stays in notification list; returns false if alert was already raised by another evaluation
+func (s *IService) raise(ctx context.Context, r *mysql.ReorderCandidate, available int64) (bool, error) {
+	id, err := s.Repo.RaiseStockAlert(ctx, r.ID, mysql.StockAlert{
+		UserID:    r.UserID,
+		Barcode:   r.Barcode,
+		Available: available,
+		Threshold: r.Threshold,
+	})
+	if err != nil {
+		if errors.Is(err, mysql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	a := &Alert{
+		ID:        id,
+		Barcode:   r.Barcode,
+		Name:      r.Name,
+		Available: available,
+		Threshold: r.Threshold,
+		Created:   time.Now(),
+	}
+	to := &Recipient{
+		Login:   r.Login,
+		Address: r.Email,
+		Settings: Settings{
+			Email:         r.AlertSettings.Email,
+			Webhook:       r.AlertSettings.Webhook,
+			WebhookSecret: r.AlertSettings.WebhookSecret,
+		},
+	}
+
+	for _, ch := range s.Channels {
+		if err := ch.Notify(ctx, a, to); err != nil {
+			log.WithFields(log.Fields{
+				"channel":   ch.Name(),
+				"alert":     id,
+				"barcode":   r.Barcode,
+				"userLogin": r.Login,
+			}).WithError(err).Warn("Low stock alert wasn't delivered")
+		}
+	}
+	return true, nil
+}
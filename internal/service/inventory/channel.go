@@ -0,0 +1,198 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/provider/mail"
+)
+
+// Supported alert channels, alerts are always added to in-app notification list of user
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Mailer used to send emails
+type Mailer interface {
+	Send(ctx context.Context, msg *mail.Message) error
+}
+
+// Recipient is owner of product alert is raised for, Address is their email
+type Recipient struct {
+	Login   string
+	Address string
+	Settings
+}
+
+// Channel delivers low stock alert to recipient, channel does nothing if recipient didn't opt in to it
+type Channel interface {
+	Name() string
+	Notify(ctx context.Context, a *Alert, to *Recipient) error
+}
+
+// EmailChannel sends alerts to email address of user
+type EmailChannel struct {
+	Mailer Mailer
+	From   string
+}
+
+// Name returns name of channel
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Notify sends alert by email if user enabled email alerts
+func (c *EmailChannel) Notify(ctx context.Context, a *Alert, to *Recipient) error {
+	if !to.Email || !mail.ValidAddress(to.Address) {
+		return nil
+	}
+
+	msg := &mail.Message{
+		From:    c.From,
+		To:      to.Address,
+		Subject: fmt.Sprintf("Low stock: %s", a.Name),
+		Text: fmt.Sprintf("Available stock of %s (%s) is %d, below reorder threshold %d.\r\n",
+			a.Name, a.Barcode, a.Available, a.Threshold),
+	}
+	return c.Mailer.Send(ctx, msg)
+}
+
+// WebhookChannel posts alerts as JSON to URL set by user, body is signed with HMAC-SHA256 of user webhook secret
+// in X-Signature header; requests are sent only to public addresses and redirects aren't followed
+type WebhookChannel struct {
+	Client http.Client
+}
+
+// WebhookEvent is event name sent in webhook body
+const WebhookEvent = "stock.low"
+
+var (
+	ErrWebhookStatus  = errors.New("webhook responded with unexpected status")
+	ErrWebhookAddress = errors.New("webhook address isn't public")
+)
+
+type webhookPayload struct {
+	Event     string    `json:"event"`
+	ID        int64     `json:"id"`
+	Barcode   string    `json:"barcode"`
+	Name      string    `json:"name"`
+	Available int64     `json:"available"`
+	Threshold int64     `json:"threshold"`
+	Created   time.Time `json:"created"`
+}
+
+// NewWebhookChannel returns webhook channel with given request timeout, every connection is checked
+// after host is resolved so webhook can't reach private network or the server itself
+func NewWebhookChannel(timeout time.Duration) *WebhookChannel {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddress, host)
+			}
+			return nil
+		},
+	}
+
+	return &WebhookChannel{
+		Client: http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// PublicIP reports if ip is public unicast address, loopback, private, link-local, multicast
+// and unspecified addresses aren't
+func PublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedNets are special purpose ranges that aren't covered by net.IP methods
+var reservedNets = func() []*net.IPNet {
+	var res []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		res = append(res, n)
+	}
+	return res
+}()
+
+// Name returns name of channel
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Notify posts alert to webhook of user if it's set, any non 2xx response(redirects too) is error
+func (c *WebhookChannel) Notify(ctx context.Context, a *Alert, to *Recipient) error {
+	if to.Webhook == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:     WebhookEvent,
+		ID:        a.ID,
+		Barcode:   a.Barcode,
+		Name:      a.Name,
+		Available: a.Available,
+		Threshold: a.Threshold,
+		Created:   a.Created,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if to.WebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(to.WebhookSecret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %d", ErrWebhookStatus, resp.StatusCode)
+	}
+	return nil
+}
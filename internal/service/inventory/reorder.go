@@ -0,0 +1,99 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+)
+
+// MaxReportDays is max length of sales window and of period reorder suggestions cover
+const MaxReportDays = 365
+
+var ErrReportDays = errors.New("number of days should be in range 1-365")
+
+// Report is reorder suggestions for products of user based on sales of last Days days, suggested quantities
+// should keep products above their thresholds for next Cover days
+type Report struct {
+	Days  int
+	Cover int
+	Since time.Time
+	Lines []ReorderLine
+}
+
+// ReorderLine is reorder suggestion for product, Velocity is average quantity sold per day and Suggested
+// is quantity to order; Threshold is zero for products without reorder rule
+type ReorderLine struct {
+	Barcode   string
+	Name      string
+	Threshold int64
+	Stock     int64
+	Reserved  int64
+	Available int64
+	Sold      int64
+	Velocity  float64
+	Suggested int64
+}
+
+// Reorder returns reorder suggestions for user products that have reorder threshold or were sold within last days,
+// zero days and cover fall back to configured defaults; lines with largest suggested quantity go first
+func (s *IService) Reorder(ctx context.Context, days, cover int, login string) (*Report, error) {
+	if days == 0 {
+		days = s.SalesDays
+	}
+	if cover == 0 {
+		cover = s.CoverDays
+	}
+	if days < 1 || days > MaxReportDays || cover < 1 || cover > MaxReportDays {
+		return nil, ErrReportDays
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	lines, err := s.Repo.ReorderReport(ctx, string(stock.KindSale), since, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Report{
+		Days:  days,
+		Cover: cover,
+		Since: since,
+		Lines: make([]ReorderLine, len(lines)),
+	}
+	for i, l := range lines {
+		line := ReorderLine{
+			Barcode:   l.Barcode,
+			Name:      l.Name,
+			Threshold: l.Threshold.Int64,
+			Stock:     l.Stock,
+			Reserved:  l.Reserved,
+			Available: l.Stock - l.Reserved,
+			Sold:      l.Sold,
+			Velocity:  float64(l.Sold) / float64(days),
+		}
+		line.Suggested = Suggest(line.Sold, days, cover, line.Threshold, line.Available)
+		res.Lines[i] = line
+	}
+
+	sort.SliceStable(res.Lines, func(i, j int) bool {
+		return res.Lines[i].Suggested > res.Lines[j].Suggested
+	})
+	return res, nil
+}
+
+// Suggest returns quantity to order so available stock covers sales expected in next cover days at velocity
+// of sold quantity per days and still stays at threshold, expected sales are rounded up
+func Suggest(sold int64, days, cover int, threshold, available int64) int64 {
+	var expected int64
+	if sold > 0 {
+		expected = (sold*int64(cover) + int64(days) - 1) / int64(days)
+	}
+
+	n := expected + threshold - available
+	if n < 0 {
+		return 0
+	}
+	return n
+}
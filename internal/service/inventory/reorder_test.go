@@ -0,0 +1,32 @@
+package inventory
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		name                 string
+		sold                 int64
+		days, cover          int
+		threshold, available int64
+		want                 int64
+	}{
+		{"no sales above threshold", 0, 30, 14, 5, 10, 0},
+		{"no sales at threshold", 0, 30, 14, 5, 5, 0},
+		{"no sales below threshold", 0, 30, 14, 5, 2, 3},
+		{"sales cover exactly", 30, 30, 14, 0, 14, 0},
+		{"sales without stock", 30, 30, 14, 0, 0, 14},
+		{"sales and threshold", 30, 30, 14, 5, 4, 15},
+		{"expected sales rounded up", 10, 30, 14, 0, 0, 5},
+		{"single sale", 1, 365, 1, 0, 0, 1},
+		{"negative available", 0, 30, 14, 5, -3, 8},
+		{"reserved beyond stock", 60, 30, 7, 0, -2, 16},
+		{"overstocked", 300, 30, 7, 10, 1000, 0},
+	}
+
+	for _, c := range cases {
+		if got := Suggest(c.sold, c.days, c.cover, c.threshold, c.available); got != c.want {
+			t.Errorf("%s: Suggest(%d, %d, %d, %d, %d) = %d, want %d",
+				c.name, c.sold, c.days, c.cover, c.threshold, c.available, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package inventory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	SetReorderRule(ctx context.Context, barcode string, threshold int64, login string) error
+	DeleteReorderRule(ctx context.Context, barcode string, login string) error
+	ReorderRules(ctx context.Context, login string) ([]mysql.ReorderRule, error)
+	ReorderCandidates(ctx context.Context, afterID int64, limit int) ([]mysql.ReorderCandidate, error)
+	RaiseStockAlert(ctx context.Context, ruleID int64, a mysql.StockAlert) (int64, error)
+	ClearStockAlert(ctx context.Context, ruleID int64) error
+	StockAlerts(ctx context.Context, unread bool, amount int, offset int, login string) ([]mysql.StockAlert, error)
+	ReadStockAlert(ctx context.Context, id int64, login string) error
+	AlertSettings(ctx context.Context, login string) (*mysql.AlertSettings, error)
+	UpdateAlertSettings(ctx context.Context, s mysql.AlertSettings, login string) error
+	ReorderReport(ctx context.Context, kind string, since time.Time, login string) ([]mysql.ReorderLine, error)
+}
+
+var (
+	ErrThreshold  = errors.New("reorder threshold is out of range")
+	ErrWebhookURL = errors.New("webhook should be absolute http or https URL of public host")
+)
+
+// Rule is reorder threshold of product with its current stock, low stock alert is raised when available stock
+// drops below threshold and Alerted stays set until it's back at threshold
+type Rule struct {
+	Barcode   string
+	Name      string
+	Threshold int64
+	Stock     int64
+	Reserved  int64
+	Available int64
+	Alerted   bool
+	Updated   time.Time
+}
+
+// Alert is low stock alert in notification list of user
+type Alert struct {
+	ID        int64
+	Barcode   string
+	Name      string
+	Available int64
+	Threshold int64
+	Read      bool
+	Created   time.Time
+}
+
+// Settings is channels low stock alerts of user are sent to besides notification list, Webhook is empty
+// when alerts aren't posted to webhook; WebhookSecret signs webhook requests and is returned by every update
+type Settings struct {
+	Email         bool
+	Webhook       string
+	WebhookSecret string
+}
+
+// IService struct implements low stock alerts and reorder suggestions
+type IService struct {
+	Repo      Repository
+	Channels  []Channel
+	Interval  time.Duration
+	BatchSize int
+	SalesDays int
+	CoverDays int
+}
+
+// NewService returns inventory service, mailer is nil if emails aren't sent,
+// email channel is skipped without mailer and unknown channels are skipped with warning
+func NewService(repo Repository, mailer Mailer, cfg *config.Alerts) *IService {
+	s := &IService{
+		Repo:      repo,
+		Interval:  cfg.Interval,
+		BatchSize: cfg.BatchSize,
+		SalesDays: cfg.SalesDays,
+		CoverDays: cfg.CoverDays,
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = 500
+	}
+	if s.SalesDays <= 0 || s.SalesDays > MaxReportDays {
+		s.SalesDays = 30
+	}
+	if s.CoverDays <= 0 || s.CoverDays > MaxReportDays {
+		s.CoverDays = 14
+	}
+
+	for _, name := range cfg.Channels {
+		switch name {
+		case ChannelEmail:
+			if mailer == nil {
+				log.WithFields(log.Fields{"channel": name}).Warn("Mail is disabled, low stock alerts aren't sent by email")
+				continue
+			}
+			s.Channels = append(s.Channels, &EmailChannel{Mailer: mailer, From: cfg.From})
+		case ChannelWebhook:
+			timeout := cfg.WebhookTimeout
+			if timeout <= 0 {
+				timeout = 10 * time.Second
+			}
+			s.Channels = append(s.Channels, NewWebhookChannel(timeout))
+		default:
+			log.WithFields(log.Fields{"channel": name}).Warn("Unknown alert channel is skipped")
+		}
+	}
+	return s
+}
+
+// SetRule sets reorder threshold of user product, alert is raised again if product is still below new threshold
+func (s *IService) SetRule(ctx context.Context, barcode string, threshold int64, login string) error {
+	if threshold < 0 {
+		return ErrThreshold
+	}
+	return s.Repo.SetReorderRule(ctx, barcode, threshold, login)
+}
+
+// DeleteRule removes reorder threshold of user product, its alerts stay in notification list
+func (s *IService) DeleteRule(ctx context.Context, barcode string, login string) error {
+	return s.Repo.DeleteReorderRule(ctx, barcode, login)
+}
+
+// Rules returns reorder thresholds of user products with their current stock
+func (s *IService) Rules(ctx context.Context, login string) ([]Rule, error) {
+	rules, err := s.Repo.ReorderRules(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Rule, len(rules))
+	for i, r := range rules {
+		res[i] = Rule{
+			Barcode:   r.Barcode,
+			Name:      r.Name,
+			Threshold: r.Threshold,
+			Stock:     r.Stock,
+			Reserved:  r.Reserved,
+			Available: r.Stock - r.Reserved,
+			Alerted:   r.Alerted,
+			Updated:   r.Updated,
+		}
+	}
+	return res, nil
+}
+
+// Alerts returns low stock alerts of user, newest first, only unread ones if unread is set
+// alertsPerPage - number of alerts on page
+// page - next page with alerts
+func (s *IService) Alerts(ctx context.Context, unread bool, page, alertsPerPage int, login string) ([]Alert, error) {
+	alerts, err := s.Repo.StockAlerts(ctx, unread, alertsPerPage, (page-1)*alertsPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Alert, len(alerts))
+	for i, a := range alerts {
+		res[i] = newAlert(a)
+	}
+	return res, nil
+}
+
+// ReadAlert marks low stock alert of user as read
+func (s *IService) ReadAlert(ctx context.Context, id int64, login string) error {
+	return s.Repo.ReadStockAlert(ctx, id, login)
+}
+
+// AlertSettings returns channels low stock alerts of user are sent to
+func (s *IService) AlertSettings(ctx context.Context, login string) (*Settings, error) {
+	st, err := s.Repo.AlertSettings(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return &Settings{Email: st.Email, Webhook: st.Webhook}, nil
+}
+
+// UpdateAlertSettings saves channels low stock alerts of user are sent to, webhook should be absolute http(s) URL
+// of public host; new webhook gets new secret that is returned in settings, secret of unchanged webhook is kept
+func (s *IService) UpdateAlertSettings(ctx context.Context, st Settings, login string) (*Settings, error) {
+	if st.Webhook != "" {
+		u, err := url.Parse(st.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+			return nil, ErrWebhookURL
+		}
+		// hosts resolved to private addresses are rejected when alert is sent
+		if ip := net.ParseIP(u.Hostname()); (ip != nil && !PublicIP(ip)) || strings.EqualFold(u.Hostname(), "localhost") {
+			return nil, ErrWebhookURL
+		}
+	}
+
+	cur, err := s.Repo.AlertSettings(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := cur.WebhookSecret
+	switch {
+	case st.Webhook == "":
+		secret = ""
+	case st.Webhook != cur.Webhook || secret == "":
+		secret, err = newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Repo.UpdateAlertSettings(ctx, mysql.AlertSettings{Email: st.Email, Webhook: st.Webhook, WebhookSecret: secret}, login)
+	if err != nil {
+		return nil, err
+	}
+	return &Settings{Email: st.Email, Webhook: st.Webhook, WebhookSecret: secret}, nil
+}
+
+// newWebhookSecret returns random hex encoded 32 byte secret
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newAlert(a mysql.StockAlert) Alert {
+	return Alert{
+		ID:        a.ID,
+		Barcode:   a.Barcode,
+		Name:      a.Name,
+		Available: a.Available,
+		Threshold: a.Threshold,
+		Read:      a.Read,
+		Created:   a.Created,
+	}
+}
@@ -0,0 +1,186 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/worker"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	CreateJob(ctx context.Context, j mysql.Job, login string) error
+	QueuedJobs(ctx context.Context, login string) (int, error)
+	ClaimJob(ctx context.Context, owner string, lease time.Duration) (*mysql.Job, error)
+	ExtendJobLease(ctx context.Context, id string, owner string, lease time.Duration) error
+	FinishJob(ctx context.Context, id string, owner string, filename string) error
+	RetryJob(ctx context.Context, id string, owner string, errMsg string, delay time.Duration) error
+	FailJob(ctx context.Context, id string, owner string, errMsg string) error
+	RequeueExpiredJobs(ctx context.Context) (int64, error)
+	UserJob(ctx context.Context, id string, login string) (*mysql.Job, error)
+}
+
+// CheckCreator used to generate checks
+type CheckCreator interface {
+	CreateCheck(ctx context.Context, barcode string, tplName string, login string) (string, error)
+}
+
+var ErrQueueFull = errors.New("user has too many jobs waiting in queue")
+
+// JService struct implements asynchronous check generation functionality, jobs are executed on worker pool
+type JService struct {
+	Repo             Repository
+	Checks           CheckCreator
+	MaxQueuedPerUser int
+	Pool             *worker.Pool
+}
+
+// Job is service level model of check generation job
+type Job struct {
+	ID       string
+	Barcode  string
+	Template string
+	Status   string
+	Attempts int
+	FileName string
+	Error    string
+	Created  time.Time
+	Updated  time.Time
+}
+
+func NewService(repo Repository, checks CheckCreator, cfg *config.Jobs) *JService {
+	s := &JService{
+		Repo:             repo,
+		Checks:           checks,
+		MaxQueuedPerUser: cfg.MaxQueuedPerUser,
+	}
+	s.Pool = worker.NewPool("jobs", &queue{s: s}, worker.Settings{
+		Workers:      cfg.Workers,
+		MaxAttempts:  cfg.MaxAttempts,
+		Backoff:      cfg.Backoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		PollInterval: cfg.PollInterval,
+		Lease:        cfg.Lease,
+	})
+	return s
+}
+
+// EnqueueCheck persists job generating check for product and returns its id,
+// returns ErrQueueFull if user has max number of jobs waiting in queue
+func (s *JService) EnqueueCheck(ctx context.Context, barcode string, tplName string, login string) (string, error) {
+	queued, err := s.Repo.QueuedJobs(ctx, login)
+	if err != nil {
+		return "", err
+	}
+
+	if queued >= s.MaxQueuedPerUser {
+		return "", ErrQueueFull
+	}
+
+	j := mysql.Job{
+		ID:       uuid.NewString(),
+		Barcode:  barcode,
+		Template: tplName,
+	}
+
+	if err := s.Repo.CreateJob(ctx, j, login); err != nil {
+		return "", err
+	}
+
+	s.Pool.WakeUp()
+
+	return j.ID, nil
+}
+
+// UserJob returns job created by user
+func (s *JService) UserJob(ctx context.Context, id string, login string) (*Job, error) {
+	j, err := s.Repo.UserJob(ctx, id, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Job{
+		ID:       j.ID,
+		Barcode:  j.Barcode,
+		Template: j.Template,
+		Status:   j.Status,
+		Attempts: j.Attempts,
+		FileName: j.FileName.String,
+		Error:    j.Error,
+		Created:  j.Created,
+		Updated:  j.Updated,
+	}
+	return res, nil
+}
+
+// RunJobs executes queued jobs on pool of workers until context is canceled
+func (s *JService) RunJobs(ctx context.Context) {
+	s.Pool.Run(ctx)
+}
+
+// queue is queue of check generation jobs
+type queue struct {
+	s *JService
+}
+
+func (q *queue) Claim(ctx context.Context, owner string, lease time.Duration) (worker.Task, error) {
+	j, err := q.s.Repo.ClaimJob(ctx, owner, lease)
+	if err != nil {
+		return nil, err
+	}
+	return &task{s: q.s, j: j}, nil
+}
+
+func (q *queue) RequeueExpired(ctx context.Context) (int64, error) {
+	return q.s.Repo.RequeueExpiredJobs(ctx)
+}
+
+// Permanent reports whether job can't succeed on retry: product or template was deleted or check can't be rendered
+// with its data and template, errors of db and check store are retried
+func (q *queue) Permanent(err error) bool {
+	var renderErr *render.Error
+	return errors.Is(err, mysql.ErrNoRows) || errors.As(err, &renderErr)
+}
+
+// task is claimed check generation job, fileName is name of generated check
+type task struct {
+	s        *JService
+	j        *mysql.Job
+	fileName string
+}
+
+func (t *task) Run(ctx context.Context) (err error) {
+	t.fileName, err = t.s.Checks.CreateCheck(ctx, t.j.Barcode, t.j.Template, t.j.Login)
+	return err
+}
+
+func (t *task) Attempts() int {
+	return t.j.Attempts
+}
+
+func (t *task) Fields() log.Fields {
+	return log.Fields{"job": t.j.ID, "barcode": t.j.Barcode, "userLogin": t.j.Login}
+}
+
+func (t *task) Extend(ctx context.Context, owner string, lease time.Duration) error {
+	return t.s.Repo.ExtendJobLease(ctx, t.j.ID, owner, lease)
+}
+
+func (t *task) Finish(ctx context.Context, owner string) error {
+	return t.s.Repo.FinishJob(ctx, t.j.ID, owner, t.fileName)
+}
+
+func (t *task) Retry(ctx context.Context, owner string, errMsg string, delay time.Duration) error {
+	return t.s.Repo.RetryJob(ctx, t.j.ID, owner, errMsg, delay)
+}
+
+func (t *task) Fail(ctx context.Context, owner string, errMsg string) error {
+	return t.s.Repo.FailJob(ctx, t.j.ID, owner, errMsg)
+}
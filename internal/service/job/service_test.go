@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+)
+
+// jobsRepo keeps queued jobs by user login
+type jobsRepo struct {
+	Repository
+	queued map[string]int
+}
+
+func (r *jobsRepo) QueuedJobs(ctx context.Context, login string) (int, error) {
+	return r.queued[login], nil
+}
+
+func (r *jobsRepo) CreateJob(ctx context.Context, j mysql.Job, login string) error {
+	r.queued[login]++
+	return nil
+}
+
+func TestEnqueueCheckPerUser(t *testing.T) {
+	repo := &jobsRepo{queued: map[string]int{}}
+	s := NewService(repo, nil, &config.Jobs{MaxQueuedPerUser: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.EnqueueCheck(ctx, "1234567890", "", "busy"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.EnqueueCheck(ctx, "1234567890", "", "busy"); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("job over user limit returned %v, want ErrQueueFull", err)
+	}
+
+	// jobs of other users don't take place of user in queue
+	id, err := s.EnqueueCheck(ctx, "1234567890", "", "other")
+	if err != nil || id == "" {
+		t.Fatalf("job of other user returned %q, %v", id, err)
+	}
+}
+
+func TestQueuePermanent(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{err: mysql.ErrNoRows, want: true},
+		{err: fmt.Errorf("template: %w", mysql.ErrNoRows), want: true},
+		{err: &render.Error{Err: render.ErrInvalidLayout}, want: true},
+		{err: &render.Error{Err: errors.New("font has no glyph")}, want: true},
+		{err: errors.New("connection refused")},
+		{err: context.DeadlineExceeded},
+	}
+
+	q := &queue{}
+	for _, c := range cases {
+		if got := q.Permanent(c.err); got != c.want {
+			t.Errorf("Permanent(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
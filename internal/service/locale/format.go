@@ -0,0 +1,98 @@
+package locale
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/number"
+)
+
+// dateLayouts holds short date and time layouts of supported languages,
+// the first one is used for languages without own layout
+var dateLayouts = []struct {
+	tag    language.Tag
+	layout string
+}{
+	{language.Und, "2006-01-02 15:04"},
+	{language.AmericanEnglish, "1/2/2006 3:04 PM"},
+	{language.BritishEnglish, "02/01/2006 15:04"},
+	{language.German, "02.01.2006 15:04"},
+	{language.Russian, "02.01.2006 15:04"},
+	{language.Ukrainian, "02.01.2006 15:04"},
+	{language.Polish, "02.01.2006 15:04"},
+	{language.Czech, "02.01.2006 15:04"},
+	{language.Turkish, "02.01.2006 15:04"},
+	{language.French, "02/01/2006 15:04"},
+	{language.Spanish, "02/01/2006 15:04"},
+	{language.Italian, "02/01/2006 15:04"},
+	{language.Portuguese, "02/01/2006 15:04"},
+	{language.Japanese, "2006/01/02 15:04"},
+	{language.Chinese, "2006/01/02 15:04"},
+	{language.Korean, "2006. 1. 2. 15:04"},
+}
+
+var dateMatcher = func() language.Matcher {
+	tags := make([]language.Tag, len(dateLayouts))
+	for i := range dateLayouts {
+		tags[i] = dateLayouts[i].tag
+	}
+	return language.NewMatcher(tags)
+}()
+
+// symbolAfter holds languages which put currency symbol after amount
+var symbolAfter = map[language.Base]bool{}
+
+func init() {
+	for _, t := range []language.Tag{
+		language.German, language.French, language.Russian, language.Ukrainian, language.Polish,
+		language.Czech, language.Spanish, language.Italian, language.Swedish, language.Finnish,
+	} {
+		b, _ := t.Base()
+		symbolAfter[b] = true
+	}
+}
+
+// Money formats amount in its own currency with locale grouping and decimal separators,
+// amounts without currency are formatted in user currency
+func (s *Settings) Money(m money.Money) string {
+	unit := s.Currency
+	if m.Currency != "" {
+		if u, err := currency.ParseISO(m.Currency); err == nil {
+			unit = u
+		}
+	}
+
+	res := s.printer.Sprint(currency.Symbol(unit.Amount(m.Float())))
+
+	b, _ := s.Language.Base()
+	if symbolAfter[b] {
+		if i := strings.IndexByte(res, ' '); i > 0 {
+			res = res[i+1:] + " " + res[:i]
+		}
+	}
+
+	return res
+}
+
+// Number formats number with locale grouping and decimal separators
+func (s *Settings) Number(n interface{}) string {
+	return s.printer.Sprint(number.Decimal(n))
+}
+
+// Percent formats rate given in basis points(hundredths of percent)
+func (s *Settings) Percent(bp int) string {
+	return s.printer.Sprint(number.Percent(float64(bp)/10000, number.MaxFractionDigits(2)))
+}
+
+// Date formats time in user time zone using short date layout of user language
+func (s *Settings) Date(t time.Time) string {
+	_, i, conf := dateMatcher.Match(s.Language)
+	if conf == language.No {
+		i = 0
+	}
+	return t.In(s.Location).Format(dateLayouts[i].layout)
+}
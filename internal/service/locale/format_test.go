@@ -0,0 +1,84 @@
+package locale
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		locale, cur, tz string
+		want            string
+		err             error
+	}{
+		{want: "en-US USD UTC"},
+		{locale: "de-DE", cur: "EUR", tz: "Europe/Berlin", want: "de-DE EUR Europe/Berlin"},
+		{cur: "eur", want: "en-US EUR UTC"},
+		{locale: "!!", err: ErrInvalidLocale},
+		{cur: "EURO", err: ErrInvalidCurrency},
+		{tz: "Mars/Base", err: ErrInvalidTimeZone},
+	}
+
+	for _, c := range cases {
+		s, err := Parse(c.locale, c.cur, c.tz)
+		if !errors.Is(err, c.err) {
+			t.Errorf("Parse(%q, %q, %q) returned %v, want %v", c.locale, c.cur, c.tz, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := s.Language.String() + " " + s.Currency.String() + " " + s.Location.String(); got != c.want {
+			t.Errorf("Parse(%q, %q, %q) = %s, want %s", c.locale, c.cur, c.tz, got, c.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// time is formatted in user time zone
+	date := time.Date(2024, 3, 5, 22, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		locale  string
+		money   string // amount without currency
+		foreign string // amount in USD
+		number  string
+		percent string
+		date    string
+	}{
+		{locale: "en-US", money: "€ 1,234.56", foreign: "$ 1,234.56", number: "1,234,567.5", percent: "19.5%", date: "3/5/2024 11:30 PM"},
+		{locale: "en-GB", money: "€ 1,234.56", foreign: "US$ 1,234.56", number: "1,234,567.5", percent: "19.5%", date: "05/03/2024 23:30"},
+		{locale: "de-DE", money: "1.234,56\u00a0€", foreign: "1.234,56\u00a0$", number: "1.234.567,5", percent: "19,5\u00a0%", date: "05.03.2024 23:30"},
+		{locale: "fr", money: "1\u00a0234,56\u00a0€", foreign: "1\u00a0234,56\u00a0$US", number: "1\u00a0234\u00a0567,5", percent: "19,5\u00a0%", date: "05/03/2024 23:30"},
+		{locale: "ru", money: "1\u00a0234,56\u00a0€", foreign: "1\u00a0234,56\u00a0$", number: "1\u00a0234\u00a0567,5", percent: "19,5\u00a0%", date: "05.03.2024 23:30"},
+		{locale: "ja", money: "€ 1,234.56", foreign: "$ 1,234.56", number: "1,234,567.5", percent: "19.5%", date: "2024/03/05 23:30"},
+		// region falls back to date layout of its language
+		{locale: "pt-BR", money: "€ 1.234,56", foreign: "US$ 1.234,56", number: "1.234.567,5", percent: "19,5%", date: "05/03/2024 23:30"},
+		// language without own date layout
+		{locale: "nl", money: "€ 1.234,56", foreign: "US$ 1.234,56", number: "1.234.567,5", percent: "19,5%", date: "2024-03-05 23:30"},
+	}
+
+	for _, c := range cases {
+		s, err := Parse(c.locale, "EUR", "Europe/Berlin")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []string{
+			s.Money(money.New(123456, "")),
+			s.Money(money.New(123456, "USD")),
+			s.Number(1234567.5),
+			s.Percent(1950),
+			s.Date(date),
+		}
+		want := []string{c.money, c.foreign, c.number, c.percent, c.date}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %q, want %q", c.locale, got[i], want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package locale
+
+import (
+	"errors"
+	"time"
+	// time zones are loaded from binary, runtime image has no tzdata
+	_ "time/tzdata"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Default settings of new users
+const (
+	DefaultLocale   = "en-US"
+	DefaultCurrency = "USD"
+	DefaultTimeZone = "UTC"
+)
+
+var (
+	ErrInvalidLocale   = errors.New("locale is not valid BCP 47 language tag")
+	ErrInvalidCurrency = errors.New("currency is not valid ISO 4217 code")
+	ErrInvalidTimeZone = errors.New("time zone is not valid IANA time zone name")
+)
+
+// Settings hold user language, currency and time zone used to format checks
+type Settings struct {
+	Language language.Tag
+	Currency currency.Unit
+	Location *time.Location
+	printer  *message.Printer
+}
+
+// Parse validates user settings, empty values are replaced with defaults
+func Parse(locale, cur, tz string) (*Settings, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	if cur == "" {
+		cur = DefaultCurrency
+	}
+	if tz == "" {
+		tz = DefaultTimeZone
+	}
+
+	lang, err := language.Parse(locale)
+	if err != nil {
+		return nil, ErrInvalidLocale
+	}
+
+	unit, err := currency.ParseISO(cur)
+	if err != nil {
+		return nil, ErrInvalidCurrency
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	s := &Settings{
+		Language: lang,
+		Currency: unit,
+		Location: loc,
+		printer:  message.NewPrinter(lang),
+	}
+	return s, nil
+}
+
+// Default returns settings used when user has no settings
+func Default() *Settings {
+	s, _ := Parse(DefaultLocale, DefaultCurrency, DefaultTimeZone)
+	return s
+}
@@ -0,0 +1,109 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
+)
+
+// Templates used to choose template for receipt
+type Templates interface {
+	ResolveTemplate(ctx context.Context, name string, version int, login string) (*template.Template, error)
+}
+
+// Locales used to get user settings for formatting receipts
+type Locales interface {
+	Locale(ctx context.Context, login string) (*locale.Settings, error)
+}
+
+// ReceiptRenderer used to draw order receipt over check template
+type ReceiptRenderer interface {
+	RenderReceipt(w io.Writer, p *render.Page, rc *render.Receipt) error
+	ContentType() string
+}
+
+// Receipt renders PDF receipt of user order with all lines and totals using chosen check template
+// (empty name means user default), lines that don't fit template page are continued on next pages
+func (s *OService) Receipt(ctx context.Context, id int64, tplName string, login string) (*product.CheckFile, error) {
+	ord, err := s.UserOrder(ctx, id, "", login)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, err := s.TplResolver.ResolveTemplate(ctx, tplName, 0, login)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := s.Locales.Locale(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	seller, err := s.Repo.UserSeller(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := s.VATRate
+	if seller.VATRate.Valid {
+		rate = int(seller.VATRate.Int64)
+	}
+	// prices include VAT, tax is part of grand total
+	_, tax := product.VATAmounts(ord.Total.Amount, rate)
+
+	rc := &render.Receipt{
+		Title:    "#" + strconv.FormatInt(ord.ID, 10),
+		Date:     st.Date(ord.Created),
+		Language: st.Language,
+		Lines:    make([]render.ReceiptLine, len(ord.Items)),
+		Subtotal: st.Money(ord.Subtotal),
+		Tax:      st.Percent(rate) + " " + st.Money(money.New(tax, ord.Total.Currency)),
+		Total:    st.Money(ord.Total),
+		Seller:   seller.Name,
+		TaxID:    seller.TaxID,
+		Address:  seller.Address,
+	}
+	for _, d := range ord.Discounts {
+		if d.Amount.Amount > 0 {
+			rc.Discounts = append(rc.Discounts, render.ReceiptDiscount{
+				Code:   d.Code,
+				Amount: st.Money(d.Amount),
+			})
+		}
+	}
+	for i, it := range ord.Items {
+		rc.Lines[i] = render.ReceiptLine{
+			Name:     it.ProductName,
+			Quantity: st.Number(it.Quantity),
+			Price:    st.Money(it.Price),
+			Total:    st.Money(it.Total()),
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.Receipts.RenderReceipt(&buf, &tpl.Page, rc); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	res := &product.CheckFile{
+		Name:        fmt.Sprintf("order_%d.pdf", ord.ID),
+		ContentType: s.Receipts.ContentType(),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Modified:    ord.Updated,
+		Content:     store.NewBytesObject(buf.Bytes()),
+	}
+	return res, nil
+}
@@ -0,0 +1,316 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	CreateOrder(ctx context.Context, status string, customer string, items []mysql.OrderItem, codes []string,
+		price func(items []mysql.OrderItem, coupons []mysql.CouponUsage) ([]mysql.OrderDiscount, error), login string) (int64, error)
+	UserOrders(ctx context.Context, amount int, offset int, login string) ([]mysql.Order, error)
+	UserOrder(ctx context.Context, id int64, login string) (*mysql.Order, []mysql.OrderItem, error)
+	OrderEvents(ctx context.Context, orderID int64) ([]mysql.OrderEvent, error)
+	OrderDiscounts(ctx context.Context, orderID int64) ([]mysql.OrderDiscount, error)
+	UserSeller(ctx context.Context, login string) (*mysql.Seller, error)
+	TransitionOrder(ctx context.Context, id int64, to string, releaseDiscounts bool,
+		check func(from string, st *mysql.OrderStock) (*mysql.StockChange, error), login string) error
+}
+
+// Rates used to convert amounts to other currency
+type Rates interface {
+	Converter(ctx context.Context, from, to string, at time.Time) (func(m money.Money) money.Money, *big.Rat, error)
+}
+
+var (
+	ErrNoItems       = errors.New("order should have at least one item")
+	ErrDuplicateItem = errors.New("product is listed in order more than once")
+	ErrQuantity      = errors.New("item quantity should be positive")
+	ErrCurrencies    = errors.New("order products have different currencies")
+)
+
+// Order is service level model of user order, all amounts are in currency of its products
+// or in currency they were converted to, converted order has its original total and rate
+type Order struct {
+	ID         int64
+	Customer   string
+	Status     Status
+	Subtotal   money.Money
+	Discount   money.Money
+	Total      money.Money
+	Conversion *money.Conversion
+	ItemsCount int
+	Items      []Item
+	Discounts  []Discount
+	Events     []Event
+	Created    time.Time
+	Updated    time.Time
+}
+
+// Item is order line, name and price are snapshot of product at order creation
+type Item struct {
+	Barcode     string
+	ProductName string
+	Price       money.Money
+	Quantity    int
+}
+
+// Discount is coupon applied to order with amount it took off
+type Discount struct {
+	Code   string
+	Amount money.Money
+}
+
+// Total returns cost of order line
+func (it Item) Total() money.Money {
+	return it.Price.Mul(int64(it.Quantity))
+}
+
+// OService struct implements order service functionality
+type OService struct {
+	Repo        Repository
+	TplResolver Templates
+	Locales     Locales
+	Receipts    ReceiptRenderer
+	Rates       Rates
+	Stock       stock.Fulfillment
+	VATRate     int // basis points, used when seller has no own rate
+}
+
+// NewService returns order service, receipts are rendered with templates of product checks,
+// fulfillment decides whether order can be placed when it takes more goods than there are available in stock,
+// which warehouse goods are taken from and how long placed order holds them
+func NewService(repo Repository, tpls Templates, locales Locales, receipts ReceiptRenderer, rates Rates,
+	fulfillment stock.Fulfillment, cfg *config.Product) *OService {
+	s := &OService{
+		Repo:        repo,
+		TplResolver: tpls,
+		Locales:     locales,
+		Receipts:    receipts,
+		Rates:       rates,
+		Stock:       fulfillment,
+		VATRate:     int(math.Round(cfg.VATRate * 100)),
+	}
+	return s
+}
+
+// CreateOrder creates draft order of user products with given quantities for customer and returns it with price snapshot,
+// coupons of user with given codes are applied in given order, each one to price left after previous ones,
+// uses of coupons limited per customer are counted by customer email
+func (s *OService) CreateOrder(ctx context.Context, items []Item, codes []string, customer string, login string) (*Order, error) {
+	if len(items) == 0 {
+		return nil, ErrNoItems
+	}
+
+	seen := make(map[string]bool, len(items))
+	dbItems := make([]mysql.OrderItem, len(items))
+	for i, it := range items {
+		if seen[it.Barcode] {
+			return nil, ErrDuplicateItem
+		}
+		seen[it.Barcode] = true
+
+		if it.Quantity < 1 {
+			return nil, ErrQuantity
+		}
+
+		dbItems[i] = mysql.OrderItem{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		}
+	}
+
+	if len(codes) > coupon.MaxPerOrder {
+		return nil, coupon.ErrTooManyCoupons
+	}
+
+	customer = strings.ToLower(strings.TrimSpace(customer))
+
+	normalized := make([]string, len(codes))
+	applied := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		normalized[i] = coupon.NormalizeCode(code)
+		if applied[normalized[i]] {
+			return nil, coupon.ErrDuplicateCoupon
+		}
+		applied[normalized[i]] = true
+	}
+
+	price := func(items []mysql.OrderItem, coupons []mysql.CouponUsage) ([]mysql.OrderDiscount, error) {
+		currency := items[0].Currency
+		var subtotal int64
+		for _, it := range items {
+			if it.Currency != currency {
+				return nil, ErrCurrencies
+			}
+			subtotal += it.Price * int64(it.Quantity)
+		}
+
+		byCode := make(map[string]mysql.CouponUsage, len(coupons))
+		for _, c := range coupons {
+			byCode[c.Code] = c
+		}
+
+		now := time.Now()
+		remaining := subtotal
+		discounts := make([]mysql.OrderDiscount, 0, len(normalized))
+		for _, code := range normalized {
+			c, ok := byCode[code]
+			if !ok {
+				return nil, coupon.ErrCouponNotFound
+			}
+			if c.MaxUsesPerUser.Valid && customer == "" {
+				return nil, coupon.ErrCouponCustomer
+			}
+
+			amount, err := coupon.Apply(c, currency, subtotal, remaining, now)
+			if err != nil {
+				return nil, err
+			}
+
+			remaining -= amount
+			discounts = append(discounts, mysql.OrderDiscount{
+				CouponID: c.ID,
+				Code:     c.Code,
+				Amount:   amount,
+			})
+		}
+		return discounts, nil
+	}
+
+	id, err := s.Repo.CreateOrder(ctx, string(StatusDraft), customer, dbItems, normalized, price, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserOrder(ctx, id, "", login)
+}
+
+// UserOrders returns user orders without items, newest first, amounts are converted to currency
+// with rates effective at order creation if it is set
+// ordersPerPage - number of orders on page
+// page - next page with orders
+func (s *OService) UserOrders(ctx context.Context, page, ordersPerPage int, currency string, login string) ([]Order, error) {
+	orders, err := s.Repo.UserOrders(ctx, ordersPerPage, (page-1)*ordersPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Order, len(orders))
+	for i, o := range orders {
+		res[i] = Order{
+			ID:         o.ID,
+			Customer:   o.Customer,
+			Status:     Status(o.Status),
+			Subtotal:   money.New(o.Total, o.Currency),
+			Discount:   money.New(o.Discount, o.Currency),
+			Total:      money.New(o.Total-o.Discount, o.Currency),
+			ItemsCount: o.Items,
+			Created:    o.Created,
+			Updated:    o.Updated,
+		}
+		if err := s.convert(ctx, &res[i], currency); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// UserOrder returns order with items and status history if user owns it, amounts are converted to currency
+// with rate effective at order creation if it is set, so order is shown the same way whenever it is read
+func (s *OService) UserOrder(ctx context.Context, id int64, currency string, login string) (*Order, error) {
+	o, items, err := s.Repo.UserOrder(ctx, id, login)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.Repo.OrderEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, err := s.Repo.OrderDiscounts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Order{
+		ID:         o.ID,
+		Customer:   o.Customer,
+		Status:     Status(o.Status),
+		Subtotal:   money.New(o.Total, o.Currency),
+		Discount:   money.New(o.Discount, o.Currency),
+		Total:      money.New(o.Total-o.Discount, o.Currency),
+		ItemsCount: o.Items,
+		Items:      make([]Item, len(items)),
+		Events:     make([]Event, len(events)),
+		Discounts:  make([]Discount, len(discounts)),
+		Created:    o.Created,
+		Updated:    o.Updated,
+	}
+	for i, d := range discounts {
+		res.Discounts[i] = Discount{
+			Code:   d.Code,
+			Amount: money.New(d.Amount, o.Currency),
+		}
+	}
+	for i, e := range events {
+		res.Events[i] = Event{
+			From:    Status(e.FromStatus),
+			To:      Status(e.ToStatus),
+			Actor:   e.Actor,
+			Created: e.Created,
+		}
+	}
+	for i, it := range items {
+		res.Items[i] = Item{
+			Barcode:     it.Barcode,
+			ProductName: it.ProductName,
+			Price:       money.New(it.Price, o.Currency),
+			Quantity:    it.Quantity,
+		}
+	}
+
+	if err := s.convert(ctx, res, currency); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// convert converts amounts of order to currency, total is converted subtotal minus converted discount
+// and item prices are converted one by one, so converted lines may not sum up to converted subtotal
+func (s *OService) convert(ctx context.Context, o *Order, currency string) error {
+	if currency == "" || currency == o.Total.Currency {
+		return nil
+	}
+
+	conv, rate, err := s.Rates.Converter(ctx, o.Total.Currency, currency, o.Created)
+	if err != nil {
+		return err
+	}
+
+	o.Conversion = &money.Conversion{Original: o.Total, Rate: rate}
+	o.Subtotal = conv(o.Subtotal)
+	o.Discount = conv(o.Discount)
+	o.Total = money.New(o.Subtotal.Amount-o.Discount.Amount, currency)
+	for i := range o.Items {
+		o.Items[i].Price = conv(o.Items[i].Price)
+	}
+	for i := range o.Discounts {
+		o.Discounts[i].Amount = conv(o.Discounts[i].Amount)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+)
+
+// createRepo prices order of one product with given coupon the way repository does and keeps customer order is created for
+type createRepo struct {
+	Repository
+	coupon    mysql.CouponUsage
+	customer  string
+	discounts []mysql.OrderDiscount
+}
+
+func (r *createRepo) CreateOrder(ctx context.Context, status string, customer string, items []mysql.OrderItem, codes []string,
+	price func(items []mysql.OrderItem, coupons []mysql.CouponUsage) ([]mysql.OrderDiscount, error), login string) (int64, error) {
+	r.customer = customer
+	items[0].Price, items[0].Currency = 1000, "USD"
+
+	discounts, err := price(items, []mysql.CouponUsage{r.coupon})
+	if err != nil {
+		return 0, err
+	}
+	r.discounts = discounts
+	return 1, nil
+}
+
+func (r *createRepo) UserOrder(ctx context.Context, id int64, login string) (*mysql.Order, []mysql.OrderItem, error) {
+	return &mysql.Order{ID: id, Customer: r.customer, Status: string(StatusDraft), Currency: "USD"}, nil, nil
+}
+
+func (r *createRepo) OrderEvents(ctx context.Context, orderID int64) ([]mysql.OrderEvent, error) {
+	return nil, nil
+}
+
+func (r *createRepo) OrderDiscounts(ctx context.Context, orderID int64) ([]mysql.OrderDiscount, error) {
+	return r.discounts, nil
+}
+
+func TestCreateOrderCustomer(t *testing.T) {
+	perUser := mysql.CouponUsage{Coupon: mysql.Coupon{
+		ID: 1, Code: "SAVE10", Kind: string(coupon.KindPercent), Value: 1000,
+		MaxUsesPerUser: sql.NullInt64{Int64: 1, Valid: true}, Created: time.Now(),
+	}}
+	unlimited := perUser
+	unlimited.MaxUsesPerUser = sql.NullInt64{}
+	usedUp := perUser
+	usedUp.UserUses = 1
+
+	cases := []struct {
+		name     string
+		coupon   mysql.CouponUsage
+		customer string
+		stored   string
+		err      error
+	}{
+		{name: "normalized customer", coupon: perUser, customer: " Buyer@Test.com ", stored: "buyer@test.com"},
+		{name: "per customer coupon without customer", coupon: perUser, err: coupon.ErrCouponCustomer},
+		{name: "coupon without per customer limit", coupon: unlimited},
+		{name: "customer used coupon up", coupon: usedUp, customer: "buyer@test.com", err: coupon.ErrCouponPerUser},
+	}
+
+	for _, c := range cases {
+		repo := &createRepo{coupon: c.coupon}
+		s := &OService{Repo: repo}
+
+		o, err := s.CreateOrder(context.Background(), []Item{{Barcode: "1234567890", Quantity: 1}}, []string{"save10"}, c.customer, "seller")
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: CreateOrder returned %v, want %v", c.name, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if repo.customer != c.stored || o.Customer != c.stored {
+			t.Errorf("%s: order is created for %q and returned for %q, want %q", c.name, repo.customer, o.Customer, c.stored)
+		}
+		if len(o.Discounts) != 1 || o.Discounts[0].Amount.Amount != 100 {
+			t.Errorf("%s: order discounts = %+v, want 10%% of 1000", c.name, o.Discounts)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+)
+
+// Status is state of order in its lifecycle
+type Status string
+
+// Order statuses
+const (
+	StatusDraft     Status = "draft"
+	StatusPlaced    Status = "placed"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown order status")
+	ErrIllegalTransition = errors.New("order can't be moved to requested status")
+)
+
+// transitions lists statuses order can be moved to from every status, cancelled and refunded orders are final
+var transitions = map[Status][]Status{
+	StatusDraft:     {StatusPlaced, StatusCancelled},
+	StatusPlaced:    {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusCompleted, StatusRefunded},
+	StatusCompleted: {StatusRefunded},
+	StatusCancelled: nil,
+	StatusRefunded:  nil,
+}
+
+// Event is change of order status made by actor(user login)
+type Event struct {
+	From    Status
+	To      Status
+	Actor   string
+	Created time.Time
+}
+
+// Next returns statuses order can be moved to from status s
+func (s Status) Next() []Status {
+	return transitions[s]
+}
+
+// CanTransition reports whether order can be moved from status s to status to
+func (s Status) CanTransition(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseStatus returns status with given name
+func ParseStatus(name string) (Status, error) {
+	s := Status(name)
+	if _, ok := transitions[s]; !ok {
+		return "", ErrUnknownStatus
+	}
+	return s, nil
+}
+
+// Transition moves user order to given status if it's allowed from current one,
+// change is recorded in order history with user as actor; placed order reserves its lines in warehouse chosen
+// by fulfillment strategy, paid one turns reservations into sales and cancelled one releases them, puts taken goods
+// back and gives coupon uses of its discounts back in the same transaction; order refunded before it was shipped
+// puts sold goods back too, goods of shipped orders are with customer and are returned by stock movements
+func (s *OService) Transition(ctx context.Context, id int64, to Status, login string) (*Order, error) {
+	if _, ok := transitions[to]; !ok {
+		return nil, ErrUnknownStatus
+	}
+
+	err := s.Repo.TransitionOrder(ctx, id, string(to), to == StatusCancelled, func(from string, st *mysql.OrderStock) (*mysql.StockChange, error) {
+		if !Status(from).CanTransition(to) {
+			return nil, ErrIllegalTransition
+		}
+
+		switch to {
+		case StatusPlaced:
+			return stock.Reserve(st, s.Stock.Policy, s.Stock.Strategy, time.Now().Add(s.Stock.ReservationTTL))
+		case StatusPaid:
+			return stock.Sell(st, s.Stock.Policy, s.Stock.Strategy)
+		case StatusCancelled:
+			return stock.Restock(st), nil
+		case StatusRefunded:
+			if Status(from) == StatusPaid {
+				return stock.Restock(st), nil
+			}
+		}
+		return nil, nil
+	}, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserOrder(ctx, id, "", login)
+}
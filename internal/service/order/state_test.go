@@ -0,0 +1,152 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+)
+
+func TestCanTransition(t *testing.T) {
+	all := []Status{StatusDraft, StatusPlaced, StatusPaid, StatusShipped, StatusCompleted, StatusCancelled, StatusRefunded}
+
+	allowed := map[Status][]Status{
+		StatusDraft:     {StatusPlaced, StatusCancelled},
+		StatusPlaced:    {StatusPaid, StatusCancelled},
+		StatusPaid:      {StatusShipped, StatusRefunded},
+		StatusShipped:   {StatusCompleted, StatusRefunded},
+		StatusCompleted: {StatusRefunded},
+	}
+
+	for _, from := range all {
+		for _, to := range all {
+			want := false
+			for _, next := range allowed[from] {
+				if next == to {
+					want = true
+				}
+			}
+
+			if got := from.CanTransition(to); got != want {
+				t.Errorf("%s.CanTransition(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if StatusPaid.CanTransition("lost") || Status("lost").CanTransition(StatusPaid) {
+		t.Error("unknown status takes part in transition")
+	}
+}
+
+func TestFinalStatuses(t *testing.T) {
+	for _, s := range []Status{StatusCancelled, StatusRefunded} {
+		if next := s.Next(); len(next) != 0 {
+			t.Errorf("%s order can be moved to %v", s, next)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if s, err := ParseStatus("paid"); err != nil || s != StatusPaid {
+		t.Errorf("ParseStatus(paid) = %q, %v", s, err)
+	}
+
+	for _, name := range []string{"", "Paid", "lost"} {
+		if _, err := ParseStatus(name); !errors.Is(err, ErrUnknownStatus) {
+			t.Errorf("ParseStatus(%q) returned %v, want ErrUnknownStatus", name, err)
+		}
+	}
+}
+
+// transitionRepo runs transition check against order stock and keeps its result, order isn't read afterwards
+type transitionRepo struct {
+	Repository
+	from             Status
+	stock            *mysql.OrderStock
+	releaseDiscounts bool
+	change           *mysql.StockChange
+}
+
+var errTransitioned = errors.New("transitioned")
+
+func (r *transitionRepo) TransitionOrder(ctx context.Context, id int64, to string, releaseDiscounts bool,
+	check func(from string, st *mysql.OrderStock) (*mysql.StockChange, error), login string) error {
+	change, err := check(string(r.from), r.stock)
+	if err != nil {
+		return err
+	}
+	r.change, r.releaseDiscounts = change, releaseDiscounts
+	return errTransitioned
+}
+
+// soldStock is stock of paid order that took 2 pens from warehouse 1
+func soldStock() *mysql.OrderStock {
+	return &mysql.OrderStock{
+		Items:      []mysql.OrderItem{{ID: 11, Barcode: "pen", Quantity: 2}},
+		Warehouses: []mysql.Warehouse{{ID: 1, Code: "main"}},
+		Levels:     []mysql.WarehouseStock{{WarehouseID: 1, Barcode: "pen", Stock: 8, Sold: 2}},
+	}
+}
+
+func TestTransitionRefund(t *testing.T) {
+	returned := []mysql.StockMovement{{Barcode: "pen", WarehouseID: 1, Kind: string(stock.KindReturn), Quantity: 2}}
+
+	cases := []struct {
+		from Status
+		want []mysql.StockMovement
+	}{
+		{StatusPaid, returned},
+		{StatusShipped, nil},
+		{StatusCompleted, nil},
+	}
+
+	for _, c := range cases {
+		repo := &transitionRepo{from: c.from, stock: soldStock()}
+		s := &OService{Repo: repo}
+
+		if _, err := s.Transition(context.Background(), 1, StatusRefunded, "user"); !errors.Is(err, errTransitioned) {
+			t.Fatalf("refund of %s order returned %v", c.from, err)
+		}
+
+		var got []mysql.StockMovement
+		if repo.change != nil {
+			got = repo.change.Movements
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("refund of %s order moves %+v, want %+v", c.from, got, c.want)
+		}
+		if repo.releaseDiscounts {
+			t.Errorf("refund of %s order releases discounts", c.from)
+		}
+	}
+}
+
+func TestTransitionCancel(t *testing.T) {
+	repo := &transitionRepo{from: StatusPlaced, stock: soldStock()}
+	repo.stock.Levels[0].Sold = 0
+	repo.stock.Reservations = []mysql.StockReservation{{ID: 7, Barcode: "pen", WarehouseID: 1, Quantity: 2, Status: stock.ReservationActive}}
+	s := &OService{Repo: repo}
+
+	if _, err := s.Transition(context.Background(), 1, StatusCancelled, "user"); !errors.Is(err, errTransitioned) {
+		t.Fatalf("cancel returned %v", err)
+	}
+
+	if !repo.releaseDiscounts {
+		t.Error("cancelled order keeps its discounts")
+	}
+	if len(repo.change.Close) != 1 || repo.change.Close[0].Status != stock.ReservationReleased {
+		t.Errorf("cancel closes %+v, want released reservation", repo.change.Close)
+	}
+}
+
+func TestTransitionIllegal(t *testing.T) {
+	repo := &transitionRepo{from: StatusShipped, stock: soldStock()}
+	s := &OService{Repo: repo}
+
+	if _, err := s.Transition(context.Background(), 1, StatusCancelled, "user"); !errors.Is(err, ErrIllegalTransition) {
+		t.Fatalf("cancel of shipped order returned %v, want ErrIllegalTransition", err)
+	}
+}
@@ -0,0 +1,304 @@
+package product
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Statuses of products in batch
+const (
+	BatchOK       = "ok"
+	BatchNotFound = "not_found"
+	BatchNotOwner = "not_owner"
+	BatchArchived = "archived"
+	BatchFailed   = "failed"
+)
+
+const batchManifestName = "manifest.json"
+
+var (
+	ErrBatchEmpty    = errors.New("no products chosen for batch")
+	ErrBatchTooLarge = errors.New("too many products in batch")
+)
+
+// ProductFilter holds conditions products are chosen by, zero values are ignored,
+// only products in currency of cost bounds are chosen when bounds are set
+type ProductFilter struct {
+	NameContains string
+	MinCost      money.Money
+	MaxCost      money.Money
+}
+
+// BatchRequest chooses products by barcodes or, if there are none, by filter
+type BatchRequest struct {
+	Barcodes []string
+	Filter   ProductFilter
+	Template string
+	Format   render.Format
+}
+
+// BatchResult is result of check generation for one product of batch
+type BatchResult struct {
+	Barcode  string `json:"barcode"`
+	Status   string `json:"status"`
+	FileName string `json:"file,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BatchManifest is saved in archive as manifest.json
+type BatchManifest struct {
+	Created   time.Time     `json:"created"`
+	Template  string        `json:"template,omitempty"`
+	Format    render.Format `json:"format"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// Batch holds products chosen for batch, their checks are generated while archive is written
+type Batch struct {
+	s       *PService
+	req     BatchRequest
+	login   string
+	results []BatchResult
+}
+
+// BatchChecks chooses products for batch, products user can't create checks for are reported in manifest
+func (s *PService) BatchChecks(ctx context.Context, req BatchRequest, login string) (*Batch, error) {
+	if _, err := s.Renderers.Get(req.Format); err != nil {
+		return nil, err
+	}
+
+	// fail whole batch at once if template doesn't exist
+	if _, err := s.TplResolver.ResolveTemplate(ctx, req.Template, 0, login); err != nil {
+		return nil, err
+	}
+
+	var (
+		results []BatchResult
+		err     error
+	)
+	if len(req.Barcodes) > 0 {
+		results, err = s.batchByBarcodes(ctx, req.Barcodes, login)
+	} else {
+		results, err = s.batchByFilter(ctx, req.Filter, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, ErrBatchEmpty
+	}
+
+	b := &Batch{
+		s:       s,
+		req:     req,
+		login:   login,
+		results: results,
+	}
+	return b, nil
+}
+
+func (s *PService) batchByBarcodes(ctx context.Context, barcodes []string, login string) ([]BatchResult, error) {
+	seen := make(map[string]bool, len(barcodes))
+	unique := make([]string, 0, len(barcodes))
+	for _, barcode := range barcodes {
+		if !seen[barcode] {
+			seen[barcode] = true
+			unique = append(unique, barcode)
+		}
+	}
+
+	if len(unique) > s.BatchMaxChecks {
+		return nil, ErrBatchTooLarge
+	}
+
+	owners, err := s.Repo.ProductOwners(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	byBarcode := make(map[string]mysql.ProductOwner, len(owners))
+	for _, o := range owners {
+		byBarcode[o.Barcode] = o
+	}
+
+	results := make([]BatchResult, len(unique))
+	for i, barcode := range unique {
+		results[i].Barcode = barcode
+
+		o, ok := byBarcode[barcode]
+		switch {
+		case !ok:
+			results[i].Status = BatchNotFound
+		case o.Login != login:
+			results[i].Status = BatchNotOwner
+		case o.Deleted:
+			results[i].Status = BatchArchived
+		}
+	}
+
+	return results, nil
+}
+
+func (s *PService) batchByFilter(ctx context.Context, f ProductFilter, login string) ([]BatchResult, error) {
+	dbFilter := mysql.ProductFilter{
+		NameContains: f.NameContains,
+		MinCost:      f.MinCost.Amount,
+		MaxCost:      f.MaxCost.Amount,
+	}
+	switch {
+	case f.MinCost.Amount != 0 && f.MaxCost.Amount != 0 && f.MinCost.Currency != f.MaxCost.Currency:
+		return nil, money.ErrCurrencyMismatch
+	case f.MinCost.Amount != 0:
+		dbFilter.Currency = f.MinCost.Currency
+	case f.MaxCost.Amount != 0:
+		dbFilter.Currency = f.MaxCost.Currency
+	}
+
+	barcodes, err := s.Repo.FilteredProducts(ctx, dbFilter, s.BatchMaxChecks+1, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(barcodes) > s.BatchMaxChecks {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchResult, len(barcodes))
+	for i := range barcodes {
+		results[i].Barcode = barcodes[i]
+	}
+
+	return results, nil
+}
+
+// WriteZip generates checks on pool of workers and writes them into archive as they are ready,
+// manifest with results for all products is written last
+func (b *Batch) WriteZip(ctx context.Context, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type generated struct {
+		i    int
+		file *CheckFile
+		err  error
+	}
+
+	out := make(chan generated)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(b.s.BatchWorkers)
+
+	go func() {
+		defer close(out)
+
+		for i := range b.results {
+			if b.results[i].Status != "" || gctx.Err() != nil {
+				continue
+			}
+
+			i := i
+			g.Go(func() error {
+				f, err := b.s.GenCheck(gctx, b.results[i].Barcode, b.req.Template, "", b.req.Format, b.login)
+				select {
+				case out <- generated{i: i, file: f, err: err}:
+				case <-gctx.Done():
+					if f != nil {
+						f.Content.Close()
+					}
+				}
+				return nil
+			})
+		}
+
+		g.Wait()
+	}()
+
+	zw := zip.NewWriter(w)
+
+	var writeErr error
+	for gen := range out {
+		res := &b.results[gen.i]
+
+		if gen.err != nil {
+			res.Status = BatchFailed
+			res.Error = gen.err.Error()
+			if errors.Is(gen.err, mysql.ErrNoRows) {
+				res.Status = BatchNotFound
+				res.Error = ""
+			}
+			continue
+		}
+
+		if writeErr == nil {
+			writeErr = writeZipFile(zw, gen.file.Name, gen.file.Content)
+			if writeErr != nil {
+				cancel()
+			}
+		}
+		gen.file.Content.Close()
+
+		res.Status = BatchOK
+		res.FileName = gen.file.Name
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	manifest := BatchManifest{
+		Created:  time.Now(),
+		Template: b.req.Template,
+		Format:   b.req.Format,
+		Results:  b.results,
+	}
+	for _, res := range b.results {
+		if res.Status == BatchOK {
+			manifest.Succeeded++
+		} else {
+			manifest.Failed++
+		}
+	}
+
+	mw, err := zw.Create(batchManifestName)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, r io.Reader) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, r)
+	return err
+}
@@ -0,0 +1,234 @@
+package product
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+)
+
+// batchRepo knows owners of products, product is named after its barcode
+type batchRepo struct {
+	receiptRepo
+	owners []mysql.ProductOwner
+	filter mysql.ProductFilter
+}
+
+func (r *batchRepo) ProductInfoForCheck(ctx context.Context, barcode string, login string) (*mysql.Product, error) {
+	return &mysql.Product{Barcode: barcode, Name: "product " + barcode, Cost: 1200, Currency: "EUR"}, nil
+}
+
+func (r *batchRepo) ProductOwners(ctx context.Context, barcodes []string) ([]mysql.ProductOwner, error) {
+	var res []mysql.ProductOwner
+	for _, o := range r.owners {
+		for _, barcode := range barcodes {
+			if o.Barcode == barcode {
+				res = append(res, o)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *batchRepo) FilteredProducts(ctx context.Context, f mysql.ProductFilter, limit int, login string) ([]string, error) {
+	r.filter = f
+	var res []string
+	for _, o := range r.owners {
+		if o.Login == login && !o.Deleted && len(res) < limit {
+			res = append(res, o.Barcode)
+		}
+	}
+	return res, nil
+}
+
+func testBatch(t *testing.T, maxChecks int) (*PService, *batchRepo, *testPDF) {
+	st, err := store.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &batchRepo{
+		receiptRepo: receiptRepo{numbers: map[int64]string{}},
+		owners: []mysql.ProductOwner{
+			{Barcode: "1000000001", Login: "seller"},
+			{Barcode: "1000000002", Login: "seller"},
+			{Barcode: "1000000003", Login: "other"},
+			{Barcode: "1000000004", Login: "seller", Deleted: true},
+			{Barcode: "1000000005", Login: "seller"},
+		},
+	}
+	pdf := &testPDF{}
+	s := &PService{
+		Repo:           repo,
+		TplResolver:    testTemplates{},
+		Store:          st,
+		Renderers:      render.Renderers{render.FormatPDF: pdf},
+		Locales:        testLocales{},
+		TimeFormat:     "20060102150405",
+		BatchMaxChecks: maxChecks,
+		BatchWorkers:   2,
+	}
+	return s, repo, pdf
+}
+
+// readBatch returns names of files in archive and its manifest
+func readBatch(t *testing.T, b []byte) ([]string, BatchManifest) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		names    []string
+		manifest BatchManifest
+	)
+	for i, f := range zr.File {
+		if f.Name != batchManifestName {
+			names = append(names, f.Name)
+			continue
+		}
+		if i != len(zr.File)-1 {
+			t.Error("manifest isn't the last file of archive")
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(r).Decode(&manifest)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(names)
+	return names, manifest
+}
+
+func TestBatchChecksZip(t *testing.T) {
+	s, _, pdf := testBatch(t, 10)
+	pdf.failFor = "product 1000000005"
+	ctx := context.Background()
+
+	req := BatchRequest{
+		Barcodes: []string{"1000000001", "1000000002", "1000000001", "1000000003", "1000000004", "1000000005", "1000000009"},
+		Format:   render.FormatPDF,
+	}
+	b, err := s.BatchChecks(ctx, req, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.WriteZip(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	names, manifest := readBatch(t, buf.Bytes())
+
+	statuses := map[string]string{}
+	files := map[string]bool{}
+	for _, res := range manifest.Results {
+		statuses[res.Barcode] = res.Status
+		if res.FileName != "" {
+			files[res.FileName] = true
+		}
+	}
+	want := map[string]string{
+		"1000000001": BatchOK,
+		"1000000002": BatchOK,
+		"1000000003": BatchNotOwner,
+		"1000000004": BatchArchived,
+		"1000000005": BatchFailed,
+		"1000000009": BatchNotFound,
+	}
+	if len(manifest.Results) != len(want) {
+		t.Errorf("manifest has %d results, want one for every distinct barcode", len(manifest.Results))
+	}
+	for barcode, status := range want {
+		if statuses[barcode] != status {
+			t.Errorf("product %s has status %q, want %q", barcode, statuses[barcode], status)
+		}
+	}
+	if manifest.Succeeded != 2 || manifest.Failed != 4 {
+		t.Errorf("manifest counts %d succeeded and %d failed, want 2 and 4", manifest.Succeeded, manifest.Failed)
+	}
+
+	if len(names) != 2 {
+		t.Fatalf("archive has checks %v, want 2", names)
+	}
+	for _, name := range names {
+		if !files[name] || !strings.HasSuffix(name, ".pdf") {
+			t.Errorf("check %s in archive isn't listed in manifest", name)
+		}
+	}
+}
+
+func TestBatchChecksChoice(t *testing.T) {
+	ctx := context.Background()
+
+	s, repo, _ := testBatch(t, 2)
+	if _, err := s.BatchChecks(ctx, BatchRequest{Barcodes: []string{"1", "2", "3"}, Format: render.FormatPDF}, "seller"); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("batch over limit returned %v, want ErrBatchTooLarge", err)
+	}
+	// duplicates aren't counted towards limit
+	if _, err := s.BatchChecks(ctx, BatchRequest{Barcodes: []string{"1", "2", "1"}, Format: render.FormatPDF}, "seller"); err != nil {
+		t.Errorf("batch with duplicates returned %v", err)
+	}
+
+	if _, err := s.BatchChecks(ctx, BatchRequest{Format: "docx"}, "seller"); !errors.Is(err, render.ErrUnknownFormat) {
+		t.Errorf("batch in unknown format returned %v, want ErrUnknownFormat", err)
+	}
+
+	// filter chooses 3 products of user, one more than limit
+	if _, err := s.BatchChecks(ctx, BatchRequest{Format: render.FormatPDF}, "seller"); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("filter over limit returned %v, want ErrBatchTooLarge", err)
+	}
+
+	s.BatchMaxChecks = 10
+	f := ProductFilter{MinCost: money.New(100, "EUR"), MaxCost: money.New(100, "USD")}
+	if _, err := s.BatchChecks(ctx, BatchRequest{Filter: f, Format: render.FormatPDF}, "seller"); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("filter with bounds in different currencies returned %v, want ErrCurrencyMismatch", err)
+	}
+
+	f = ProductFilter{NameContains: "pen", MaxCost: money.New(5000, "EUR")}
+	b, err := s.BatchChecks(ctx, BatchRequest{Filter: f, Format: render.FormatPDF}, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.results) != 3 || repo.filter.Currency != "EUR" || repo.filter.MaxCost != 5000 || repo.filter.NameContains != "pen" {
+		t.Errorf("filter chose %+v with %+v", b.results, repo.filter)
+	}
+
+	if _, err := s.BatchChecks(ctx, BatchRequest{Format: render.FormatPDF}, "nobody"); !errors.Is(err, ErrBatchEmpty) {
+		t.Errorf("batch without products returned %v, want ErrBatchEmpty", err)
+	}
+}
+
+// failingWriter is client connection closed before archive is sent
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestBatchChecksWriteError(t *testing.T) {
+	s, _, _ := testBatch(t, 10)
+	ctx := context.Background()
+
+	b, err := s.BatchChecks(ctx, BatchRequest{Barcodes: []string{"1000000001", "1000000002", "1000000005"}, Format: render.FormatPDF}, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteZip(ctx, failingWriter{}); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("WriteZip returned %v, want write error", err)
+	}
+}
@@ -0,0 +1,219 @@
+package product
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/signature"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxVerifiedSize limits size of uploaded check
+const maxVerifiedSize = 10 << 20
+
+var ErrTooLarge = errors.New("uploaded file is too large")
+
+// Check is service level model of generated check metadata
+type Check struct {
+	ID              int64
+	FileName        string
+	Barcode         string
+	Created         time.Time
+	Size            int64
+	SHA256          string
+	TemplateID      int64
+	TemplateVersion int
+	ProductName     string
+	ProductCost     money.Money
+	Converted       *Converted
+	Receipt         *Receipt
+	Deliveries      []Delivery
+}
+
+// Converted is cost check was printed with in other currency and rate product cost was converted with
+type Converted struct {
+	Cost money.Money
+	Rate *big.Rat
+}
+
+// Delivery is state of sending check by email
+type Delivery struct {
+	ID        int64
+	Recipient string
+	Status    string
+	Attempts  int
+	Error     string
+	Created   time.Time
+	Updated   time.Time
+}
+
+// Receipt holds fiscal data of check, amounts are in currency of check, VATRate is in basis points
+type Receipt struct {
+	Number        int64
+	SellerName    string
+	SellerTaxID   string
+	SellerAddress string
+	VATRate       int
+	Net           money.Money
+	Tax           money.Money
+	Gross         money.Money
+}
+
+// ProductChecks returns metadata of checks generated for user product
+// checksPerPage - number of checks on page
+// page - next page with checks
+func (s *PService) ProductChecks(ctx context.Context, barcode string, page, checksPerPage int, login string) ([]Check, error) {
+	// product existence and ownership is checked separately so empty page is distinguishable from foreign product
+	if _, err := s.Repo.ProductInfoForCheck(ctx, barcode, login); err != nil {
+		return nil, err
+	}
+
+	checks, err := s.Repo.ProductChecks(ctx, barcode, checksPerPage, (page-1)*checksPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Check, len(checks))
+	for i := range checks {
+		res[i] = checkFromDB(&checks[i])
+	}
+
+	return res, nil
+}
+
+// CheckInfo returns metadata of check if user owns checked product
+func (s *PService) CheckInfo(ctx context.Context, id int64, login string) (*Check, error) {
+	ch, err := s.Repo.UserCheck(ctx, id, login)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.Repo.CheckDeliveries(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := checkFromDB(ch)
+	res.Deliveries = make([]Delivery, len(deliveries))
+	for i, d := range deliveries {
+		res.Deliveries[i] = Delivery{
+			ID:        d.ID,
+			Recipient: d.Recipient,
+			Status:    d.Status,
+			Attempts:  d.Attempts,
+			Error:     d.Error,
+			Created:   d.Created,
+			Updated:   d.Updated,
+		}
+	}
+
+	return &res, nil
+}
+
+// DeleteCheck removes check record and then its stored file, file which couldn't be removed
+// is left as orphan and is cleaned up by retention reconciliation
+func (s *PService) DeleteCheck(ctx context.Context, id int64, login string) error {
+	fileName, err := s.Repo.DeleteCheck(ctx, id, login)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Store.Delete(ctx, fileName); err != nil && !errors.Is(err, store.ErrNotFound) {
+		log.WithFields(log.Fields{"check": fileName, "userLogin": login}).WithError(err).
+			Warn("Could not remove file of deleted check, left as orphan")
+	}
+
+	return nil
+}
+
+// CheckVerification holds result of check signature verification and matched check record
+type CheckVerification struct {
+	signature.Verification
+	Check *Check
+}
+
+// VerifyCheck verifies signature of uploaded check and finds its record among user checks
+func (s *PService) VerifyCheck(ctx context.Context, pdf io.Reader, login string) (*CheckVerification, error) {
+	if s.Signer == nil {
+		return nil, ErrNoSigning
+	}
+
+	b, err := io.ReadAll(io.LimitReader(pdf, maxVerifiedSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxVerifiedSize {
+		return nil, ErrTooLarge
+	}
+
+	v, err := s.Signer.Verify(b)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CheckVerification{Verification: *v}
+
+	sum := sha256.Sum256(b)
+	ch, err := s.Repo.UserCheckByHash(ctx, hex.EncodeToString(sum[:]), login)
+	if errors.Is(err, mysql.ErrNoRows) && v.CheckName != "" {
+		ch, err = s.Repo.UserCheckByName(ctx, v.CheckName, login)
+	}
+
+	switch {
+	case err == nil:
+		check := checkFromDB(ch)
+		res.Check = &check
+	case !errors.Is(err, mysql.ErrNoRows):
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func checkFromDB(ch *mysql.Check) Check {
+	res := Check{
+		ID:              ch.ID,
+		FileName:        ch.FileName,
+		Barcode:         ch.Barcode,
+		Created:         ch.Created,
+		Size:            ch.Size,
+		SHA256:          ch.SHA256,
+		TemplateID:      ch.TemplateID.Int64,
+		TemplateVersion: ch.TemplateVersion,
+		ProductName:     ch.ProductName,
+		ProductCost:     money.New(ch.ProductCost, ch.Currency),
+	}
+
+	if ch.ConvCurrency.Valid {
+		rate, _ := new(big.Rat).SetString(ch.ConvRate.String)
+		res.Converted = &Converted{
+			Cost: money.New(ch.ConvCost.Int64, ch.ConvCurrency.String),
+			Rate: rate,
+		}
+	}
+
+	if ch.ReceiptNumber.Valid {
+		res.Receipt = &Receipt{
+			Number:        ch.ReceiptNumber.Int64,
+			SellerName:    ch.SellerName,
+			SellerTaxID:   ch.SellerTaxID,
+			SellerAddress: ch.SellerAddress,
+			VATRate:       ch.VATRate,
+			Net:           money.New(ch.NetAmount, ch.Currency),
+			Tax:           money.New(ch.TaxAmount, ch.Currency),
+			Gross:         money.New(ch.GrossAmount, ch.Currency),
+		}
+	}
+
+	return res
+}
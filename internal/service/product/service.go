@@ -1,19 +1,30 @@
 package product
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/money"
 	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/signature"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
 
-	"github.com/signintech/gopdf"
+	log "github.com/sirupsen/logrus"
 )
 
 // Repository used to call db level logic
@@ -23,50 +34,122 @@ type Repository interface {
 	UserProduct(ctx context.Context, barcode string, login string) (*mysql.Product, []string, error)
 	Delete(ctx context.Context, barcode string, login string) error
 	ProductInfoForCheck(ctx context.Context, barcode string, login string) (*mysql.Product, error)
-	UpdateCheckInfo(ctx context.Context, filename string, barcode string, login string) error
+	ReserveReceiptNumber(ctx context.Context, login string) (int64, error)
+	RegisterCheck(ctx context.Context, login string, ch *mysql.Check) error
+	VoidReceiptNumber(ctx context.Context, login string, number int64, reason string) error
+	UserSeller(ctx context.Context, login string) (*mysql.Seller, error)
 	CheckOwnership(ctx context.Context, filename string, login string) error
+	ProductChecks(ctx context.Context, barcode string, amount int, offset int, login string) ([]mysql.Check, error)
+	UserCheck(ctx context.Context, id int64, login string) (*mysql.Check, error)
+	DeleteCheck(ctx context.Context, id int64, login string) (string, error)
+	UserCheckByHash(ctx context.Context, sha256 string, login string) (*mysql.Check, error)
+	UserCheckByName(ctx context.Context, filename string, login string) (*mysql.Check, error)
+	ProductOwners(ctx context.Context, barcodes []string) ([]mysql.ProductOwner, error)
+	FilteredProducts(ctx context.Context, f mysql.ProductFilter, limit int, login string) ([]string, error)
+	CreateCheckShare(ctx context.Context, sh mysql.CheckShare, login string) (int64, error)
+	UserCheckShares(ctx context.Context, filename string, login string) ([]mysql.CheckShare, error)
+	RevokeCheckShare(ctx context.Context, id int64, login string) error
+	UseCheckShare(ctx context.Context, id int64, at time.Time) (*mysql.SharedCheck, error)
+	ReturnCheckShareDownload(ctx context.Context, id int64) error
+	CheckDeliveries(ctx context.Context, checkID int64) ([]mysql.CheckDelivery, error)
+}
+
+// Templates used to choose template for check
+type Templates interface {
+	ResolveTemplate(ctx context.Context, name string, version int, login string) (*template.Template, error)
+	TemplateVersion(ctx context.Context, id int64, version int) (*template.Template, error)
+}
+
+// Locales used to get user settings for formatting checks
+type Locales interface {
+	Locale(ctx context.Context, login string) (*locale.Settings, error)
+}
+
+// Rates used to convert amounts to other currency
+type Rates interface {
+	Converter(ctx context.Context, from, to string, at time.Time) (func(m money.Money) money.Money, *big.Rat, error)
+}
+
+// Signer used to sign generated checks and verify signed ones
+type Signer interface {
+	Sign(pdf []byte, checkName string, at time.Time) ([]byte, error)
+	Verify(pdf []byte) (*signature.Verification, error)
 }
 
 var (
 	ErrNotOwner  = errors.New("user is not the owner of product")
 	ErrNotExists = errors.New("product dosn't exist")
+	ErrNoSigning = errors.New("checks signing is not configured")
+	ErrCost      = errors.New("product cost should be positive")
 )
 
 // AService struct implements auth service functionality
 type PService struct {
 	Repo           Repository
-	PathToCheckDir string
-	PathToTemplate string
-	TemplateName   string
-	FontName       string
-	FontFileName   string
+	TplResolver    Templates
+	Store          store.CheckStore
+	Signer         Signer
+	Renderers      render.Renderers
+	Locales        Locales
+	Rates          Rates
 	TimeFormat     string
-	TemplateW      float64
-	TemplateH      float64
+	BatchMaxChecks int
+	BatchWorkers   int
+	VATRate        int // basis points, used when seller has no own rate
+	ShareSecret    []byte
+	ShareTTL       time.Duration
+	ShareMaxTTL    time.Duration
 }
 
-// Order used to parse into JSON response
+// CheckFile is content of check in requested format
+type CheckFile struct {
+	Name        string
+	ContentType string
+	SHA256      string // hex encoded hash of content
+	Modified    time.Time
+	Content     io.ReadSeekCloser
+}
+
+// generatedCheck is check created in current request, kept in memory
+// so it can be sent to client without reading it back from check store
+type generatedCheck struct {
+	fileName string
+	pdf      []byte
+	sha256   string
+	created  time.Time
+	page     *render.Page
+	data     render.Data
+}
+
+// Order used to parse into JSON response, cost converted to other currency has conversion
 type Product struct {
-	Barcode  string
-	Name     string
-	Descr    string
-	Cost     int
-	Created  time.Time
-	FileName []string
+	Barcode    string
+	Name       string
+	Descr      string
+	Cost       money.Money
+	Conversion *money.Conversion `json:",omitempty"`
+	Created    time.Time
+	FileName   []string
 }
 
-func NewService(repo Repository, cfg *config.Product) *PService {
+// NewService returns product service, signer can be nil if checks shouldn't be signed
+func NewService(repo Repository, tpls Templates, st store.CheckStore, signer Signer, renderers render.Renderers, locales Locales, rates Rates, cfg *config.Product, shareCfg *config.Share) *PService {
 
 	s := &PService{
 		Repo:           repo,
-		PathToCheckDir: cfg.PathToCheckDir,
-		PathToTemplate: cfg.PathToTemplate,
-		TemplateName:   cfg.TemplateName,
-		FontName:       cfg.FontName,
-		FontFileName:   cfg.FontFileName,
+		TplResolver:    tpls,
+		Store:          st,
+		Signer:         signer,
+		Renderers:      renderers,
+		Locales:        locales,
+		Rates:          rates,
 		TimeFormat:     cfg.TimeFormat,
-		TemplateH:      cfg.TemplateH,
-		TemplateW:      cfg.TemplateW,
+		BatchMaxChecks: cfg.BatchMaxChecks,
+		BatchWorkers:   cfg.BatchWorkers,
+		VATRate:        int(math.Round(cfg.VATRate * 100)),
+		ShareSecret:    shareCfg.Secret,
+		ShareTTL:       shareCfg.DefaultTTL,
+		ShareMaxTTL:    shareCfg.MaxTTL,
 	}
 	return s
 }
@@ -74,19 +157,28 @@ func NewService(repo Repository, cfg *config.Product) *PService {
 // Create new product for user with given login
 // returns error if somethoing went wrong
 func (s *PService) Create(ctx context.Context, pr Product, login string) error {
+	cur, err := money.ParseCurrency(pr.Cost.Currency)
+	if err != nil {
+		return err
+	}
+	if pr.Cost.Amount < 1 {
+		return ErrCost
+	}
+
 	dbModel := mysql.Product{
-		Barcode: pr.Barcode,
-		Name:    pr.Name,
-		Descr:   pr.Descr,
-		Cost:    pr.Cost,
+		Barcode:  pr.Barcode,
+		Name:     pr.Name,
+		Descr:    pr.Descr,
+		Cost:     pr.Cost.Amount,
+		Currency: cur,
 	}
 	return s.Repo.Create(ctx, dbModel, login)
 }
 
-// UserProducts  returns user products
+// UserProducts  returns user products, costs are converted to currency with current rates if it is set
 // prodsPerPage - number of products on page
 // page - next page with products
-func (s *PService) UserProducts(ctx context.Context, page, prodsPerPage int, login string) ([]Product, error) {
+func (s *PService) UserProducts(ctx context.Context, page, prodsPerPage int, currency string, login string) ([]Product, error) {
 	products, err := s.Repo.UserProducts(ctx, prodsPerPage, (page-1)*prodsPerPage, login)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -100,12 +192,19 @@ func (s *PService) UserProducts(ctx context.Context, page, prodsPerPage int, log
 		return nil, nil
 	}
 
+	now := time.Now()
 	resProds := make([]Product, len(products))
 	for i := 0; i < len(products); i++ {
+		cost, conv, err := s.convert(ctx, money.New(products[i].Cost, products[i].Currency), currency, now)
+		if err != nil {
+			return nil, err
+		}
+
 		resProds[i] = Product{
-			Barcode: products[i].Barcode,
-			Name:    products[i].Name,
-			Cost:    products[i].Cost,
+			Barcode:    products[i].Barcode,
+			Name:       products[i].Name,
+			Cost:       cost,
+			Conversion: conv,
 		}
 	}
 
@@ -113,94 +212,326 @@ func (s *PService) UserProducts(ctx context.Context, page, prodsPerPage int, log
 
 }
 
-// UserProduct returns user product
-func (s *PService) UserProduct(ctx context.Context, barcode string, login string) (*Product, error) {
+// UserProduct returns user product, cost is converted to currency with current rate if it is set
+func (s *PService) UserProduct(ctx context.Context, barcode string, currency string, login string) (*Product, error) {
 	dbModel, checks, err := s.Repo.UserProduct(ctx, barcode, login)
 	if err != nil {
 		return nil, err
 	}
 
+	cost, conv, err := s.convert(ctx, money.New(dbModel.Cost, dbModel.Currency), currency, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	res := &Product{
-		Barcode:  dbModel.Barcode,
-		Name:     dbModel.Name,
-		Descr:    dbModel.Descr,
-		Cost:     dbModel.Cost,
-		Created:  dbModel.Created,
-		FileName: checks,
+		Barcode:    dbModel.Barcode,
+		Name:       dbModel.Name,
+		Descr:      dbModel.Descr,
+		Cost:       cost,
+		Conversion: conv,
+		Created:    dbModel.Created,
+		FileName:   checks,
 	}
 	return res, nil
 }
 
+// convert returns amount in currency with rate effective at given time and conversion made,
+// amount is returned as it is when currency isn't set or is currency of amount
+func (s *PService) convert(ctx context.Context, m money.Money, currency string, at time.Time) (money.Money, *money.Conversion, error) {
+	if currency == "" || currency == m.Currency {
+		return m, nil, nil
+	}
+
+	conv, rate, err := s.Rates.Converter(ctx, m.Currency, currency, at)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	return conv(m), &money.Conversion{Original: m, Rate: rate}, nil
+}
+
 // Delete delets user product
 func (s *PService) Delete(ctx context.Context, barcode string, login string) error {
 	return s.Repo.Delete(ctx, barcode, login)
 }
 
-// GenCheck creates check for product? saves in configured directory and send PDF to user
-func (s *PService) GenCheck(ctx context.Context, barcode string, login string) (*os.File, error) {
-	prod, err := s.Repo.ProductInfoForCheck(ctx, barcode, login)
+// GenCheck creates check for product using chosen template(empty name means user default),
+// saves PDF in check store and returns check in requested format, check shows cost converted to currency if it is set
+func (s *PService) GenCheck(ctx context.Context, barcode string, tplName string, currency string, format render.Format, login string) (*CheckFile, error) {
+	rn, err := s.Renderers.Get(format)
+	if err != nil {
+		return nil, err
+	}
 
+	gen, err := s.createCheck(ctx, barcode, tplName, currency, login)
 	if err != nil {
 		return nil, err
 	}
 
-	pdf := gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: s.TemplateW, H: s.TemplateH}})
-	pdf.AddPage()
+	if format == render.FormatPDF {
+		return &CheckFile{
+			Name:        gen.fileName,
+			ContentType: rn.ContentType(),
+			SHA256:      gen.sha256,
+			Modified:    gen.created,
+			Content:     store.NewBytesObject(gen.pdf),
+		}, nil
+	}
+
+	return renderCheck(rn, gen.fileName, gen.created, gen.page, gen.data)
+}
+
+// CreateCheck creates check for product using chosen template(empty name means user default),
+// saves it in check store and returns its file name
+func (s *PService) CreateCheck(ctx context.Context, barcode string, tplName string, login string) (string, error) {
+	gen, err := s.createCheck(ctx, barcode, tplName, "", login)
+	if err != nil {
+		return "", err
+	}
+
+	return gen.fileName, nil
+}
 
-	path := filepath.Join(s.PathToTemplate, s.TemplateName)
-	// import template file
-	tpl := pdf.ImportPage(path, 1, "/MediaBox") // 1 is the page
-	// Draw pdf onto page
-	pdf.UseImportedTemplate(tpl, 0, 0, s.TemplateW, s.TemplateH) // Template structure, x coordinate, y coordinate, width, height
+// createCheck reserves next receipt number of seller, renders and signs PDF check with it in memory,
+// saves it in check store and registers it, check is registered only after it was completely stored
+// and number of check that couldn't be created is voided;
+// receipt number is part of check name so checks of product created in the same second never share stored file;
+// check converted to other currency keeps rate and converted cost so it is reprinted with the same amounts
+func (s *PService) createCheck(ctx context.Context, barcode string, tplName string, currency string, login string) (*generatedCheck, error) {
+	prod, err := s.Repo.ProductInfoForCheck(ctx, barcode, login)
 
-	path = filepath.Join(s.PathToTemplate, s.FontFileName)
-	err = pdf.AddTTFFont(s.FontName, path)
 	if err != nil {
 		return nil, err
 	}
 
-	err = pdf.SetFont(s.FontName, "", 10)
+	tpl, err := s.TplResolver.ResolveTemplate(ctx, tplName, 0, login)
 	if err != nil {
 		return nil, err
 	}
-	pdf.SetXY(21, 36)
-	pdf.Text(prod.Barcode) // y coordinate specification
 
-	pdf.SetFontSize(8)
-	pdf.SetXY(21, 75)
-	pdf.Text(prod.Name) // y coordinate specification
+	st, err := s.Locales.Locale(ctx, login)
+	if err != nil {
+		return nil, err
+	}
 
-	pdf.SetFontSize(10)
-	pdf.SetXY(161, 116)
-	pdf.Text(strconv.Itoa(prod.Cost)) // y coordinate specification
+	seller, err := s.Repo.UserSeller(ctx, login)
+	if err != nil {
+		return nil, err
+	}
 
-	fileName := fmt.Sprintf("doc_%s_%s.pdf", prod.Barcode, time.Now().Format(s.TimeFormat))
+	rate := s.VATRate
+	if seller.VATRate.Valid {
+		rate = int(seller.VATRate.Int64)
+	}
+	gross := prod.Cost
+	net, tax := VATAmounts(gross, rate)
 
-	path = filepath.Join(s.PathToCheckDir, fileName)
-	err = s.Repo.UpdateCheckInfo(ctx, fileName, barcode, login)
+	now := time.Now()
+	cost, conv, err := s.convert(ctx, money.New(prod.Cost, prod.Currency), currency, now)
 	if err != nil {
 		return nil, err
 	}
-	pdf.WritePdf(path)
 
-	f, err := os.Open(path)
+	ch := mysql.Check{
+		Barcode:         barcode,
+		TemplateID:      sql.NullInt64{Int64: tpl.ID, Valid: tpl.ID != 0},
+		TemplateVersion: tpl.Version,
+		Created:         now,
+		ProductName:     prod.Name,
+		ProductCost:     prod.Cost,
+		Currency:        prod.Currency,
+		SellerName:      seller.Name,
+		SellerTaxID:     seller.TaxID,
+		SellerAddress:   seller.Address,
+		VATRate:         rate,
+		NetAmount:       net,
+		TaxAmount:       tax,
+		GrossAmount:     gross,
+	}
+	if conv != nil {
+		ch.ConvCurrency = sql.NullString{String: cost.Currency, Valid: true}
+		ch.ConvRate = sql.NullString{String: money.FormatRate(conv.Rate), Valid: true}
+		ch.ConvCost = sql.NullInt64{Int64: cost.Amount, Valid: true}
+	}
+
+	// seller sequence is locked only to reserve the number, check is rendered and stored without holding it
+	receiptNumber, err := s.Repo.ReserveReceiptNumber(ctx, login)
 	if err != nil {
 		return nil, err
 	}
-	return f, nil
+
+	fileName := fmt.Sprintf("doc_%s_%s_%d.pdf", prod.Barcode, now.In(st.Location).Format(s.TimeFormat), receiptNumber)
+	ch.FileName = fileName
+	ch.ReceiptNumber = sql.NullInt64{Int64: receiptNumber, Valid: true}
+	data := checkData(&ch, st)
+
+	b, stored, err := s.storeCheck(ctx, &ch, &tpl.Page, data, now)
+	if err == nil {
+		err = s.Repo.RegisterCheck(ctx, login, &ch)
+	}
+	if err != nil {
+		// file under name that is already registered belongs to another check and is never removed
+		if stored && !errors.Is(err, mysql.ErrUniqConstrViolation) {
+			if derr := s.Store.Delete(ctx, fileName); derr != nil {
+				log.WithFields(log.Fields{"check": fileName}).WithError(derr).Warn("Could not remove unregistered check")
+			}
+		}
+		// number is voided even if request was canceled, so it is accounted for without waiting for expiry
+		if verr := s.Repo.VoidReceiptNumber(context.Background(), login, receiptNumber, err.Error()); verr != nil {
+			log.WithFields(log.Fields{"userLogin": login, "receipt": receiptNumber}).WithError(verr).Warn("Could not void receipt number")
+		}
+		return nil, err
+	}
+
+	res := &generatedCheck{
+		fileName: fileName,
+		pdf:      b,
+		sha256:   ch.SHA256,
+		created:  now,
+		page:     &tpl.Page,
+		data:     data,
+	}
+	return res, nil
+}
+
+// storeCheck renders and signs PDF check and saves it in check store, size and hash of stored file
+// are set in check; stored reports if file was saved so it can be removed when check isn't registered
+func (s *PService) storeCheck(ctx context.Context, ch *mysql.Check, page *render.Page, data render.Data, now time.Time) ([]byte, bool, error) {
+	var buf bytes.Buffer
+	if err := s.Renderers[render.FormatPDF].Render(&buf, page, data); err != nil {
+		return nil, false, &render.Error{Err: err}
+	}
+	b := buf.Bytes()
+
+	if s.Signer != nil {
+		var err error
+		b, err = s.Signer.Sign(b, ch.FileName, now)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	sum := sha256.Sum256(b)
+	ch.Size = int64(len(b))
+	ch.SHA256 = hex.EncodeToString(sum[:])
+
+	if err := s.Store.Put(ctx, ch.FileName, bytes.NewReader(b)); err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
 }
 
-func (s *PService) UserProductCheck(ctx context.Context, fileName string, login string) (*os.File, error) {
+// UserProductCheck returns previously generated check in requested format if user owns checked product
+func (s *PService) UserProductCheck(ctx context.Context, fileName string, format render.Format, login string) (*CheckFile, error) {
+	if _, err := s.Renderers.Get(format); err != nil {
+		return nil, err
+	}
+
 	err := s.Repo.CheckOwnership(ctx, fileName, login)
 	if err != nil {
 		return nil, ErrNotOwner
 	}
 
-	path := filepath.Join(s.PathToCheckDir, fileName)
-	f, err := os.Open(path)
+	return s.checkFile(ctx, fileName, format, login)
+}
+
+// checkFile returns stored PDF check, other formats and PDF of archived receipt, whose file was removed
+// by retention, are rendered from product data and template version saved with check
+func (s *PService) checkFile(ctx context.Context, fileName string, format render.Format, login string) (*CheckFile, error) {
+	rn, err := s.Renderers.Get(format)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := s.Repo.UserCheckByName(ctx, fileName, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == render.FormatPDF && !ch.Archived.Valid {
+		f, _, err := s.Store.Get(ctx, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &CheckFile{
+			Name:        fileName,
+			ContentType: rn.ContentType(),
+			SHA256:      ch.SHA256,
+			Modified:    ch.Created,
+			Content:     f,
+		}
+		return res, nil
+	}
+
+	tpl, err := s.TplResolver.TemplateVersion(ctx, ch.TemplateID.Int64, ch.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := s.Locales.Locale(ctx, login)
 	if err != nil {
 		return nil, err
 	}
-	return f, nil
+
+	return renderCheck(rn, fileName, ch.Created, &tpl.Page, checkData(ch, st))
+}
+
+// renderCheck renders check in non PDF format, name of stored PDF gets extension of format
+func renderCheck(rn render.Renderer, fileName string, created time.Time, page *render.Page, data render.Data) (*CheckFile, error) {
+	var buf bytes.Buffer
+	if err := rn.Render(&buf, page, data); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	res := &CheckFile{
+		Name:        strings.TrimSuffix(fileName, ".pdf") + "." + rn.Extension(),
+		ContentType: rn.ContentType(),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Modified:    created,
+		Content:     store.NewBytesObject(buf.Bytes()),
+	}
+	return res, nil
+}
+
+// checkData formats product info and fiscal data of check for user locale,
+// checks created before receipts were numbered have no fiscal data,
+// converted checks show stored converted cost and amounts split from it
+func checkData(ch *mysql.Check, st *locale.Settings) render.Data {
+	cost, net, tax := money.New(ch.ProductCost, ch.Currency), money.New(ch.NetAmount, ch.Currency), money.New(ch.TaxAmount, ch.Currency)
+	if ch.ConvCurrency.Valid {
+		cost = money.New(ch.ConvCost.Int64, ch.ConvCurrency.String)
+		n, t := VATAmounts(cost.Amount, ch.VATRate)
+		net, tax = money.New(n, cost.Currency), money.New(t, cost.Currency)
+	}
+
+	d := render.Data{
+		Barcode:  ch.Barcode,
+		Name:     ch.ProductName,
+		Cost:     st.Money(cost),
+		Date:     st.Date(ch.Created),
+		Language: st.Language,
+	}
+
+	if ch.ReceiptNumber.Valid {
+		d.Seller = ch.SellerName
+		d.TaxID = ch.SellerTaxID
+		d.Address = ch.SellerAddress
+		d.Receipt = strconv.FormatInt(ch.ReceiptNumber.Int64, 10)
+		d.Net = st.Money(net)
+		d.VAT = st.Percent(ch.VATRate) + " " + st.Money(tax)
+	}
+
+	return d
+}
+
+// VATAmounts splits gross amount including VAT with rate in basis points into net amount and tax,
+// net amount is rounded half up and tax gets the rest so they always sum up to gross amount
+func VATAmounts(gross int64, rate int) (net, tax int64) {
+	d := int64(10000 + rate)
+	net = (gross*10000*2 + d) / (2 * d)
+	return net, gross - net
 }
@@ -0,0 +1,203 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
+)
+
+func TestVATAmounts(t *testing.T) {
+	cases := []struct {
+		gross int64
+		rate  int
+		net   int64
+		tax   int64
+	}{
+		{gross: 12000, rate: 2000, net: 10000, tax: 2000},
+		{gross: 1000, rate: 2000, net: 833, tax: 167},
+		{gross: 999, rate: 1900, net: 839, tax: 160},
+		{gross: 107, rate: 700, net: 100, tax: 7},
+		{gross: 5, rate: 2000, net: 4, tax: 1},
+		{gross: 1, rate: 2000, net: 1, tax: 0},
+		{gross: 1000, rate: 0, net: 1000, tax: 0},
+		{gross: 0, rate: 2000, net: 0, tax: 0},
+		{gross: 105, rate: 1000, net: 95, tax: 10},
+		// net of 1.5 minor units is rounded half up
+		{gross: 3, rate: 10000, net: 2, tax: 1},
+		{gross: 11, rate: 1000, net: 10, tax: 1},
+		{gross: 21, rate: 500, net: 20, tax: 1},
+	}
+
+	for _, c := range cases {
+		net, tax := VATAmounts(c.gross, c.rate)
+		if net != c.net || tax != c.tax {
+			t.Errorf("VATAmounts(%d, %d) = %d, %d; want %d, %d", c.gross, c.rate, net, tax, c.net, c.tax)
+		}
+		if net+tax != c.gross {
+			t.Errorf("VATAmounts(%d, %d) parts don't add up to gross", c.gross, c.rate)
+		}
+	}
+}
+
+// receiptRepo issues receipt numbers of one seller, every reserved number ends up issued or void
+type receiptRepo struct {
+	Repository
+	mu           sync.Mutex
+	last         int64
+	numbers      map[int64]string
+	checks       []mysql.Check
+	failRegister bool
+}
+
+func (r *receiptRepo) ProductInfoForCheck(ctx context.Context, barcode string, login string) (*mysql.Product, error) {
+	return &mysql.Product{Barcode: barcode, Name: "Stabilo pen", Cost: 1200, Currency: "EUR"}, nil
+}
+
+func (r *receiptRepo) UserSeller(ctx context.Context, login string) (*mysql.Seller, error) {
+	return &mysql.Seller{Name: "Stationery Ltd", TaxID: "DE123456789", VATRate: sql.NullInt64{Int64: 2000, Valid: true}}, nil
+}
+
+func (r *receiptRepo) ReserveReceiptNumber(ctx context.Context, login string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last++
+	r.numbers[r.last] = mysql.ReceiptReserved
+	return r.last, nil
+}
+
+func (r *receiptRepo) RegisterCheck(ctx context.Context, login string, ch *mysql.Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failRegister {
+		return errors.New("connection lost")
+	}
+	r.numbers[ch.ReceiptNumber.Int64] = mysql.ReceiptIssued
+	r.checks = append(r.checks, *ch)
+	return nil
+}
+
+func (r *receiptRepo) VoidReceiptNumber(ctx context.Context, login string, number int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.numbers[number] == mysql.ReceiptReserved {
+		r.numbers[number] = mysql.ReceiptVoid
+	}
+	return nil
+}
+
+type testTemplates struct {
+	Templates
+}
+
+func (testTemplates) ResolveTemplate(ctx context.Context, name string, version int, login string) (*template.Template, error) {
+	return &template.Template{ID: 1, Version: 2}, nil
+}
+
+type testLocales struct{}
+
+func (testLocales) Locale(ctx context.Context, login string) (*locale.Settings, error) {
+	return locale.Default(), nil
+}
+
+// testPDF writes PDF header instead of check, fails if fail is set or for product named failFor
+type testPDF struct {
+	render.PDF
+	fail    bool
+	failFor string
+}
+
+func (r *testPDF) Render(w io.Writer, p *render.Page, d render.Data) error {
+	if r.fail || (r.failFor != "" && d.Name == r.failFor) {
+		return errors.New("glyph is missing in font")
+	}
+	_, err := io.WriteString(w, "%PDF-1.4 "+d.Name)
+	return err
+}
+
+func TestCreateCheckReceiptNumbers(t *testing.T) {
+	st, err := store.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &receiptRepo{numbers: map[int64]string{}}
+	pdf := &testPDF{}
+	s := &PService{
+		Repo:        repo,
+		TplResolver: testTemplates{},
+		Store:       st,
+		Renderers:   render.Renderers{render.FormatPDF: pdf},
+		Locales:     testLocales{},
+		TimeFormat:  "20060102150405",
+	}
+	ctx := context.Background()
+
+	name, err := s.CreateCheck(ctx, "1234567890", "", "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(name, "_1.pdf") {
+		t.Errorf("first check is named %s, want receipt number 1 in name", name)
+	}
+	ch := repo.checks[0]
+	if ch.ReceiptNumber.Int64 != 1 || ch.VATRate != 2000 || ch.NetAmount != 1000 || ch.TaxAmount != 200 || ch.GrossAmount != 1200 {
+		t.Errorf("check is registered with receipt %d, VAT %d: %d + %d = %d; want receipt 1, VAT 2000: 1000 + 200 = 1200",
+			ch.ReceiptNumber.Int64, ch.VATRate, ch.NetAmount, ch.TaxAmount, ch.GrossAmount)
+	}
+	if ch.SellerName != "Stationery Ltd" || ch.SellerTaxID != "DE123456789" || ch.TemplateVersion != 2 {
+		t.Errorf("check is registered with seller %q %q and template version %d", ch.SellerName, ch.SellerTaxID, ch.TemplateVersion)
+	}
+
+	// number of check that can't be rendered is voided
+	pdf.fail = true
+	var renderErr *render.Error
+	if _, err := s.CreateCheck(ctx, "1234567890", "", "seller"); !errors.As(err, &renderErr) {
+		t.Fatalf("check that can't be rendered returned %v, want render error", err)
+	}
+	pdf.fail = false
+
+	// number of check that isn't registered is voided and its file is removed
+	repo.failRegister = true
+	if _, err := s.CreateCheck(ctx, "1234567890", "", "seller"); err == nil {
+		t.Fatal("check that isn't registered is created")
+	}
+	repo.failRegister = false
+
+	if _, err := s.CreateCheck(ctx, "1234567890", "", "seller"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]string{1: mysql.ReceiptIssued, 2: mysql.ReceiptVoid, 3: mysql.ReceiptVoid, 4: mysql.ReceiptIssued}
+	for n, status := range want {
+		if repo.numbers[n] != status {
+			t.Errorf("receipt number %d is %q, want %q", n, repo.numbers[n], status)
+		}
+	}
+	if len(repo.numbers) != len(want) {
+		t.Errorf("seller has receipt numbers %v, want %v", repo.numbers, want)
+	}
+
+	var files []string
+	err = st.List(ctx, func(info store.ObjectInfo) error {
+		files = append(files, info.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != repo.checks[0].FileName || files[1] != repo.checks[1].FileName {
+		t.Errorf("store has files %v, want only files of registered checks", files)
+	}
+}
@@ -0,0 +1,188 @@
+package product
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoSharing    = errors.New("check sharing is not configured")
+	ErrShareTTL     = errors.New("share link lifetime is too long")
+	ErrShareInvalid = errors.New("share link is invalid, expired or used up")
+)
+
+// Share is public link to check, token is set only when link is created
+type Share struct {
+	ID           int64
+	FileName     string
+	Token        string
+	Expires      time.Time
+	MaxDownloads int // 0 means unlimited
+	Downloads    int
+	Revoked      bool
+	Created      time.Time
+}
+
+// CreateShare creates expiring link to check that can be downloaded without authentication,
+// ttl 0 means default lifetime, maxDownloads 0 means unlimited downloads
+func (s *PService) CreateShare(ctx context.Context, fileName string, ttl time.Duration, maxDownloads int, login string) (*Share, error) {
+	if len(s.ShareSecret) == 0 {
+		return nil, ErrNoSharing
+	}
+
+	if ttl == 0 {
+		ttl = s.ShareTTL
+	}
+	if s.ShareMaxTTL > 0 && ttl > s.ShareMaxTTL {
+		return nil, ErrShareTTL
+	}
+
+	now := time.Now()
+	// MySQL TIMESTAMP keeps whole seconds, expiry in token must match stored one
+	expires := now.Add(ttl).Truncate(time.Second)
+
+	sh := mysql.CheckShare{
+		FileName:     fileName,
+		Expires:      expires,
+		MaxDownloads: sql.NullInt64{Int64: int64(maxDownloads), Valid: maxDownloads > 0},
+	}
+
+	id, err := s.Repo.CreateCheckShare(ctx, sh, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Share{
+		ID:           id,
+		FileName:     fileName,
+		Token:        s.shareToken(id, expires),
+		Expires:      expires,
+		MaxDownloads: maxDownloads,
+		Created:      now,
+	}
+	return res, nil
+}
+
+// CheckShares returns share links of user checks with download counters,
+// only links of check with given file name if it isn't empty
+func (s *PService) CheckShares(ctx context.Context, fileName string, login string) ([]Share, error) {
+	shares, err := s.Repo.UserCheckShares(ctx, fileName, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Share, len(shares))
+	for i, sh := range shares {
+		res[i] = Share{
+			ID:           sh.ID,
+			FileName:     sh.FileName,
+			Expires:      sh.Expires,
+			MaxDownloads: int(sh.MaxDownloads.Int64),
+			Downloads:    sh.Downloads,
+			Revoked:      sh.Revoked,
+			Created:      sh.Created,
+		}
+	}
+
+	return res, nil
+}
+
+// RevokeShare disables share link, check can't be downloaded by it anymore
+func (s *PService) RevokeShare(ctx context.Context, id int64, login string) error {
+	return s.Repo.RevokeCheckShare(ctx, id, login)
+}
+
+// SharedCheck returns check in requested format by share link token, download is counted before
+// check is sent so every request including partial and conditional ones uses up link;
+// download is given back if check can't be prepared
+func (s *PService) SharedCheck(ctx context.Context, token string, format render.Format) (*CheckFile, error) {
+	if len(s.ShareSecret) == 0 {
+		return nil, ErrNoSharing
+	}
+
+	if _, err := s.Renderers.Get(format); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	id, expires, ok := s.parseShareToken(token)
+	if !ok || !now.Before(expires) {
+		return nil, ErrShareInvalid
+	}
+
+	sh, err := s.Repo.UseCheckShare(ctx, id, now)
+	if err != nil {
+		if errors.Is(err, mysql.ErrNoRows) {
+			return nil, ErrShareInvalid
+		}
+		return nil, err
+	}
+
+	f, err := s.checkFile(ctx, sh.FileName, format, sh.Login)
+	if err != nil {
+		if rerr := s.Repo.ReturnCheckShareDownload(ctx, id); rerr != nil {
+			log.WithFields(log.Fields{"share": id}).WithError(rerr).Warn("Could not give back share link download")
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// shareToken returns token of share link in form id.expires.signature,
+// signature is HMAC-SHA256 of id and expiry so neither of them can be forged
+func (s *PService) shareToken(id int64, expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d", id, expires.Unix())
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.shareSignature(payload))
+}
+
+// parseShareToken returns share link id and expiry if token signature is valid
+func (s *PService) parseShareToken(token string) (int64, time.Time, bool) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return 0, time.Time{}, false
+	}
+	payload := token[:i]
+
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(sig, s.shareSignature(payload)) {
+		return 0, time.Time{}, false
+	}
+
+	idStr, expStr, ok := strings.Cut(payload, ".")
+	if !ok {
+		return 0, time.Time{}, false
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return id, time.Unix(exp, 0), true
+}
+
+func (s *PService) shareSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, s.ShareSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
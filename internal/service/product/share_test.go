@@ -0,0 +1,97 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+)
+
+func TestShareToken(t *testing.T) {
+	s := &PService{ShareSecret: []byte("secret")}
+	expires := time.Unix(1700000000, 0)
+	token := s.shareToken(42, expires)
+
+	id, exp, ok := s.parseShareToken(token)
+	if !ok || id != 42 || !exp.Equal(expires) {
+		t.Fatalf("parseShareToken(%q) = %d, %v, %v; want 42, %v, true", token, id, exp, ok, expires)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := map[string]string{
+		"other id":       "43." + parts[1] + "." + parts[2],
+		"later expiry":   parts[0] + ".1800000000." + parts[2],
+		"no signature":   parts[0] + "." + parts[1],
+		"bad signature":  parts[0] + "." + parts[1] + ".AAAA",
+		"not base64":     parts[0] + "." + parts[1] + ".!!!",
+		"empty":          "",
+		"other secret":   (&PService{ShareSecret: []byte("other")}).shareToken(42, expires),
+		"extra part":     "1." + token,
+		"truncated sign": token[:len(token)-1],
+	}
+	for name, tok := range forged {
+		if _, _, ok := s.parseShareToken(tok); ok {
+			t.Errorf("%s: forged token %q is accepted", name, tok)
+		}
+	}
+}
+
+// expiryRepo records share links and fails test if expired link reaches db
+type expiryRepo struct {
+	Repository
+	t      *testing.T
+	shares []mysql.CheckShare
+}
+
+func (r *expiryRepo) CreateCheckShare(ctx context.Context, sh mysql.CheckShare, login string) (int64, error) {
+	r.shares = append(r.shares, sh)
+	return int64(len(r.shares)), nil
+}
+
+func (r *expiryRepo) UseCheckShare(ctx context.Context, id int64, at time.Time) (*mysql.SharedCheck, error) {
+	r.t.Fatalf("expired link %d is used", id)
+	return nil, nil
+}
+
+func TestCreateShareExpiry(t *testing.T) {
+	repo := &expiryRepo{t: t}
+	s := &PService{
+		Repo:        repo,
+		Renderers:   render.Renderers{render.FormatPDF: &render.PDF{}},
+		ShareSecret: []byte("secret"),
+		ShareTTL:    time.Hour,
+		ShareMaxTTL: 24 * time.Hour,
+	}
+	ctx := context.Background()
+
+	sh, err := s.CreateShare(ctx, "check.pdf", 0, 3, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(sh.Expires); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("link with default lifetime expires in %v, want 1h", d)
+	}
+	if sh.Expires.Nanosecond() != 0 || !repo.shares[0].Expires.Equal(sh.Expires) || repo.shares[0].MaxDownloads.Int64 != 3 {
+		t.Errorf("stored link %+v doesn't match created one %+v", repo.shares[0], sh)
+	}
+	if _, exp, ok := s.parseShareToken(sh.Token); !ok || !exp.Equal(sh.Expires) {
+		t.Errorf("token expires at %v, want %v", exp, sh.Expires)
+	}
+
+	if _, err := s.CreateShare(ctx, "check.pdf", 48*time.Hour, 0, "owner"); !errors.Is(err, ErrShareTTL) {
+		t.Errorf("link over max lifetime returned %v, want ErrShareTTL", err)
+	}
+
+	expired := s.shareToken(1, time.Now().Add(-time.Second))
+	if _, err := s.SharedCheck(ctx, expired, render.FormatPDF); !errors.Is(err, ErrShareInvalid) {
+		t.Errorf("expired link returned %v, want ErrShareInvalid", err)
+	}
+
+	if _, err := (&PService{}).CreateShare(ctx, "check.pdf", 0, 0, "owner"); !errors.Is(err, ErrNoSharing) {
+		t.Errorf("sharing without secret returned %v, want ErrNoSharing", err)
+	}
+}
@@ -0,0 +1,222 @@
+package render
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/signintech/gopdf/fontmaker/core"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/font/opentype"
+)
+
+const defaultAssetsSize = 64
+
+// Assets caches template and font files used for rendering, so they are read, parsed and
+// imported once instead of on every check, entries are dropped when files change on disk
+type Assets struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool
+}
+
+// asset is cached file, parsed fonts and imported template page are filled on first use
+type asset struct {
+	path string
+	data []byte
+
+	fontOnce sync.Once
+	font     *opentype.Font
+	fontErr  error
+
+	ttfOnce sync.Once
+	ttf     *core.TTFParser
+	ttfErr  error
+
+	tplOnce sync.Once
+	tpl     *PDFTemplate
+	tplErr  error
+}
+
+// NewAssets returns cache keeping at most size files
+func NewAssets(size int) (*Assets, error) {
+	if size <= 0 {
+		size = defaultAssetsSize
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Assets{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+		watcher: watcher,
+		dirs:    make(map[string]bool),
+	}
+	return a, nil
+}
+
+// Preload reads files into cache, used to check template assets at startup
+func (a *Assets) Preload(paths ...string) {
+	for _, path := range paths {
+		if _, err := a.get(path); err != nil {
+			log.WithFields(log.Fields{"file": path}).WithError(err).Warn("Could not preload render asset")
+		}
+	}
+}
+
+// Template returns imported page of PDF template, it is imported once per file version
+func (a *Assets) Template(path string) (*PDFTemplate, error) {
+	as, err := a.get(path)
+	if err != nil {
+		return nil, err
+	}
+	return as.template()
+}
+
+// TTF returns font parsed for PDF documents, it is parsed once per file version
+func (a *Assets) TTF(path string) (*core.TTFParser, error) {
+	as, err := a.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	as.ttfOnce.Do(func() {
+		as.ttf, as.ttfErr = parseTTF(as.data)
+	})
+	return as.ttf, as.ttfErr
+}
+
+// OpenTypeFont returns parsed font, it is parsed once per file version
+func (a *Assets) OpenTypeFont(path string) (*opentype.Font, error) {
+	as, err := a.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	as.fontOnce.Do(func() {
+		as.font, as.fontErr = opentype.Parse(as.data)
+	})
+	return as.font, as.fontErr
+}
+
+// Watch drops cached files when they are changed until context is canceled
+func (a *Assets) Watch(ctx context.Context) {
+	defer a.watcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-a.watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+				if a.drop(filepath.Clean(ev.Name)) {
+					log.WithFields(log.Fields{"file": ev.Name}).Info("Render asset changed, it will be reloaded")
+				}
+			}
+		case err, ok := <-a.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.WithFields(log.Fields{"place": "assets"}).WithError(err).Warn("Render assets watcher error")
+		}
+	}
+}
+
+func (a *Assets) get(path string) (*asset, error) {
+	path = filepath.Clean(path)
+
+	a.mu.Lock()
+	if el, ok := a.entries[path]; ok {
+		a.lru.MoveToFront(el)
+		a.mu.Unlock()
+		return el.Value.(*asset), nil
+	}
+	a.mu.Unlock()
+
+	// file is loaded without lock, concurrent loads of the same file are harmless
+	as, err := a.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if el, ok := a.entries[path]; ok {
+		a.lru.MoveToFront(el)
+		return el.Value.(*asset), nil
+	}
+
+	a.entries[path] = a.lru.PushFront(as)
+	for a.lru.Len() > a.size {
+		old := a.lru.Remove(a.lru.Back()).(*asset)
+		delete(a.entries, old.path)
+	}
+
+	dir := filepath.Dir(path)
+	if !a.dirs[dir] {
+		if err := a.watcher.Add(dir); err != nil {
+			log.WithFields(log.Fields{"dir": dir}).WithError(err).Warn("Could not watch render assets directory")
+		} else {
+			a.dirs[dir] = true
+		}
+	}
+
+	return as, nil
+}
+
+func (a *Assets) load(path string) (*asset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	as := &asset{path: path, data: data}
+
+	// template is imported when it's loaded so broken files are reported before first check
+	if filepath.Ext(path) == ".pdf" {
+		if _, err := as.template(); err != nil {
+			return nil, err
+		}
+	}
+
+	return as, nil
+}
+
+func (as *asset) template() (*PDFTemplate, error) {
+	as.tplOnce.Do(func() {
+		as.tpl, as.tplErr = newPDFTemplate(as.data)
+		if as.tplErr != nil {
+			as.tplErr = fmt.Errorf("template %s can't be imported: %w", as.path, as.tplErr)
+		}
+	})
+	return as.tpl, as.tplErr
+}
+
+func (a *Assets) drop(path string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	el, ok := a.entries[path]
+	if !ok {
+		return false
+	}
+
+	a.lru.Remove(el)
+	delete(a.entries, path)
+	return true
+}
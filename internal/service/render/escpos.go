@@ -0,0 +1,119 @@
+package render
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ESC/POS commands
+var (
+	escposInit       = []byte{0x1b, 0x40}             // ESC @
+	escposAlignLeft  = []byte{0x1b, 0x61, 0x00}       // ESC a 0
+	escposAlignCtr   = []byte{0x1b, 0x61, 0x01}       // ESC a 1
+	escposSizeNormal = []byte{0x1d, 0x21, 0x00}       // GS ! 0
+	escposSizeDouble = []byte{0x1d, 0x21, 0x11}       // GS ! double width and height
+	escposHRIBelow   = []byte{0x1d, 0x48, 0x02}       // GS H 2, print barcode digits below it
+	escposBarHeight  = []byte{0x1d, 0x68, 0x50}       // GS h 80 dots
+	escposFeedCut    = []byte{0x1d, 0x56, 0x42, 0x03} // GS V B, feed 3 lines and cut
+)
+
+// fields printed with font bigger than that are printed with double size
+const escposLargeFont = 12
+
+// ESCPOS writes raw byte stream for thermal receipt printers,
+// layout position is used only for fields order, columns is number of chars in line
+// (32 for 58mm paper, 48 for 80mm)
+type ESCPOS struct {
+	Columns int
+}
+
+// Render writes commands printing fields line by line, barcode is printed as CODE128
+func (r *ESCPOS) Render(w io.Writer, p *Page, d Data) error {
+	bw := bufio.NewWriter(w)
+
+	bw.Write(escposInit)
+
+	for _, f := range p.Layout.fields(d) {
+		if f.Key == "barcode" {
+			r.barcode(bw, f.Value)
+			continue
+		}
+
+		columns := r.Columns
+		bw.Write(escposAlignLeft)
+		if f.FontSize > escposLargeFont {
+			bw.Write(escposSizeDouble)
+			columns /= 2
+		} else {
+			bw.Write(escposSizeNormal)
+		}
+
+		for _, line := range wrap(f.Value, columns) {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+	}
+
+	bw.Write(escposSizeNormal)
+	bw.Write(escposFeedCut)
+
+	return bw.Flush()
+}
+
+func (r *ESCPOS) ContentType() string {
+	return "application/vnd.escpos"
+}
+
+func (r *ESCPOS) Extension() string {
+	return "bin"
+}
+
+// barcode writes GS k command printing CODE128 barcode with its digits
+func (r *ESCPOS) barcode(w *bufio.Writer, code string) {
+	// {B selects code set B
+	data := "{B" + code
+	if len(data) > 255 {
+		data = data[:255]
+	}
+
+	w.Write(escposAlignCtr)
+	w.Write(escposHRIBelow)
+	w.Write(escposBarHeight)
+	w.Write([]byte{0x1d, 0x6b, 73, byte(len(data))}) // GS k m=73 n
+	w.WriteString(data)
+	w.WriteByte('\n')
+}
+
+// wrap splits text in lines not longer than given number of chars
+func wrap(text string, columns int) []string {
+	if columns <= 0 {
+		return []string{text}
+	}
+
+	var (
+		lines []string
+		line  []rune
+	)
+	for _, word := range strings.Fields(text) {
+		rw := []rune(word)
+		if len(line) > 0 && len(line)+1+len(rw) > columns {
+			lines = append(lines, string(line))
+			line = line[:0]
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, rw...)
+
+		for len(line) > columns {
+			lines = append(lines, string(line[:columns]))
+			line = append([]rune{}, line[columns:]...)
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+
+	return lines
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Font is TTF font used to print text
+type Font struct {
+	Name string
+	Path string
+}
+
+// ScriptFonts holds fonts for unicode scripts template font may not support(Cyrillic, Han, etc.)
+type ScriptFonts struct {
+	scripts []string
+	fonts   map[string]Font
+}
+
+// NewScriptFonts returns fonts by script, keys of given map are unicode script names in any case,
+// unknown scripts are skipped
+func NewScriptFonts(paths map[string]string) *ScriptFonts {
+	names := make(map[string]string, len(unicode.Scripts))
+	for name := range unicode.Scripts {
+		names[strings.ToLower(name)] = name
+	}
+
+	sf := &ScriptFonts{fonts: make(map[string]Font, len(paths))}
+	for key, path := range paths {
+		script, ok := names[strings.ToLower(key)]
+		if !ok {
+			log.WithFields(log.Fields{"script": key}).Warn("Unknown script in fonts configuration")
+			continue
+		}
+
+		sf.scripts = append(sf.scripts, script)
+		sf.fonts[script] = Font{Name: "script_" + script, Path: path}
+	}
+	sort.Strings(sf.scripts)
+
+	return sf
+}
+
+// font returns font of the first configured script used in text, otherwise template font
+func (sf *ScriptFonts) font(p *Page, text string) Font {
+	if sf != nil {
+		for _, r := range text {
+			if r < unicode.MaxASCII {
+				continue
+			}
+			for _, script := range sf.scripts {
+				if unicode.Is(unicode.Scripts[script], r) {
+					return sf.fonts[script]
+				}
+			}
+		}
+	}
+
+	return Font{Name: p.FontName, Path: p.PathToFont}
+}
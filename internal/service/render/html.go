@@ -0,0 +1,56 @@
+package render
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("check").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>Check {{.Data.Barcode}}</title>
+<style>
+body { margin: 0; background: #f0f0f0; }
+.check { position: relative; margin: 16px auto; background: #fff; box-shadow: 0 0 4px #999;
+	width: {{.Layout.Width}}pt; height: {{.Layout.Height}}pt; font-family: {{.Font}}, sans-serif; }
+.field { position: absolute; white-space: nowrap; }
+</style>
+</head>
+<body>
+<div class="check">
+{{- range .Fields}}
+<div class="field {{.Key}}" style="left: {{.X}}pt; top: {{.Y}}pt; font-size: {{.FontSize}}pt;">{{.Value}}</div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// HTML renders check as web page with fields positioned as in layout
+type HTML struct{}
+
+// Render writes HTML page with check
+func (r *HTML) Render(w io.Writer, p *Page, d Data) error {
+	return htmlTemplate.Execute(w, struct {
+		Layout Layout
+		Lang   string
+		Font   string
+		Data   Data
+		Fields []placed
+	}{
+		Layout: p.Layout,
+		Lang:   d.Language.String(),
+		Font:   p.FontName,
+		Data:   d,
+		Fields: p.Layout.fields(d),
+	})
+}
+
+func (r *HTML) ContentType() string {
+	return "text/html"
+}
+
+func (r *HTML) Extension() string {
+	return "html"
+}
@@ -0,0 +1,247 @@
+package render
+
+import (
+	"errors"
+	"sort"
+
+	"golang.org/x/text/language"
+)
+
+var ErrInvalidLayout = errors.New("template layout is invalid")
+
+// Field holds position and font size of text printed on check
+type Field struct {
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	FontSize float64 `json:"fontSize"`
+}
+
+// Layout describes page size of template and where product info is printed
+type Layout struct {
+	Width   float64      `json:"width"`
+	Height  float64      `json:"height"`
+	Barcode Field        `json:"barcode"`
+	Name    Field        `json:"name"`
+	Cost    Field        `json:"cost"`
+	Date    *Field       `json:"date,omitempty"`
+	Labels  []Label      `json:"labels,omitempty"`
+	Fiscal  *Fiscal      `json:"fiscal,omitempty"`
+	Receipt *ReceiptArea `json:"receipt,omitempty"`
+}
+
+// Label is static text printed on check, Text holds its translations by BCP 47 language tag,
+// translation with empty tag is used when there is no translation for user language
+type Label struct {
+	Field
+	Text map[string]string `json:"text"`
+}
+
+// Fiscal holds positions of legally required receipt data, fields that aren't set aren't printed
+type Fiscal struct {
+	Seller  *FiscalField `json:"seller,omitempty"`
+	TaxID   *FiscalField `json:"taxId,omitempty"`
+	Address *FiscalField `json:"address,omitempty"`
+	Receipt *FiscalField `json:"receipt,omitempty"`
+	Net     *FiscalField `json:"net,omitempty"`
+	VAT     *FiscalField `json:"vat,omitempty"`
+}
+
+// FiscalField is position of fiscal value, Label holds translations of text printed before value
+// by BCP 47 language tag same as Label.Text
+type FiscalField struct {
+	Field
+	Label map[string]string `json:"label,omitempty"`
+}
+
+// placed returns fiscal fields which are set with their values, fields with empty value are skipped
+// so incomplete seller profile or check without fiscal data doesn't leave labels without values
+func (f *Fiscal) placed(d Data) []placed {
+	if f == nil {
+		return nil
+	}
+
+	all := []struct {
+		field *FiscalField
+		key   string
+		value string
+	}{
+		{f.Seller, "seller", d.Seller},
+		{f.TaxID, "taxId", d.TaxID},
+		{f.Address, "address", d.Address},
+		{f.Receipt, "receipt", d.Receipt},
+		{f.Net, "net", d.Net},
+		{f.VAT, "vat", d.VAT},
+	}
+
+	var res []placed
+	for _, fl := range all {
+		if fl.field == nil || fl.value == "" {
+			continue
+		}
+
+		value := fl.value
+		if len(fl.field.Label) > 0 {
+			value = Label{Text: fl.field.Label}.translate(d.Language) + " " + value
+		}
+		res = append(res, placed{Field: fl.field.Field, Key: fl.key, Value: value})
+	}
+	return res
+}
+
+// fields returns fiscal fields which are set
+func (f *Fiscal) fields() []FiscalField {
+	if f == nil {
+		return nil
+	}
+
+	var res []FiscalField
+	for _, fl := range []*FiscalField{f.Seller, f.TaxID, f.Address, f.Receipt, f.Net, f.VAT} {
+		if fl != nil {
+			res = append(res, *fl)
+		}
+	}
+	return res
+}
+
+// DefaultLayout returns layout that matches builtin template
+func DefaultLayout(w, h float64) Layout {
+	return Layout{
+		Width:   w,
+		Height:  h,
+		Barcode: Field{X: 21, Y: 36, FontSize: 10},
+		Name:    Field{X: 21, Y: 75, FontSize: 8},
+		Cost:    Field{X: 161, Y: 116, FontSize: 10},
+		Fiscal: &Fiscal{
+			Seller:  &FiscalField{Field: Field{X: 21, Y: 118, FontSize: 7}},
+			Address: &FiscalField{Field: Field{X: 21, Y: 123, FontSize: 7}},
+			TaxID: &FiscalField{
+				Field: Field{X: 21, Y: 128, FontSize: 7},
+				Label: map[string]string{"": "Tax ID:", "de": "Steuernummer:", "ru": "ИНН:"},
+			},
+			Receipt: &FiscalField{
+				Field: Field{X: 21, Y: 133, FontSize: 7},
+				Label: map[string]string{"": "Receipt No.", "de": "Beleg-Nr.", "ru": "Чек №"},
+			},
+			Net: &FiscalField{
+				Field: Field{X: 21, Y: 138, FontSize: 7},
+				Label: map[string]string{"": "Net:", "de": "Netto:", "ru": "Без НДС:"},
+			},
+			VAT: &FiscalField{
+				Field: Field{X: 21, Y: 143, FontSize: 7},
+				Label: map[string]string{"": "VAT", "de": "MwSt.", "ru": "НДС"},
+			},
+		},
+	}
+}
+
+// Validate checks that page has size and all fields are placed inside of it
+func (l Layout) Validate() error {
+	if l.Width <= 0 || l.Height <= 0 {
+		return ErrInvalidLayout
+	}
+
+	fields := []Field{l.Barcode, l.Name, l.Cost}
+	if l.Date != nil {
+		fields = append(fields, *l.Date)
+	}
+	for _, lb := range l.Labels {
+		if len(lb.Text) == 0 || !validTags(lb.Text) {
+			return ErrInvalidLayout
+		}
+		fields = append(fields, lb.Field)
+	}
+	for _, f := range l.Fiscal.fields() {
+		if !validTags(f.Label) {
+			return ErrInvalidLayout
+		}
+		fields = append(fields, f.Field)
+	}
+
+	for _, f := range fields {
+		if f.X < 0 || f.Y < 0 || f.X > l.Width || f.Y > l.Height || f.FontSize <= 0 {
+			return ErrInvalidLayout
+		}
+	}
+
+	if l.Receipt != nil {
+		return l.Receipt.validate(l.Width, l.Height)
+	}
+
+	return nil
+}
+
+// validTags reports whether all keys of translations are empty or BCP 47 language tags
+func validTags(text map[string]string) bool {
+	for tag := range text {
+		if _, err := language.Parse(tag); tag != "" && err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// translate returns label text in language closest to given one
+func (lb Label) translate(lang language.Tag) string {
+	keys := make([]string, 0, len(lb.Text))
+	for k := range lb.Text {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	tags := make([]language.Tag, len(keys))
+	for i, k := range keys {
+		tags[i] = language.Make(k)
+	}
+
+	if len(tags) > 0 {
+		_, i, conf := language.NewMatcher(tags).Match(lang)
+		if conf != language.No {
+			return lb.Text[keys[i]]
+		}
+	}
+
+	if text, ok := lb.Text[""]; ok {
+		return text
+	}
+	if len(keys) > 0 {
+		return lb.Text[keys[0]]
+	}
+	return ""
+}
+
+// placed is field of layout with its value, X of right aligned field is right edge of text
+type placed struct {
+	Field
+	Key   string
+	Value string
+	Right bool
+}
+
+// fields returns layout fields with values ordered as they are read: top to bottom, left to right
+func (l Layout) fields(d Data) []placed {
+	res := []placed{
+		{Field: l.Barcode, Key: "barcode", Value: d.Barcode},
+		{Field: l.Name, Key: "name", Value: d.Name},
+		{Field: l.Cost, Key: "cost", Value: d.Cost},
+	}
+
+	if l.Date != nil {
+		res = append(res, placed{Field: *l.Date, Key: "date", Value: d.Date})
+	}
+
+	for _, lb := range l.Labels {
+		res = append(res, placed{Field: lb.Field, Key: "label", Value: lb.translate(d.Language)})
+	}
+
+	res = append(res, l.Fiscal.placed(d)...)
+
+	for i := 1; i < len(res); i++ {
+		for j := i; j > 0 && (res[j].Y < res[j-1].Y || (res[j].Y == res[j-1].Y && res[j].X < res[j-1].X)); j-- {
+			res[j], res[j-1] = res[j-1], res[j]
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"io"
+
+	"github.com/signintech/gopdf"
+)
+
+// PDF draws check over imported PDF template page
+type PDF struct {
+	Fonts  *ScriptFonts
+	Assets *Assets
+}
+
+// Render draws given data over template page
+func (r *PDF) Render(w io.Writer, p *Page, d Data) error {
+	return r.render(w, p, [][]placed{p.Layout.fields(d)})
+}
+
+// RenderReceipt draws order receipt over template pages, as many pages are added as lines need
+func (r *PDF) RenderReceipt(w io.Writer, p *Page, rc *Receipt) error {
+	area := p.Layout.receiptArea()
+	if err := area.validate(p.Layout.Width, p.Layout.Height); err != nil {
+		return err
+	}
+
+	return r.render(w, p, area.pages(rc))
+}
+
+// render adds page with template for every set of fields and draws fields on it
+func (r *PDF) render(w io.Writer, p *Page, pages [][]placed) error {
+	l := p.Layout
+
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: l.Width, H: l.Height}})
+
+	tpl, err := r.Assets.Template(p.PathToPDF)
+	if err != nil {
+		return err
+	}
+
+	// import template page
+	tplID, err := tpl.importInto(pdf)
+	if err != nil {
+		return err
+	}
+
+	added := make(map[string]bool)
+	for _, fields := range pages {
+		pdf.AddPage()
+		// Draw pdf onto page
+		pdf.UseImportedTemplate(tplID, 0, 0, l.Width, l.Height) // Template structure, x coordinate, y coordinate, width, height
+
+		for _, f := range fields {
+			font := r.Fonts.font(p, f.Value)
+			if !added[font.Name] {
+				ttf, err := r.Assets.TTF(font.Path)
+				if err != nil {
+					return err
+				}
+				if err := addParsedFont(pdf, font.Name, ttf); err != nil {
+					return err
+				}
+				added[font.Name] = true
+			}
+
+			if err := pdf.SetFont(font.Name, "", f.FontSize); err != nil {
+				return err
+			}
+
+			x := f.X
+			if f.Right {
+				width, err := pdf.MeasureTextWidth(f.Value)
+				if err != nil {
+					return err
+				}
+				x -= width
+			}
+			pdf.SetXY(x, f.Y)
+			pdf.Text(f.Value) // y coordinate specification
+		}
+	}
+
+	return pdf.Write(w)
+}
+
+func (r *PDF) ContentType() string {
+	return "application/pdf"
+}
+
+func (r *PDF) Extension() string {
+	return "pdf"
+}
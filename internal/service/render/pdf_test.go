@@ -0,0 +1,332 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/text/language"
+)
+
+// testPage returns page with builtin template and Go font copied into temporary directory
+func testPage(tb testing.TB) *Page {
+	tb.Helper()
+
+	dir := tb.TempDir()
+
+	tpl, err := os.ReadFile("../../../templates/template.pdf")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pdfPath := filepath.Join(dir, "template.pdf")
+	if err := os.WriteFile(pdfPath, tpl, 0o600); err != nil {
+		tb.Fatal(err)
+	}
+
+	fontPath := filepath.Join(dir, "goregular.ttf")
+	if err := os.WriteFile(fontPath, goregular.TTF, 0o600); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &Page{
+		Layout:     DefaultLayout(209.9, 148.2),
+		PathToPDF:  pdfPath,
+		FontName:   "goregular",
+		PathToFont: fontPath,
+	}
+}
+
+func testData() Data {
+	return Data{
+		Barcode:  "4006381333931",
+		Name:     "Stabilo pen",
+		Cost:     "$12.50",
+		Date:     "2024-05-06 07:08",
+		Language: language.English,
+		Seller:   "Paper Shop LLC",
+		TaxID:    "7707083893",
+		Address:  "1 Main st",
+		Receipt:  "42",
+		Net:      "$10.42",
+		VAT:      "$2.08",
+	}
+}
+
+// testReceiptPage returns test page with receipt area that fits template
+func testReceiptPage(tb testing.TB) *Page {
+	p := testPage(tb)
+	p.Layout.Receipt = &ReceiptArea{X: 10, Y: 10, Width: 190, Bottom: 140, FontSize: 5}
+	return p
+}
+
+func testReceipt(lines int) *Receipt {
+	rc := &Receipt{
+		Title:    "Order 1",
+		Date:     "2024-05-06 07:08",
+		Language: language.English,
+		Subtotal: "$125.00",
+		Total:    "$125.00",
+		Seller:   "Paper Shop LLC",
+	}
+	for i := 0; i < lines; i++ {
+		rc.Lines = append(rc.Lines, ReceiptLine{Name: "Stabilo pen " + strconv.Itoa(i), Quantity: "1", Price: "$1.25", Total: "$1.25"})
+	}
+	return rc
+}
+
+func newTestAssets(tb testing.TB) *Assets {
+	tb.Helper()
+
+	a, err := NewAssets(0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { a.watcher.Close() })
+	return a
+}
+
+var formNameRegex = regexp.MustCompile(`/GOFPDITPL0 (\d+) 0 R`)
+
+// templateStream returns content stream of form object template page is drawn with
+func templateStream(t *testing.T, pdf []byte) []byte {
+	t.Helper()
+
+	m := formNameRegex.FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("document doesn't draw template")
+	}
+
+	start := bytes.Index(pdf, []byte("\n"+string(m[1])+" 0 obj"))
+	if start < 0 {
+		t.Fatalf("document has no object %s", m[1])
+	}
+	obj := pdf[start:]
+	obj = obj[:bytes.Index(obj, []byte("endobj"))]
+
+	i := bytes.Index(obj, []byte("stream\n"))
+	j := bytes.LastIndex(obj, []byte("endstream"))
+	if i < 0 || j < i {
+		t.Fatalf("object %s isn't stream", m[1])
+	}
+	return obj[i:j]
+}
+
+func TestRenderPDFImportsCachedTemplate(t *testing.T) {
+	p := testPage(t)
+	a := newTestAssets(t)
+	r := &PDF{Assets: a}
+
+	tpl, err := a.Template(p.PathToPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.stub == nil {
+		t.Fatal("builtin template isn't imported through blank page")
+	}
+
+	var cached bytes.Buffer
+	if err := r.Render(&cached, p, testData()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	// the same template imported into document directly
+	direct := &PDFTemplate{data: tpl.data, imported: make(map[int]*importedPage)}
+	a.entries[filepath.Clean(p.PathToPDF)].Value.(*asset).tpl = direct
+
+	var imported bytes.Buffer
+	if err := r.Render(&imported, p, testData()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if !bytes.Equal(templateStream(t, cached.Bytes()), templateStream(t, imported.Bytes())) {
+		t.Fatal("cached template is drawn differently from imported one")
+	}
+}
+
+// fontDocument returns document with text written in Go font added by given function
+func fontDocument(t *testing.T, text string, add func(pdf *gopdf.GoPdf) error) []byte {
+	t.Helper()
+
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: 100, H: 100}})
+	pdf.AddPage()
+	if err := add(pdf); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdf.SetFont("goregular", "", 12); err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetXY(10, 10)
+	if err := pdf.Text(text); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAddParsedFont(t *testing.T) {
+	ttf, err := parseTTF(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every document gets own subset of shared parsed font
+	for _, text := range []string{"Check 1", "Total: 9.99 €", "Check 1"} {
+		parsed := fontDocument(t, text, func(pdf *gopdf.GoPdf) error {
+			return addParsedFont(pdf, "goregular", ttf)
+		})
+		data := fontDocument(t, text, func(pdf *gopdf.GoPdf) error {
+			return pdf.AddTTFFontData("goregular", goregular.TTF)
+		})
+
+		if !bytes.Equal(parsed, data) {
+			t.Fatalf("document with parsed font differs from one with font data for %q", text)
+		}
+	}
+}
+
+func TestRenderReceiptPDFPages(t *testing.T) {
+	p := testReceiptPage(t)
+	r := &PDF{Assets: newTestAssets(t)}
+
+	var buf bytes.Buffer
+	if err := r.RenderReceipt(&buf, p, testReceipt(40)); err != nil {
+		t.Fatalf("RenderReceipt: %v", err)
+	}
+
+	pages := bytes.Count(buf.Bytes(), []byte("/Type /Page\n"))
+	if want := len(p.Layout.receiptArea().pages(testReceipt(40))); pages != want {
+		t.Fatalf("document has %d pages, want %d", pages, want)
+	}
+}
+
+func TestAssetsTemplateReloaded(t *testing.T) {
+	p := testPage(t)
+	a := newTestAssets(t)
+
+	first, err := a.Template(p.PathToPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := a.Template(p.PathToPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again {
+		t.Fatal("template is imported again while file didn't change")
+	}
+
+	if !a.drop(filepath.Clean(p.PathToPDF)) {
+		t.Fatal("template wasn't cached")
+	}
+
+	reloaded, err := a.Template(p.PathToPDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == first {
+		t.Fatal("changed template isn't imported again")
+	}
+}
+
+func TestAssetsBrokenTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4\nnot a document"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newTestAssets(t).Template(path); err == nil {
+		t.Fatal("broken template is imported")
+	}
+}
+
+// dropAssets removes template and font of page from cache, so they are read, imported and parsed again
+func dropAssets(a *Assets, p *Page) {
+	a.drop(filepath.Clean(p.PathToPDF))
+	a.drop(filepath.Clean(p.PathToFont))
+}
+
+func BenchmarkRenderPDFUncached(b *testing.B) {
+	p := testPage(b)
+	a := newTestAssets(b)
+	r := &PDF{Assets: a}
+	d := testData()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dropAssets(a, p)
+		if err := r.Render(io.Discard, p, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderPDF(b *testing.B) {
+	p := testPage(b)
+	r := &PDF{Assets: newTestAssets(b)}
+	d := testData()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.Render(io.Discard, p, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderReceiptPDFUncached(b *testing.B) {
+	p := testReceiptPage(b)
+	a := newTestAssets(b)
+	r := &PDF{Assets: a}
+	rc := testReceipt(40)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dropAssets(a, p)
+		if err := r.RenderReceipt(io.Discard, p, rc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderReceiptPDF(b *testing.B) {
+	p := testReceiptPage(b)
+	r := &PDF{Assets: newTestAssets(b)}
+	rc := testReceipt(40)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.RenderReceipt(io.Discard, p, rc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderPNG(b *testing.B) {
+	p := testPage(b)
+	r := &PNG{Width: 576, Assets: newTestAssets(b)}
+	d := testData()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.Render(io.Discard, p, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
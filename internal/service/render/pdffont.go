@@ -0,0 +1,36 @@
+package render
+
+import (
+	_ "unsafe" // for go:linkname
+
+	"github.com/signintech/gopdf"
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+// setSubsetFontObject registers font object in document, it is what AddTTFFontData calls after parsing TTF data;
+// gopdf parses font on every AddTTFFontData and has no API to add parsed font, so already parsed font is registered
+// by this function directly, its signature has to match gopdf version from go.mod
+//
+//go:linkname setSubsetFontObject github.com/signintech/gopdf.(*GoPdf).setSubsetFontObject
+func setSubsetFontObject(gp *gopdf.GoPdf, subsetFont *gopdf.SubsetFontObj, family string, option gopdf.TtfOption) error
+
+// parseTTF parses TTF font the way AddTTFFontData does with default options
+func parseTTF(data []byte) (*core.TTFParser, error) {
+	ttf := &core.TTFParser{}
+	ttf.SetUseKerning(false)
+	if err := ttf.ParseFontData(data); err != nil {
+		return nil, err
+	}
+	return ttf, nil
+}
+
+// addParsedFont adds font parsed by parseTTF to document, parsed font is only read by gopdf
+// after parsing so one parsed font is shared by all documents
+func addParsedFont(pdf *gopdf.GoPdf, family string, ttf *core.TTFParser) error {
+	sub := &gopdf.SubsetFontObj{CharacterToGlyphIndex: gopdf.NewMapOfCharacterToGlyphIndex()}
+	sub.SetTtfFontOption(gopdf.TtfOption{Style: gopdf.Regular})
+	sub.SetFamily(family)
+	*sub.GetTTFParser() = *ttf
+
+	return setSubsetFontObject(pdf, sub, family, sub.GetTtfFontOption())
+}
@@ -0,0 +1,183 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/phpdave11/gofpdi"
+	"github.com/signintech/gopdf"
+)
+
+// templateBox is page box template page is imported with
+const templateBox = "/MediaBox"
+
+// PDFTemplate is first page of PDF template imported once, its objects are reused by every rendered check
+// instead of parsing template file for each of them.
+// Document can't take imported page directly, so blank page of the same size is imported into it
+// and its name is pointed to objects of cached template page
+type PDFTemplate struct {
+	data []byte
+	stub []byte
+
+	mu       sync.Mutex
+	imported map[int]*importedPage
+}
+
+// importedPage holds serialized objects of page starting from some object number,
+// names of form objects page is drawn with, its size and how it is drawn over page of its size
+type importedPage struct {
+	objects       map[int]string
+	names         map[string]int
+	width, height float64
+	drawing       importedDrawing
+}
+
+// newPDFTemplate imports template page and checks that it can be replaced with blank page of its size,
+// otherwise template is imported into every document as is
+func newPDFTemplate(data []byte) (*PDFTemplate, error) {
+	t := &PDFTemplate{data: data, imported: make(map[int]*importedPage)}
+
+	page, err := importPage(data, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := blankPage(page.width, page.height)
+
+	sp, err := importPage(stub, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if sp.sameDrawing(page) {
+		t.stub = stub
+	}
+
+	return t, nil
+}
+
+// importInto adds template page to document and returns its id used to draw it
+func (t *PDFTemplate) importInto(pdf *gopdf.GoPdf) (int, error) {
+	if t.stub == nil {
+		rs := io.ReadSeeker(bytes.NewReader(t.data))
+		return pdf.ImportPageStream(&rs, 1, templateBox), nil
+	}
+
+	rs := io.ReadSeeker(bytes.NewReader(t.stub))
+	id := pdf.ImportPageStream(&rs, 1, templateBox)
+
+	start := pdf.GetNextObjectID()
+	page, err := t.page(start)
+	if err != nil {
+		return 0, err
+	}
+
+	// names of blank page form objects now refer to template page objects added after it
+	pdf.ImportTemplates(page.names)
+	pdf.ImportObjects(page.objects, start)
+
+	return id, nil
+}
+
+// page returns template page objects numbered from start, documents are built the same way
+// so pages are serialized once for every start in use
+func (t *PDFTemplate) page(start int) (*importedPage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.imported[start]; ok {
+		return p, nil
+	}
+
+	p, err := importPage(t.data, start)
+	if err != nil {
+		return nil, err
+	}
+	t.imported[start] = p
+
+	return p, nil
+}
+
+// importedDrawing is template name and transformation page is drawn with on page of its size
+type importedDrawing struct {
+	name                   string
+	scaleX, scaleY, tx, ty float64
+}
+
+// importPage serializes first page of PDF with objects numbered from start,
+// importer panics on malformed documents so panic is returned as error
+func importPage(data []byte, start int) (p *importedPage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	imp := gofpdi.NewImporter()
+	rs := io.ReadSeeker(bytes.NewReader(data))
+	imp.SetSourceStream(&rs)
+	imp.SetNextObjectID(start)
+	tplID := imp.ImportPage(1, templateBox)
+
+	p = &importedPage{
+		names:   imp.PutFormXobjects(),
+		objects: imp.GetImportedObjects(),
+	}
+
+	box, ok := imp.GetPageSizes()[1][templateBox]
+	if !ok {
+		return nil, errors.New("page has no media box")
+	}
+	p.width, p.height = box["w"], box["h"]
+
+	d := &p.drawing
+	d.name, d.scaleX, d.scaleY, d.tx, d.ty = imp.UseTemplate(tplID, 0, 0, p.width, p.height)
+
+	return p, nil
+}
+
+// sameDrawing reports if page is drawn under the same name and with the same transformation as other page
+func (p *importedPage) sameDrawing(other *importedPage) bool {
+	if p.drawing != other.drawing || len(p.names) != len(other.names) {
+		return false
+	}
+	for name := range p.names {
+		if _, ok := other.names[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// blankPage returns PDF with one empty page of given size
+func blankPage(w, h float64) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << >> /Contents 4 0 R >>",
+			strconv.FormatFloat(w, 'f', -1, 64), strconv.FormatFloat(h, 'f', -1, 64)),
+		"<< /Length 0 >>\nstream\n\nendstream",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// PNG rasterizes check, width is set in dots so image can be sent to thermal printers
+// (384 dots for 58mm paper, 576 dots for 80mm)
+type PNG struct {
+	Width  int
+	Fonts  *ScriptFonts
+	Assets *Assets
+}
+
+// Render draws fields on white image scaled from layout size to configured width
+func (r *PNG) Render(w io.Writer, p *Page, d Data) error {
+	l := p.Layout
+	scale := float64(r.Width) / l.Width
+	height := int(l.Height*scale + 0.5)
+
+	img := image.NewGray(image.Rect(0, 0, r.Width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, f := range l.fields(d) {
+		fnt, err := r.loadFont(r.Fonts.font(p, f.Value).Path)
+		if err != nil {
+			return err
+		}
+
+		face, err := fontFace(fnt, f.FontSize*scale)
+		if err != nil {
+			return err
+		}
+
+		dr := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(color.Black),
+			Face: face,
+			// layout position is top of text line, drawer expects baseline
+			Dot: fixed.Point26_6{
+				X: fixed.I(int(f.X * scale)),
+				Y: fixed.I(int(f.Y*scale)) + face.Metrics().Ascent,
+			},
+		}
+		dr.DrawString(f.Value)
+		face.Close()
+	}
+
+	return png.Encode(w, img)
+}
+
+func (r *PNG) ContentType() string {
+	return "image/png"
+}
+
+func (r *PNG) Extension() string {
+	return "png"
+}
+
+// loadFont returns parsed TTF font, nil font means basic font should be used
+func (r *PNG) loadFont(path string) (*opentype.Font, error) {
+	fnt, err := r.Assets.OpenTypeFont(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return fnt, err
+}
+
+func fontFace(fnt *opentype.Font, size float64) (font.Face, error) {
+	if fnt == nil {
+		return basicfont.Face7x13, nil
+	}
+
+	return opentype.NewFace(fnt, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
@@ -0,0 +1,217 @@
+package render
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/text/language"
+)
+
+// ReceiptArea is part of template page where order lines and totals are printed,
+// lines that don't fit above Bottom are moved to next page with the same template
+type ReceiptArea struct {
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Bottom   float64 `json:"bottom"`
+	FontSize float64 `json:"fontSize"`
+}
+
+// Receipt holds order lines and totals formatted for user locale, optional values that are empty aren't printed
+type Receipt struct {
+	Title     string
+	Date      string
+	Language  language.Tag
+	Lines     []ReceiptLine
+	Subtotal  string
+	Discounts []ReceiptDiscount
+	Tax       string
+	Total     string
+	Seller    string
+	TaxID     string
+	Address   string
+}
+
+// ReceiptDiscount is coupon applied to order, amount is printed as price reduction
+type ReceiptDiscount struct {
+	Code   string
+	Amount string
+}
+
+// ReceiptLine is one order line of receipt
+type ReceiptLine struct {
+	Name     string
+	Quantity string
+	Price    string
+	Total    string
+}
+
+// receiptLabels are translations of receipt headings by BCP 47 language tag same as Label.Text
+var receiptLabels = map[string]Label{
+	"item":     {Text: map[string]string{"": "Item", "de": "Artikel", "ru": "Товар"}},
+	"quantity": {Text: map[string]string{"": "Qty", "de": "Menge", "ru": "Кол."}},
+	"price":    {Text: map[string]string{"": "Price", "de": "Preis", "ru": "Цена"}},
+	"total":    {Text: map[string]string{"": "Total", "de": "Summe", "ru": "Сумма"}},
+	"subtotal": {Text: map[string]string{"": "Subtotal", "de": "Zwischensumme", "ru": "Подытог"}},
+	"discount": {Text: map[string]string{"": "Discount", "de": "Rabatt", "ru": "Скидка"}},
+	"tax":      {Text: map[string]string{"": "incl. VAT", "de": "inkl. MwSt.", "ru": "в т.ч. НДС"}},
+	"taxId":    {Text: map[string]string{"": "Tax ID:", "de": "Steuernummer:", "ru": "ИНН:"}},
+	"page":     {Text: map[string]string{"": "Page", "de": "Seite", "ru": "Стр."}},
+}
+
+// rows of area needed for page header(title and column headings) and at most for totals with seller,
+// order has at most 3 discounts
+const (
+	receiptHeaderRows = 2
+	receiptFooterRows = 10
+)
+
+// DefaultReceiptArea returns receipt area that fits page of given size with margins of builtin template
+func DefaultReceiptArea(w, h float64) ReceiptArea {
+	return ReceiptArea{X: 21, Y: 20, Width: w - 42, Bottom: h - 10, FontSize: 7}
+}
+
+// receiptArea returns area of layout, default one if layout doesn't set it
+func (l Layout) receiptArea() ReceiptArea {
+	if l.Receipt != nil {
+		return *l.Receipt
+	}
+	return DefaultReceiptArea(l.Width, l.Height)
+}
+
+// validate checks that area is inside of page and has room for header and totals
+func (a ReceiptArea) validate(w, h float64) error {
+	if a.X < 0 || a.Y < 0 || a.Width <= 0 || a.FontSize <= 0 || a.X+a.Width > w || a.Bottom > h {
+		return ErrInvalidLayout
+	}
+	if a.rowsPerPage() < receiptHeaderRows+receiptFooterRows {
+		return ErrInvalidLayout
+	}
+	return nil
+}
+
+func (a ReceiptArea) rowHeight() float64 {
+	return a.FontSize * 1.5
+}
+
+func (a ReceiptArea) rowsPerPage() int {
+	return int(math.Floor((a.Bottom - a.Y) / a.rowHeight()))
+}
+
+// column right edges of quantity, price and line total, item name takes the rest on the left
+func (a ReceiptArea) columns() (quantity, price, total float64) {
+	return a.X + a.Width*0.64, a.X + a.Width*0.82, a.X + a.Width
+}
+
+// pages splits receipt into pages of placed fields: every page has header with page number,
+// lines follow it, totals and seller are printed after the last line and are moved
+// to next page together when they don't fit
+func (a ReceiptArea) pages(rc *Receipt) [][]placed {
+	tr := func(key string) string {
+		return receiptLabels[key].translate(rc.Language)
+	}
+
+	var footer [][2]string
+	footer = append(footer, [2]string{tr("subtotal"), rc.Subtotal})
+	for _, d := range rc.Discounts {
+		footer = append(footer, [2]string{tr("discount") + " " + d.Code, "-" + d.Amount})
+	}
+	if rc.Tax != "" {
+		footer = append(footer, [2]string{tr("tax"), rc.Tax})
+	}
+	footer = append(footer, [2]string{tr("total"), rc.Total})
+
+	var seller []string
+	for _, s := range []string{rc.Seller, rc.Address} {
+		if s != "" {
+			seller = append(seller, s)
+		}
+	}
+	if rc.TaxID != "" {
+		seller = append(seller, tr("taxId")+" "+rc.TaxID)
+	}
+
+	// one empty row separates lines from totals
+	footerRows := 1 + len(footer) + len(seller)
+	perPage := a.rowsPerPage() - receiptHeaderRows
+
+	// lines of every page, last page must have room for totals
+	var split [][]ReceiptLine
+	lines := rc.Lines
+	for {
+		if len(lines)+footerRows <= perPage || len(lines) == 0 {
+			split = append(split, lines)
+			break
+		}
+		n := perPage
+		if n > len(lines) {
+			n = len(lines)
+		}
+		split = append(split, lines[:n])
+		lines = lines[n:]
+	}
+
+	qtyX, priceX, totalX := a.columns()
+	nameChars := int((qtyX - a.X) / (a.FontSize * 0.55))
+	rowH := a.rowHeight()
+
+	res := make([][]placed, len(split))
+	for i, pageLines := range split {
+		y := a.Y
+		text := func(x float64, key, value string, right bool) {
+			if value != "" {
+				res[i] = append(res[i], placed{Field: Field{X: x, Y: y, FontSize: a.FontSize}, Key: key, Value: value, Right: right})
+			}
+		}
+
+		title := rc.Title
+		if rc.Date != "" {
+			title += "  " + rc.Date
+		}
+		text(a.X, "title", title, false)
+		if len(split) > 1 {
+			text(totalX, "page", fmt.Sprintf("%s %d/%d", tr("page"), i+1, len(split)), true)
+		}
+		y += rowH
+
+		text(a.X, "heading", tr("item"), false)
+		text(qtyX, "heading", tr("quantity"), true)
+		text(priceX, "heading", tr("price"), true)
+		text(totalX, "heading", tr("total"), true)
+		y += rowH
+
+		for _, ln := range pageLines {
+			text(a.X, "item", truncate(ln.Name, nameChars), false)
+			text(qtyX, "quantity", ln.Quantity, true)
+			text(priceX, "price", ln.Price, true)
+			text(totalX, "lineTotal", ln.Total, true)
+			y += rowH
+		}
+
+		if i < len(split)-1 {
+			continue
+		}
+
+		y += rowH
+		for _, f := range footer {
+			text(a.X, "totalLabel", f[0], false)
+			text(totalX, "totalValue", f[1], true)
+			y += rowH
+		}
+		for _, s := range seller {
+			text(a.X, "seller", s, false)
+			y += rowH
+		}
+	}
+
+	return res
+}
+
+// truncate shortens text to given number of chars marking cut with ellipsis
+func truncate(text string, chars int) string {
+	r := []rune(text)
+	if chars < 1 || len(r) <= chars {
+		return text
+	}
+	return string(r[:chars-1]) + "…"
+}
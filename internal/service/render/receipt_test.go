@@ -0,0 +1,125 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pageLayout counts fields of every page by key
+func pageLayout(pages [][]placed) []map[string]int {
+	res := make([]map[string]int, len(pages))
+	for i, page := range pages {
+		res[i] = make(map[string]int)
+		for _, p := range page {
+			res[i][p.Key]++
+		}
+	}
+	return res
+}
+
+func TestReceiptAreaPages(t *testing.T) {
+	// 14 rows per page: 2 header rows and 12 rows for lines and totals
+	area := ReceiptArea{X: 10, Y: 0, Width: 100, Bottom: 42, FontSize: 2}
+	if err := area.validate(120, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	full := func(lines int) *Receipt {
+		rc := testReceipt(lines)
+		rc.Discounts = []ReceiptDiscount{{"A", "$1"}, {"B", "$1"}, {"C", "$1"}}
+		rc.Tax = "$2"
+		rc.Address = "1 Main st"
+		rc.TaxID = "7707083893"
+		return rc
+	}
+
+	cases := []struct {
+		name  string
+		rc    *Receipt
+		lines []int // lines on every page
+	}{
+		{"empty", testReceipt(0), []int{0}},
+		// subtotal, total and seller take 4 rows with separator
+		{"fits", testReceipt(8), []int{8}},
+		{"totals moved", testReceipt(9), []int{9, 0}},
+		{"page full of lines", testReceipt(12), []int{12, 0}},
+		{"lines continued", testReceipt(13), []int{12, 1}},
+		{"three pages", testReceipt(32), []int{12, 12, 8}},
+		// 3 discounts, tax, address and tax id make totals 10 rows long
+		{"all totals fit", full(2), []int{2}},
+		{"all totals moved", full(3), []int{3, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pages := area.pages(c.rc)
+			layout := pageLayout(pages)
+
+			got := make([]int, len(layout))
+			for i, l := range layout {
+				got[i] = l["item"]
+			}
+			if !reflect.DeepEqual(got, c.lines) {
+				t.Fatalf("lines per page = %v, want %v", got, c.lines)
+			}
+
+			for i, l := range layout {
+				if l["title"] != 1 || l["heading"] != 4 {
+					t.Errorf("page %d has no header: %v", i+1, l)
+				}
+				if want := len(pages) > 1; (l["page"] == 1) != want {
+					t.Errorf("page %d has page number %v, want %v", i+1, l["page"] == 1, want)
+				}
+
+				last := i == len(layout)-1
+				if (l["totalLabel"] > 0) != last || (l["seller"] > 0) != last {
+					t.Errorf("page %d totals: %v", i+1, l)
+				}
+
+				for _, p := range pages[i] {
+					if p.Y < area.Y || p.Y+area.rowHeight() > area.Bottom {
+						t.Errorf("page %d field %s at %v is outside of area", i+1, p.Key, p.Y)
+					}
+				}
+			}
+
+			totals := 2 + len(c.rc.Discounts)
+			if c.rc.Tax != "" {
+				totals++
+			}
+			if last := layout[len(layout)-1]; last["totalLabel"] != totals {
+				t.Errorf("last page has %d total rows, want %d", last["totalLabel"], totals)
+			}
+		})
+	}
+}
+
+func TestReceiptAreaPageNumbers(t *testing.T) {
+	area := ReceiptArea{X: 10, Y: 0, Width: 100, Bottom: 42, FontSize: 2}
+
+	var numbers []string
+	for _, page := range area.pages(testReceipt(32)) {
+		for _, p := range page {
+			if p.Key == "page" {
+				numbers = append(numbers, p.Value)
+			}
+		}
+	}
+
+	if want := []string{"Page 1/3", "Page 2/3", "Page 3/3"}; !reflect.DeepEqual(numbers, want) {
+		t.Fatalf("page numbers = %v, want %v", numbers, want)
+	}
+}
+
+func TestReceiptAreaTruncatesNames(t *testing.T) {
+	area := ReceiptArea{X: 10, Y: 0, Width: 100, Bottom: 42, FontSize: 2}
+	rc := testReceipt(1)
+	rc.Lines[0].Name = strings.Repeat("x", 200)
+
+	for _, p := range area.pages(rc)[0] {
+		if p.Key == "item" && (len([]rune(p.Value)) >= 200 || !strings.HasSuffix(p.Value, "…")) {
+			t.Fatalf("long item name is printed as %q", p.Value)
+		}
+	}
+}
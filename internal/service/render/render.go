@@ -0,0 +1,92 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+
+	"golang.org/x/text/language"
+)
+
+// Format is output format of check
+type Format string
+
+// Supported check formats
+const (
+	FormatPDF    Format = "pdf"
+	FormatHTML   Format = "html"
+	FormatPNG    Format = "png"
+	FormatESCPOS Format = "escpos"
+)
+
+var ErrUnknownFormat = errors.New("unknown check format")
+
+// Error is error of rendering check with given data and template, rendering them again gives the same error
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("check can't be rendered: %s", e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Page holds template assets and layout used to render check
+type Page struct {
+	Layout     Layout
+	PathToPDF  string
+	FontName   string
+	PathToFont string
+}
+
+// Data holds product info printed on check formatted for user locale,
+// language is used to choose translation of template labels
+type Data struct {
+	Barcode  string
+	Name     string
+	Cost     string
+	Date     string
+	Language language.Tag
+	Seller   string
+	TaxID    string
+	Address  string
+	Receipt  string
+	Net      string
+	VAT      string
+}
+
+// Renderer writes check in its format
+type Renderer interface {
+	Render(w io.Writer, p *Page, d Data) error
+	ContentType() string
+	Extension() string
+}
+
+// Renderers holds renderers of all supported formats
+type Renderers map[Format]Renderer
+
+// NewRenderers returns renderers of all supported formats, template and font files are read through assets cache
+func NewRenderers(cfg *config.Render, assets *Assets) Renderers {
+	fonts := NewScriptFonts(cfg.Fonts)
+
+	return Renderers{
+		FormatPDF:    &PDF{Fonts: fonts, Assets: assets},
+		FormatHTML:   &HTML{},
+		FormatPNG:    &PNG{Width: cfg.PNGWidth, Fonts: fonts, Assets: assets},
+		FormatESCPOS: &ESCPOS{Columns: cfg.ESCPOSColumns},
+	}
+}
+
+// Get returns renderer of given format
+func (r Renderers) Get(f Format) (Renderer, error) {
+	rn, ok := r[f]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	return rn, nil
+}
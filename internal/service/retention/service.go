@@ -0,0 +1,315 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	ExpiredChecks(ctx context.Context, before time.Time, limit int) ([]mysql.StoredCheck, error)
+	OverQuotaChecks(ctx context.Context, quota int, limit int) ([]mysql.StoredCheck, error)
+	ChecksAfter(ctx context.Context, afterID int64, before time.Time, limit int) ([]mysql.StoredCheck, error)
+	ReferencedCheckFiles(ctx context.Context, names []string) ([]string, error)
+	RemoveChecks(ctx context.Context, ids []int64) (int64, error)
+	ArchiveChecks(ctx context.Context, ids []int64) (int64, error)
+}
+
+// metrics are published by expvar under "retention" and count totals of all runs since start
+var metrics = expvar.NewMap("retention")
+
+// Report is summary of one retention run
+type Report struct {
+	Expired      int64 // checks removed because of age
+	OverQuota    int64 // checks removed because user exceeded quota
+	Archived     int64 // fiscal receipts among removed checks, their records are kept
+	OrphanFiles  int64 // stored files without check record
+	DanglingRows int64 // check records without stored file
+	Repaired     int64 // orphaned files and dangling rows removed
+	Errors       int64
+	Duration     time.Duration
+}
+
+// RService struct implements cleanup of old checks and reconciliation of storage with db
+type RService struct {
+	Repo             Repository
+	Store            store.CheckStore
+	Interval         time.Duration
+	MaxAge           time.Duration
+	MaxChecksPerUser int
+	BatchSize        int
+	Repair           bool
+	OrphanGrace      time.Duration
+}
+
+// NewService returns retention service
+func NewService(repo Repository, st store.CheckStore, cfg *config.Retention) *RService {
+	s := &RService{
+		Repo:             repo,
+		Store:            st,
+		Interval:         cfg.Interval,
+		MaxAge:           cfg.MaxAge,
+		MaxChecksPerUser: cfg.MaxChecksPerUser,
+		BatchSize:        cfg.BatchSize,
+		Repair:           cfg.Repair,
+		OrphanGrace:      cfg.OrphanGrace,
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = 500
+	}
+	return s
+}
+
+// RunRetention cleans up checks every interval until context is canceled, zero interval disables cleanup
+func (s *RService) RunRetention(ctx context.Context) {
+	if s.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.Cleanup(ctx)
+	}
+}
+
+// Cleanup removes expired and over quota checks from storage and db, then reconciles storage with db,
+// result is logged and added to metrics
+func (s *RService) Cleanup(ctx context.Context) *Report {
+	start := time.Now()
+	rep := &Report{}
+
+	if s.MaxAge > 0 {
+		before := start.Add(-s.MaxAge)
+		rep.Expired = s.removeChecks(ctx, rep, "expired", func(limit int) ([]mysql.StoredCheck, error) {
+			return s.Repo.ExpiredChecks(ctx, before, limit)
+		})
+	}
+
+	if s.MaxChecksPerUser > 0 {
+		rep.OverQuota = s.removeChecks(ctx, rep, "overQuota", func(limit int) ([]mysql.StoredCheck, error) {
+			return s.Repo.OverQuotaChecks(ctx, s.MaxChecksPerUser, limit)
+		})
+	}
+
+	// fresh files and rows can belong to check that is being generated or deleted right now
+	grace := start.Add(-s.OrphanGrace)
+	s.danglingRows(ctx, rep, grace)
+	s.orphanFiles(ctx, rep, grace)
+
+	rep.Duration = time.Since(start)
+
+	metrics.Add("runs", 1)
+	metrics.Add("expired", rep.Expired)
+	metrics.Add("overQuota", rep.OverQuota)
+	metrics.Add("archived", rep.Archived)
+	metrics.Add("orphanFiles", rep.OrphanFiles)
+	metrics.Add("danglingRows", rep.DanglingRows)
+	metrics.Add("repaired", rep.Repaired)
+	metrics.Add("errors", rep.Errors)
+
+	lg := log.WithFields(log.Fields{
+		"place":        "retention",
+		"expired":      rep.Expired,
+		"overQuota":    rep.OverQuota,
+		"archived":     rep.Archived,
+		"orphanFiles":  rep.OrphanFiles,
+		"danglingRows": rep.DanglingRows,
+		"repaired":     rep.Repaired,
+		"errors":       rep.Errors,
+		"duration":     rep.Duration,
+	})
+	if rep.Errors > 0 {
+		lg.Warn("Check retention finished with errors")
+	} else {
+		lg.Info("Check retention finished")
+	}
+
+	return rep
+}
+
+// removeChecks deletes checks returned by next in batches until there are none left, records are deleted
+// before files so check is never listed without its file, files left after failed deletion are removed
+// as orphans; returns number of removed checks
+func (s *RService) removeChecks(ctx context.Context, rep *Report, reason string, next func(limit int) ([]mysql.StoredCheck, error)) int64 {
+	var removed int64
+
+	for ctx.Err() == nil {
+		checks, err := next(s.BatchSize)
+		if err != nil {
+			s.fail(ctx, rep, err, "Could not find checks to remove", log.Fields{"reason": reason})
+			break
+		}
+
+		n, archived, err := s.dropRecords(ctx, checks)
+		if err != nil {
+			s.fail(ctx, rep, err, "Could not delete check records", log.Fields{"reason": reason})
+			break
+		}
+		removed += n
+		rep.Archived += archived
+
+		for _, ch := range checks {
+			if err := s.Store.Delete(ctx, ch.FileName); err != nil && !errors.Is(err, store.ErrNotFound) {
+				s.fail(ctx, rep, err, "Could not delete check file, left as orphan", log.Fields{"check": ch.FileName})
+			}
+		}
+
+		if len(checks) < s.BatchSize || n == 0 {
+			break
+		}
+	}
+
+	return removed
+}
+
+// dropRecords deletes records of checks that aren't fiscal receipts and archives records of receipts,
+// returns number of deleted and archived records and number of archived ones among them
+func (s *RService) dropRecords(ctx context.Context, checks []mysql.StoredCheck) (int64, int64, error) {
+	var plain, fiscal []int64
+	for _, ch := range checks {
+		if ch.ReceiptNumber.Valid {
+			fiscal = append(fiscal, ch.ID)
+		} else {
+			plain = append(plain, ch.ID)
+		}
+	}
+
+	removed, err := s.Repo.RemoveChecks(ctx, plain)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	archived, err := s.Repo.ArchiveChecks(ctx, fiscal)
+	if err != nil {
+		return removed, 0, err
+	}
+
+	return removed + archived, archived, nil
+}
+
+// danglingRows finds check records created before given time whose files are missing in storage
+func (s *RService) danglingRows(ctx context.Context, rep *Report, before time.Time) {
+	var afterID int64
+
+	for ctx.Err() == nil {
+		checks, err := s.Repo.ChecksAfter(ctx, afterID, before, s.BatchSize)
+		if err != nil {
+			s.fail(ctx, rep, err, "Could not read check records", nil)
+			return
+		}
+
+		var dangling []mysql.StoredCheck
+		for _, ch := range checks {
+			_, err := s.Store.Stat(ctx, ch.FileName)
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				rep.DanglingRows++
+				log.WithFields(log.Fields{"place": "retention", "check": ch.FileName}).Warn("Check record without file")
+				dangling = append(dangling, ch)
+			case err != nil:
+				s.fail(ctx, rep, err, "Could not check file of check record", log.Fields{"check": ch.FileName})
+			}
+		}
+
+		if s.Repair {
+			n, _, err := s.dropRecords(ctx, dangling)
+			if err != nil {
+				s.fail(ctx, rep, err, "Could not delete dangling check records", nil)
+			}
+			rep.Repaired += n
+		}
+
+		if len(checks) < s.BatchSize {
+			return
+		}
+		afterID = checks[len(checks)-1].ID
+	}
+}
+
+// orphanFiles finds stored files modified before given time that have no check record,
+// it's skipped if storage can't list its objects
+func (s *RService) orphanFiles(ctx context.Context, rep *Report, before time.Time) {
+	lister, ok := s.Store.(store.Lister)
+	if !ok {
+		return
+	}
+
+	batch := make([]string, 0, s.BatchSize)
+
+	flush := func() error {
+		referenced, err := s.Repo.ReferencedCheckFiles(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[string]bool, len(referenced))
+		for _, name := range referenced {
+			known[name] = true
+		}
+
+		for _, name := range batch {
+			if known[name] {
+				continue
+			}
+
+			rep.OrphanFiles++
+			log.WithFields(log.Fields{"place": "retention", "check": name}).Warn("Check file without record")
+
+			if !s.Repair {
+				continue
+			}
+			if err := s.Store.Delete(ctx, name); err != nil && !errors.Is(err, store.ErrNotFound) {
+				s.fail(ctx, rep, err, "Could not delete orphaned check file", log.Fields{"check": name})
+				continue
+			}
+			rep.Repaired++
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	err := lister.List(ctx, func(info store.ObjectInfo) error {
+		if !info.ModTime.Before(before) {
+			return nil
+		}
+
+		batch = append(batch, info.Name)
+		if len(batch) < s.BatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		s.fail(ctx, rep, err, "Could not reconcile stored check files", nil)
+	}
+}
+
+// fail logs error of retention step and counts it, errors caused by shutdown are ignored
+func (s *RService) fail(ctx context.Context, rep *Report, err error, msg string, fields log.Fields) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	rep.Errors++
+	log.WithFields(log.Fields{"place": "retention"}).WithFields(fields).WithError(err).Error(msg)
+}
@@ -0,0 +1,268 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
+)
+
+// checkRow is check record of fake repository, all checks belong to one user
+type checkRow struct {
+	mysql.StoredCheck
+	archived bool
+}
+
+type checksRepo struct {
+	rows []*checkRow
+}
+
+func (r *checksRepo) add(id int64, name string, created time.Time, receipt int64) {
+	ch := mysql.StoredCheck{ID: id, FileName: name, Created: created, ReceiptNumber: sql.NullInt64{Int64: receipt, Valid: receipt > 0}}
+	r.rows = append(r.rows, &checkRow{StoredCheck: ch})
+}
+
+func (r *checksRepo) row(id int64) *checkRow {
+	for _, row := range r.rows {
+		if row.ID == id {
+			return row
+		}
+	}
+	return nil
+}
+
+// stored returns records with files ordered from oldest
+func (r *checksRepo) stored() []mysql.StoredCheck {
+	var res []mysql.StoredCheck
+	for _, row := range r.rows {
+		if !row.archived {
+			res = append(res, row.StoredCheck)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Created.Before(res[j].Created) })
+	return res
+}
+
+func limited(checks []mysql.StoredCheck, limit int) []mysql.StoredCheck {
+	if len(checks) > limit {
+		return checks[:limit]
+	}
+	return checks
+}
+
+func (r *checksRepo) ExpiredChecks(ctx context.Context, before time.Time, limit int) ([]mysql.StoredCheck, error) {
+	var res []mysql.StoredCheck
+	for _, ch := range r.stored() {
+		if ch.Created.Before(before) {
+			res = append(res, ch)
+		}
+	}
+	return limited(res, limit), nil
+}
+
+func (r *checksRepo) OverQuotaChecks(ctx context.Context, quota int, limit int) ([]mysql.StoredCheck, error) {
+	st := r.stored()
+	if len(st) <= quota {
+		return nil, nil
+	}
+	return limited(st[:len(st)-quota], limit), nil
+}
+
+func (r *checksRepo) ChecksAfter(ctx context.Context, afterID int64, before time.Time, limit int) ([]mysql.StoredCheck, error) {
+	var res []mysql.StoredCheck
+	for _, ch := range r.stored() {
+		if ch.ID > afterID && ch.Created.Before(before) {
+			res = append(res, ch)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return limited(res, limit), nil
+}
+
+func (r *checksRepo) ReferencedCheckFiles(ctx context.Context, names []string) ([]string, error) {
+	var res []string
+	for _, ch := range r.stored() {
+		for _, name := range names {
+			if ch.FileName == name {
+				res = append(res, name)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *checksRepo) RemoveChecks(ctx context.Context, ids []int64) (int64, error) {
+	var n int64
+	for _, id := range ids {
+		for i, row := range r.rows {
+			if row.ID == id && !row.ReceiptNumber.Valid {
+				r.rows = append(r.rows[:i], r.rows[i+1:]...)
+				n++
+				break
+			}
+		}
+	}
+	return n, nil
+}
+
+func (r *checksRepo) ArchiveChecks(ctx context.Context, ids []int64) (int64, error) {
+	var n int64
+	for _, id := range ids {
+		if row := r.row(id); row != nil && !row.archived {
+			row.archived = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+// flakyStore fails first deletion of files with given name
+type flakyStore struct {
+	*store.FSStore
+	fail map[string]bool
+}
+
+func (s *flakyStore) Delete(ctx context.Context, name string) error {
+	if s.fail[name] {
+		delete(s.fail, name)
+		return errors.New("storage is unavailable")
+	}
+	return s.FSStore.Delete(ctx, name)
+}
+
+// testRetention returns retention service with files of given names stored and records of given checks
+func testRetention(t *testing.T, files ...string) (*RService, *checksRepo, *flakyStore) {
+	fs, err := store.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		if err := fs.Put(context.Background(), name, strings.NewReader("%PDF-1.4")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := &checksRepo{}
+	st := &flakyStore{FSStore: fs, fail: map[string]bool{}}
+	s := &RService{Repo: repo, Store: st, BatchSize: 2, Repair: true}
+	return s, repo, st
+}
+
+func exists(t *testing.T, st store.CheckStore, name string) bool {
+	_, err := st.Stat(context.Background(), name)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestCleanupExpired(t *testing.T) {
+	s, repo, st := testRetention(t, "old1.pdf", "old2.pdf", "receipt.pdf", "new.pdf")
+	s.MaxAge = 24 * time.Hour
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	repo.add(1, "old1.pdf", old, 0)
+	repo.add(2, "receipt.pdf", old.Add(time.Minute), 7)
+	repo.add(3, "old2.pdf", old.Add(2*time.Minute), 0)
+	repo.add(4, "new.pdf", now, 8)
+
+	rep := s.Cleanup(context.Background())
+	if rep.Expired != 3 || rep.Archived != 1 || rep.Errors != 0 {
+		t.Fatalf("Cleanup = %+v, want 3 expired checks with 1 archived receipt", rep)
+	}
+
+	if repo.row(1) != nil || repo.row(3) != nil {
+		t.Error("records of expired checks are kept")
+	}
+	if row := repo.row(2); row == nil || !row.archived {
+		t.Error("record of expired receipt isn't archived")
+	}
+	if row := repo.row(4); row == nil || row.archived {
+		t.Error("fresh receipt is archived")
+	}
+
+	for name, want := range map[string]bool{"old1.pdf": false, "old2.pdf": false, "receipt.pdf": false, "new.pdf": true} {
+		if got := exists(t, st, name); got != want {
+			t.Errorf("file %s exists: %t, want %t", name, got, want)
+		}
+	}
+
+	// archived receipt is neither expired again nor reported as dangling
+	rep = s.Cleanup(context.Background())
+	if rep.Expired != 0 || rep.Archived != 0 || rep.DanglingRows != 0 || rep.OrphanFiles != 0 {
+		t.Fatalf("second Cleanup = %+v, want nothing to clean up", rep)
+	}
+}
+
+func TestCleanupDeletesRecordFirst(t *testing.T) {
+	s, repo, st := testRetention(t, "old.pdf")
+	s.MaxAge = time.Hour
+	st.fail["old.pdf"] = true
+
+	repo.add(1, "old.pdf", time.Now().Add(-2*time.Hour), 0)
+
+	rep := s.Cleanup(context.Background())
+	if rep.Expired != 1 || rep.Errors != 1 {
+		t.Fatalf("Cleanup = %+v, want 1 expired check and 1 error", rep)
+	}
+	if repo.row(1) != nil {
+		t.Fatal("record is kept when file couldn't be deleted")
+	}
+
+	// file left by failed deletion has no record and is removed as orphan in the same run
+	if rep.OrphanFiles != 1 || rep.Repaired != 1 || exists(t, st, "old.pdf") {
+		t.Fatalf("Cleanup = %+v, want file left after failed deletion removed as orphan", rep)
+	}
+}
+
+func TestCleanupOverQuota(t *testing.T) {
+	s, repo, st := testRetention(t, "a.pdf", "b.pdf", "c.pdf")
+	s.MaxChecksPerUser = 1
+
+	now := time.Now()
+	repo.add(1, "a.pdf", now.Add(-3*time.Hour), 1)
+	repo.add(2, "b.pdf", now.Add(-2*time.Hour), 0)
+	repo.add(3, "c.pdf", now.Add(-time.Hour), 2)
+
+	rep := s.Cleanup(context.Background())
+	if rep.OverQuota != 2 || rep.Archived != 1 {
+		t.Fatalf("Cleanup = %+v, want 2 checks over quota with 1 archived receipt", rep)
+	}
+	if row := repo.row(1); row == nil || !row.archived || repo.row(2) != nil {
+		t.Fatal("older checks aren't archived or removed")
+	}
+	if !exists(t, st, "c.pdf") || exists(t, st, "a.pdf") {
+		t.Fatal("files of checks over quota aren't removed")
+	}
+
+	// archived receipt doesn't count towards quota
+	if rep = s.Cleanup(context.Background()); rep.OverQuota != 0 {
+		t.Fatalf("second Cleanup = %+v, want nothing over quota", rep)
+	}
+}
+
+func TestCleanupDanglingReceipt(t *testing.T) {
+	s, repo, _ := testRetention(t)
+
+	old := time.Now().Add(-time.Hour)
+	repo.add(1, "lost.pdf", old, 0)
+	repo.add(2, "lost-receipt.pdf", old, 3)
+
+	rep := s.Cleanup(context.Background())
+	if rep.DanglingRows != 2 || rep.Repaired != 2 {
+		t.Fatalf("Cleanup = %+v, want 2 dangling rows repaired", rep)
+	}
+	if repo.row(1) != nil {
+		t.Error("dangling record is kept")
+	}
+	if row := repo.row(2); row == nil || !row.archived {
+		t.Error("dangling receipt isn't archived")
+	}
+}
@@ -3,28 +3,96 @@ package service
 import (
 	"github.com/AnisaForWork/user_orders/internal/config"
 	"github.com/AnisaForWork/user_orders/internal/provider/token"
+	"github.com/AnisaForWork/user_orders/internal/repository/store"
 	"github.com/AnisaForWork/user_orders/internal/service/auth"
+	"github.com/AnisaForWork/user_orders/internal/service/coupon"
+	"github.com/AnisaForWork/user_orders/internal/service/delivery"
+	"github.com/AnisaForWork/user_orders/internal/service/exchange"
+	"github.com/AnisaForWork/user_orders/internal/service/inventory"
+	"github.com/AnisaForWork/user_orders/internal/service/job"
+	"github.com/AnisaForWork/user_orders/internal/service/order"
 	"github.com/AnisaForWork/user_orders/internal/service/product"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+	"github.com/AnisaForWork/user_orders/internal/service/retention"
+	"github.com/AnisaForWork/user_orders/internal/service/signature"
+	"github.com/AnisaForWork/user_orders/internal/service/stock"
+	"github.com/AnisaForWork/user_orders/internal/service/template"
+	"github.com/AnisaForWork/user_orders/internal/service/user"
 )
 
 // Repository  holds declaration of all needed repository services used to call db level logic
 type Repository interface {
 	auth.Repository
 	product.Repository
+	template.Repository
+	job.Repository
+	user.Repository
+	delivery.Repository
+	retention.Repository
+	order.Repository
+	coupon.Repository
+	exchange.Repository
+	stock.Repository
+	inventory.Repository
 }
 
 type Service struct {
 	*auth.AService
 	*product.PService
+	*template.TService
+	*job.JService
+	*user.UService
+	*delivery.DService
+	*retention.RService
+	*order.OService
+	*coupon.CService
+	*exchange.XService
+	*stock.SService
+	*inventory.IService
 }
 
-// NewRouter returns instane of business loggic
-func NewService(repo Repository, provider *token.JWTProvider, srvCfg *config.Service) *Service {
+// NewService returns instance of business logic, signer is nil if checks aren't signed,
+// mailer is nil if checks and alerts aren't sent by email
+func NewService(repo Repository, provider *token.JWTProvider, st store.CheckStore, signer *signature.Signer, mailer delivery.Mailer, assets *render.Assets, srvCfg *config.Service) *Service {
 	a := auth.NewService(repo, provider, srvCfg.Auth)
-	p := product.NewService(repo, srvCfg.Product)
+	u := user.NewService(repo)
+	renderers := render.NewRenderers(srvCfg.Render, assets)
+	t := template.NewService(repo, renderers, u, srvCfg.Template, srvCfg.Product)
+
+	assets.Preload(t.Builtin.PathToPDF, t.Builtin.PathToFont)
+	for _, path := range srvCfg.Render.Fonts {
+		assets.Preload(path)
+	}
+	var sig product.Signer
+	if signer != nil {
+		sig = signer
+	}
+	x := exchange.NewService(repo, srvCfg.Rates)
+	p := product.NewService(repo, t, st, sig, renderers, u, x, srvCfg.Product, srvCfg.Share)
+	j := job.NewService(repo, p, srvCfg.Jobs)
+	d := delivery.NewService(repo, p, u, mailer, srvCfg.Mail)
+	r := retention.NewService(repo, st, srvCfg.Retention)
+	sk := stock.NewService(repo, srvCfg.Stock)
+	o := order.NewService(repo, t, u, renderers[render.FormatPDF].(*render.PDF), x, sk.Fulfillment(), srvCfg.Product)
+	c := coupon.NewService(repo)
+	var alertMailer inventory.Mailer
+	if mailer != nil {
+		alertMailer = mailer
+	}
+	i := inventory.NewService(repo, alertMailer, srvCfg.Alerts)
 	s := &Service{
 		AService: a,
 		PService: p,
+		TService: t,
+		JService: j,
+		UService: u,
+		DService: d,
+		RService: r,
+		OService: o,
+		CService: c,
+		XService: x,
+		SService: sk,
+		IService: i,
 	}
 	return s
 }
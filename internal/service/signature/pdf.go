@@ -0,0 +1,172 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrMalformedPDF = errors.New("malformed PDF document")
+	ErrNotSigned    = errors.New("PDF document is not signed")
+)
+
+var (
+	startXrefRegex = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	sizeRegex      = regexp.MustCompile(`/Size\s+(\d+)`)
+	rootRegex      = regexp.MustCompile(`/Root\s+(\d+)\s+(\d+)\s+R`)
+	infoRegex      = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	byteRangeRegex = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	reasonRegex    = regexp.MustCompile(`/Reason\s*\(([^)]*)\)`)
+)
+
+// trailer holds parts of PDF trailer required to append incremental update
+type trailer struct {
+	xrefOffset int
+	size       int
+	root       int
+	info       string
+}
+
+func parseTrailer(pdf []byte) (*trailer, error) {
+	m := startXrefRegex.FindSubmatch(pdf)
+	if m == nil {
+		return nil, ErrMalformedPDF
+	}
+
+	xref, err := strconv.Atoi(string(m[1]))
+	if err != nil || xref >= len(pdf) {
+		return nil, ErrMalformedPDF
+	}
+
+	tail := pdf[xref:]
+	i := bytes.Index(tail, []byte("trailer"))
+	if i < 0 {
+		return nil, ErrMalformedPDF
+	}
+	dict := tail[i:]
+
+	t := &trailer{xrefOffset: xref}
+
+	sm := sizeRegex.FindSubmatch(dict)
+	rm := rootRegex.FindSubmatch(dict)
+	if sm == nil || rm == nil {
+		return nil, ErrMalformedPDF
+	}
+
+	t.size, _ = strconv.Atoi(string(sm[1]))
+	t.root, _ = strconv.Atoi(string(rm[1]))
+
+	if im := infoRegex.FindSubmatch(dict); im != nil {
+		t.info = string(im[0])
+	}
+
+	return t, nil
+}
+
+// objectOffset finds offset of object with given number using cross-reference table
+func objectOffset(pdf []byte, xrefOffset int, num int) (int, error) {
+	fields := bytes.Fields(pdf[xrefOffset:])
+	if len(fields) < 1 || string(fields[0]) != "xref" {
+		return 0, ErrMalformedPDF
+	}
+
+	for i := 1; i+1 < len(fields); {
+		if string(fields[i]) == "trailer" {
+			break
+		}
+
+		start, err1 := strconv.Atoi(string(fields[i]))
+		count, err2 := strconv.Atoi(string(fields[i+1]))
+		if err1 != nil || err2 != nil {
+			return 0, ErrMalformedPDF
+		}
+		i += 2
+
+		if num >= start && num < start+count {
+			e := i + (num-start)*3
+			if e+2 >= len(fields) || string(fields[e+2]) != "n" {
+				return 0, ErrMalformedPDF
+			}
+			return strconv.Atoi(string(fields[e]))
+		}
+
+		i += count * 3
+	}
+
+	return 0, ErrMalformedPDF
+}
+
+// objectDict returns content of dictionary object(without enclosing << >>)
+func objectDict(pdf []byte, offset int) ([]byte, error) {
+	if offset >= len(pdf) {
+		return nil, ErrMalformedPDF
+	}
+
+	obj := pdf[offset:]
+	end := bytes.Index(obj, []byte("endobj"))
+	if end < 0 {
+		return nil, ErrMalformedPDF
+	}
+	obj = obj[:end]
+
+	start := bytes.Index(obj, []byte("<<"))
+	stop := bytes.LastIndex(obj, []byte(">>"))
+	if start < 0 || stop < start {
+		return nil, ErrMalformedPDF
+	}
+
+	return obj[start+2 : stop], nil
+}
+
+// signedParts extracts signed content and signature from signed PDF
+func signedParts(pdf []byte) (content []byte, sig []byte, reason string, wholeFile bool, err error) {
+	all := byteRangeRegex.FindAllSubmatch(pdf, -1)
+	if len(all) == 0 {
+		return nil, nil, "", false, ErrNotSigned
+	}
+	m := all[len(all)-1]
+
+	var r [4]int
+	for i := range r {
+		r[i], err = strconv.Atoi(string(m[i+1]))
+		if err != nil {
+			return nil, nil, "", false, ErrMalformedPDF
+		}
+	}
+
+	if r[0] != 0 || r[1] <= 0 || r[2] <= r[1] || r[2]+r[3] > len(pdf) {
+		return nil, nil, "", false, ErrMalformedPDF
+	}
+
+	contents := pdf[r[1]:r[2]]
+	if len(contents) < 2 || contents[0] != '<' || contents[len(contents)-1] != '>' {
+		return nil, nil, "", false, ErrMalformedPDF
+	}
+
+	content = make([]byte, 0, r[1]+r[3])
+	content = append(content, pdf[:r[1]]...)
+	content = append(content, pdf[r[2]:r[2]+r[3]]...)
+
+	sigHex := contents[1 : len(contents)-1]
+	sig = make([]byte, hex.DecodedLen(len(sigHex)))
+	if _, err := hex.Decode(sig, sigHex); err != nil {
+		return nil, nil, "", false, ErrMalformedPDF
+	}
+
+	// placeholder is padded with zeros, DER encoding knows its own length
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(sig, &raw); err != nil {
+		return nil, nil, "", false, ErrMalformedPDF
+	}
+	sig = raw.FullBytes
+
+	if rm := reasonRegex.FindAllSubmatch(content, -1); rm != nil {
+		reason = string(rm[len(rm)-1][1])
+	}
+
+	return content, sig, reason, r[2]+r[3] == len(pdf), nil
+}
@@ -0,0 +1,227 @@
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+
+	"go.mozilla.org/pkcs7"
+)
+
+const (
+	// signature placeholder size in bytes, hex encoded placeholder is twice bigger
+	defaultContentsSize = 8192
+	byteRangeFormat     = "/ByteRange [0 %010d %010d %010d]"
+	byteRangeEmpty      = "/ByteRange [0 0000000000 0000000000 0000000000]"
+	pdfTimeFormat       = "20060102150405-07'00'"
+)
+
+var ErrSignatureTooLarge = errors.New("signature doesn't fit reserved space")
+
+// Signer signs PDF documents with PKCS#7 detached signature embedded into document
+// and verifies documents signed with configured certificate
+type Signer struct {
+	cert         *x509.Certificate
+	key          crypto.PrivateKey
+	roots        *x509.CertPool
+	name         string
+	contentsSize int
+}
+
+// Verification holds result of signature verification
+type Verification struct {
+	Valid     bool
+	Modified  bool
+	Reason    string
+	Signer    string
+	SignedAt  time.Time
+	Error     string
+	CheckName string
+}
+
+// NewSigner loads PEM encoded certificate and private key
+func NewSigner(cfg *config.Signature) (*Signer, error) {
+	pair, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	contentsSize := cfg.ContentsSize
+	if contentsSize <= 0 {
+		contentsSize = defaultContentsSize
+	}
+
+	s := &Signer{
+		cert:         cert,
+		key:          pair.PrivateKey,
+		roots:        roots,
+		name:         cfg.Name,
+		contentsSize: contentsSize,
+	}
+	return s, nil
+}
+
+// Sign appends to PDF incremental update with signature field,
+// checkName is saved in signature reason and used to find check record
+func (s *Signer) Sign(pdf []byte, checkName string, at time.Time) ([]byte, error) {
+	t, err := parseTrailer(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	rootOffset, err := objectOffset(pdf, t.xrefOffset, t.root)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := objectDict(pdf, rootOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(catalog, []byte("/AcroForm")) {
+		return nil, fmt.Errorf("%w: document already has form", ErrMalformedPDF)
+	}
+
+	fieldNum := t.size
+	sigNum := t.size + 1
+
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	if pdf[len(pdf)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	offsets := make(map[int]int, 3)
+
+	offsets[sigNum] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<<\n/Type /Sig\n/Filter /Adobe.PPKLite\n/SubFilter /adbe.pkcs7.detached\n", sigNum)
+	byteRangePos := buf.Len()
+	buf.WriteString(byteRangeEmpty)
+	buf.WriteString("\n/Contents ")
+	contentsPos := buf.Len()
+	buf.WriteByte('<')
+	buf.WriteString(strings.Repeat("0", s.contentsSize*2))
+	buf.WriteByte('>')
+	contentsEnd := buf.Len()
+	fmt.Fprintf(&buf, "\n/M (D:%s)\n/Name (%s)\n/Reason (Check %s)\n>>\nendobj\n",
+		at.Format(pdfTimeFormat), escapeString(s.name), escapeString(checkName))
+
+	offsets[fieldNum] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<<\n/Type /Annot\n/Subtype /Widget\n/FT /Sig\n/T (Signature1)\n/V %d 0 R\n/F 132\n/Rect [0 0 0 0]\n>>\nendobj\n",
+		fieldNum, sigNum)
+
+	offsets[t.root] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<<%s/AcroForm << /Fields [%d 0 R] /SigFlags 3 >>\n>>\nendobj\n",
+		t.root, catalog, fieldNum)
+
+	xrefOffset := buf.Len()
+	buf.WriteString("xref\n")
+	fmt.Fprintf(&buf, "%d 1\n%010d 00000 n \n", t.root, offsets[t.root])
+	fmt.Fprintf(&buf, "%d 2\n%010d 00000 n \n%010d 00000 n \n", fieldNum, offsets[fieldNum], offsets[sigNum])
+	fmt.Fprintf(&buf, "trailer\n<<\n/Size %d\n/Root %d 0 R\n", sigNum+1, t.root)
+	if t.info != "" {
+		buf.WriteString(t.info + "\n")
+	}
+	fmt.Fprintf(&buf, "/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n", t.xrefOffset, xrefOffset)
+
+	out := buf.Bytes()
+
+	byteRange := fmt.Sprintf(byteRangeFormat, contentsPos, contentsEnd, len(out)-contentsEnd)
+	copy(out[byteRangePos:], byteRange)
+
+	content := make([]byte, 0, contentsPos+len(out)-contentsEnd)
+	content = append(content, out[:contentsPos]...)
+	content = append(content, out[contentsEnd:]...)
+
+	sig, err := s.sign(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if hex.EncodedLen(len(sig)) > s.contentsSize*2 {
+		return nil, ErrSignatureTooLarge
+	}
+	hex.Encode(out[contentsPos+1:], sig)
+
+	return out, nil
+}
+
+// Verify checks that PDF is signed by configured certificate and wasn't modified after signing
+func (s *Signer) Verify(pdf []byte) (*Verification, error) {
+	content, sig, reason, wholeFile, err := signedParts(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Verification{
+		Modified:  !wholeFile,
+		Reason:    reason,
+		CheckName: strings.TrimPrefix(reason, "Check "),
+	}
+
+	p7, err := pkcs7.Parse(sig)
+	if err != nil {
+		res.Error = "signature can't be parsed"
+		return res, nil
+	}
+	p7.Content = content
+
+	if signer := p7.GetOnlySigner(); signer != nil {
+		res.Signer = signer.Subject.String()
+	}
+
+	var signedAt time.Time
+	if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &signedAt); err == nil {
+		res.SignedAt = signedAt
+	}
+
+	if err := p7.VerifyWithChain(s.roots); err != nil {
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	res.Valid = !res.Modified
+	if res.Modified {
+		res.Error = "document was modified after signing"
+	}
+
+	return res, nil
+}
+
+func (s *Signer) sign(content []byte) ([]byte, error) {
+	sd, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, err
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	if err := sd.AddSigner(s.cert, s.key, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, err
+	}
+	sd.Detach()
+
+	return sd.Finish()
+}
+
+// escapeString escapes PDF literal string special characters
+func escapeString(str string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(str)
+}
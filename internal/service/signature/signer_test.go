@@ -0,0 +1,178 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+
+	"github.com/signintech/gopdf"
+)
+
+// newTestSigner returns signer using freshly generated self-signed certificate
+func newTestSigner(t *testing.T, cn string) *Signer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"User Orders"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSigner(&config.Signature{CertFile: certFile, KeyFile: keyFile, Name: "Test Shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testPDF(t *testing.T) []byte {
+	t.Helper()
+
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	pdf.AddPage()
+	pdf.Line(10, 10, 100, 100)
+
+	b, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSignVerify(t *testing.T) {
+	s := newTestSigner(t, "checks.test")
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	signed, err := s.Sign(testPDF(t), "doc_123_2024.pdf", at)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	v, err := s.Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if !v.Valid || v.Modified || v.Error != "" {
+		t.Fatalf("signed document isn't valid: %+v", v)
+	}
+	if v.CheckName != "doc_123_2024.pdf" {
+		t.Errorf("CheckName = %q", v.CheckName)
+	}
+	if !strings.Contains(v.Signer, "CN=checks.test") {
+		t.Errorf("Signer = %q", v.Signer)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	s := newTestSigner(t, "checks.test")
+
+	signed, err := s.Sign(testPDF(t), "doc_1.pdf", time.Now())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// change one byte of signed content in the original document
+	i := bytes.Index(signed, []byte("/MediaBox"))
+	if i < 0 {
+		t.Fatal("test document has no MediaBox")
+	}
+	tampered := bytes.Clone(signed)
+	tampered[i+1] = 'X'
+
+	v, err := s.Verify(tampered)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if v.Valid || v.Error == "" {
+		t.Fatalf("tampered document is valid: %+v", v)
+	}
+}
+
+func TestVerifyAppended(t *testing.T) {
+	s := newTestSigner(t, "checks.test")
+
+	signed, err := s.Sign(testPDF(t), "doc_1.pdf", time.Now())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// incremental update added after signing isn't covered by signature
+	appended := append(bytes.Clone(signed), []byte("1 0 obj\n<< >>\nendobj\n")...)
+
+	v, err := s.Verify(appended)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if v.Valid || !v.Modified {
+		t.Fatalf("document modified after signing is valid: %+v", v)
+	}
+}
+
+func TestVerifyOtherCertificate(t *testing.T) {
+	signed, err := newTestSigner(t, "other.test").Sign(testPDF(t), "doc_1.pdf", time.Now())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	v, err := newTestSigner(t, "checks.test").Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if v.Valid || v.Error == "" {
+		t.Fatalf("document signed by unknown certificate is valid: %+v", v)
+	}
+}
+
+func TestVerifyNotSigned(t *testing.T) {
+	s := newTestSigner(t, "checks.test")
+
+	if _, err := s.Verify(testPDF(t)); !errors.Is(err, ErrNotSigned) {
+		t.Fatalf("Verify of unsigned document returned %v, want ErrNotSigned", err)
+	}
+}
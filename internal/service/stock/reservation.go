@@ -0,0 +1,84 @@
+package stock
+
+import (
+	"context"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reservation statuses, active reservation holds goods until order is paid(sold), cancelled(released)
+// or reservation expires(expired)
+const (
+	ReservationActive   = mysql.ReservationActive
+	ReservationSold     = "sold"
+	ReservationReleased = "released"
+	ReservationExpired  = "expired"
+)
+
+// DefaultReservationTTL is time placed order holds its goods when it isn't configured
+const DefaultReservationTTL = 30 * time.Minute
+
+// Fulfillment is rules orders take goods from stock by
+type Fulfillment struct {
+	Policy         Policy
+	Strategy       Strategy
+	ReservationTTL time.Duration
+}
+
+// Fulfillment returns rules orders take goods from stock by
+func (s *SService) Fulfillment() Fulfillment {
+	return Fulfillment{
+		Policy:         s.Policy,
+		Strategy:       s.Strategy,
+		ReservationTTL: s.ReservationTTL,
+	}
+}
+
+// RunReservations releases expired reservations every sweep interval until context is canceled,
+// zero interval disables sweeper
+func (s *SService) RunReservations(ctx context.Context) {
+	if s.SweepInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.ExpireReservations(ctx)
+		if err != nil {
+			log.WithFields(log.Fields{"expired": n}).WithError(err).Error("Error releasing expired reservations")
+			continue
+		}
+		if n > 0 {
+			log.WithFields(log.Fields{"expired": n}).Info("Expired reservations released")
+		}
+	}
+}
+
+// ExpireReservations releases reservations that expired by now in batches and returns their number,
+// goods they held become available for other orders and movements
+func (s *SService) ExpireReservations(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	var total int64
+	for {
+		n, err := s.Repo.ExpireStockReservations(ctx, ReservationExpired, now, s.SweepBatch)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(s.SweepBatch) {
+			return total, nil
+		}
+	}
+}
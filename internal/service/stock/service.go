@@ -0,0 +1,296 @@
+package stock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	AddStockMovement(ctx context.Context, m mysql.StockMovement, check func(warehouse string, stock, reserved int64) error, login string) (int64, error)
+	ProductStock(ctx context.Context, barcode string, login string) (*mysql.StockLevel, error)
+	ProductWarehouseStock(ctx context.Context, barcode string, login string) ([]mysql.WarehouseLevel, error)
+	UserStock(ctx context.Context, amount int, offset int, login string) ([]mysql.StockLevel, error)
+	StockMovements(ctx context.Context, barcode string, amount int, offset int, login string) ([]mysql.StockMovement, error)
+	CreateWarehouse(ctx context.Context, w mysql.Warehouse, login string) (int64, error)
+	UserWarehouses(ctx context.Context, login string) ([]mysql.Warehouse, error)
+	UserWarehouse(ctx context.Context, code string, login string) (*mysql.Warehouse, error)
+	ArchiveWarehouse(ctx context.Context, code string, check func(products int) error, login string) error
+	WarehouseStock(ctx context.Context, code string, amount int, offset int, login string) ([]mysql.StockLevel, error)
+	CreateTransfer(ctx context.Context, t mysql.Transfer, items []mysql.TransferItem,
+		check func(fromID, toID int64, stock []mysql.WarehouseStock) ([]mysql.StockMovement, error), login string) (int64, error)
+	UserTransfers(ctx context.Context, amount int, offset int, login string) ([]mysql.Transfer, error)
+	UserTransfer(ctx context.Context, id int64, login string) (*mysql.Transfer, []mysql.TransferItem, error)
+	ExpireStockReservations(ctx context.Context, status string, before time.Time, limit int) (int64, error)
+}
+
+// Kind is reason of stock movement
+type Kind string
+
+// Movement kinds, receipts and returns add stock, sales take it and adjustments correct it either way,
+// transfers move it between warehouses and are made only by transfer documents
+const (
+	KindReceipt    Kind = "receipt"
+	KindSale       Kind = "sale"
+	KindAdjustment Kind = "adjustment"
+	KindReturn     Kind = "return"
+	KindTransfer   Kind = "transfer"
+)
+
+// Policy is behavior when movement would make stock negative
+type Policy string
+
+// Negative stock policies
+const (
+	PolicyReject Policy = "reject"
+	PolicyAllow  Policy = "allow"
+)
+
+var (
+	ErrUnknownKind = errors.New("unknown stock movement kind")
+	ErrQuantity    = errors.New("stock movement quantity is out of range")
+	ErrOutOfStock  = errors.New("not enough stock")
+)
+
+// Movement is service level model of stock ledger entry, quantity is signed change of stock in warehouse(code),
+// OrderID is set for movements made by order status changes and TransferID for movements between warehouses
+type Movement struct {
+	ID         int64
+	Barcode    string
+	Warehouse  string
+	Kind       Kind
+	Quantity   int64
+	OrderID    int64
+	TransferID int64
+	Note       string
+	Actor      string
+	Created    time.Time
+}
+
+// Level is current stock of product, Reserved is part of it held by active reservations and Available is the rest,
+// Updated is time of its last movement, Warehouses is stock in every active warehouse and is set only for single product
+type Level struct {
+	Barcode    string
+	Name       string
+	Stock      int64
+	Reserved   int64
+	Available  int64
+	Updated    *time.Time
+	Warehouses []WarehouseLevel
+}
+
+// WarehouseLevel is current stock of product in warehouse
+type WarehouseLevel struct {
+	Code      string
+	Name      string
+	Stock     int64
+	Reserved  int64
+	Available int64
+}
+
+// SService struct implements inventory tracking
+type SService struct {
+	Repo           Repository
+	Policy         Policy
+	Strategy       Strategy
+	ReservationTTL time.Duration
+	SweepInterval  time.Duration
+	SweepBatch     int
+}
+
+// NewService returns stock service, unknown negative stock policy falls back to reject
+// and unknown fulfillment strategy to first warehouse
+func NewService(repo Repository, cfg *config.Stock) *SService {
+	s := &SService{
+		Repo:           repo,
+		Policy:         ParsePolicy(cfg.Negative),
+		Strategy:       ParseStrategy(cfg.Strategy),
+		ReservationTTL: cfg.ReservationTTL,
+		SweepInterval:  cfg.SweepInterval,
+		SweepBatch:     cfg.SweepBatch,
+	}
+	if s.ReservationTTL <= 0 {
+		s.ReservationTTL = DefaultReservationTTL
+	}
+	if s.SweepBatch <= 0 {
+		s.SweepBatch = 500
+	}
+	return s
+}
+
+// ParsePolicy returns negative stock policy with given name, stock can't go negative unless it's allowed explicitly
+func ParsePolicy(name string) Policy {
+	switch p := Policy(name); p {
+	case PolicyReject, PolicyAllow:
+		return p
+	case "":
+	default:
+		log.WithFields(log.Fields{"policy": name}).Warn("Unknown negative stock policy, negative stock is rejected")
+	}
+	return PolicyReject
+}
+
+// Check returns ErrOutOfStock if policy rejects negative stock and stock changed by delta would be below zero,
+// movements that don't take stock are always allowed
+func (p Policy) Check(stock, delta int64) error {
+	if p == PolicyAllow || delta >= 0 || stock+delta >= 0 {
+		return nil
+	}
+	return ErrOutOfStock
+}
+
+// PostMovement records movement of user product in warehouse with code m.Warehouse(warehouse with lowest priority
+// when it's empty), quantity of receipts, sales and returns is positive amount of goods and adjustments take
+// signed change of stock; movements taking goods are checked against stock that isn't held by reservations;
+// returns movement with signed quantity
+func (s *SService) PostMovement(ctx context.Context, barcode string, m Movement, login string) (*Movement, error) {
+	delta, err := Delta(m.Kind, m.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	dbModel := mysql.StockMovement{
+		Barcode:   barcode,
+		Warehouse: NormalizeCode(m.Warehouse),
+		Kind:      string(m.Kind),
+		Quantity:  delta,
+		Note:      m.Note,
+	}
+
+	var warehouse string
+	id, err := s.Repo.AddStockMovement(ctx, dbModel, func(code string, stock, reserved int64) error {
+		warehouse = code
+		return s.Policy.Check(stock-reserved, delta)
+	}, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Movement{
+		ID:        id,
+		Barcode:   barcode,
+		Warehouse: warehouse,
+		Kind:      m.Kind,
+		Quantity:  delta,
+		Note:      m.Note,
+		Actor:     login,
+		Created:   time.Now(),
+	}
+	return res, nil
+}
+
+// ProductStock returns current stock of user product in total and in every active warehouse
+func (s *SService) ProductStock(ctx context.Context, barcode string, login string) (*Level, error) {
+	lvl, err := s.Repo.ProductStock(ctx, barcode, login)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := s.Repo.ProductWarehouseStock(ctx, barcode, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newLevel(*lvl)
+	res.Warehouses = make([]WarehouseLevel, len(levels))
+	for i, l := range levels {
+		res.Warehouses[i] = WarehouseLevel{
+			Code:      l.Code,
+			Name:      l.Name,
+			Stock:     l.Stock,
+			Reserved:  l.Reserved,
+			Available: l.Stock - l.Reserved,
+		}
+	}
+	return &res, nil
+}
+
+// UserStock returns current stock of user products
+// productsPerPage - number of products on page
+// page - next page with products
+func (s *SService) UserStock(ctx context.Context, page, productsPerPage int, login string) ([]Level, error) {
+	levels, err := s.Repo.UserStock(ctx, productsPerPage, (page-1)*productsPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Level, len(levels))
+	for i, l := range levels {
+		res[i] = newLevel(l)
+	}
+	return res, nil
+}
+
+// StockMovements returns movements of user product, newest first
+// movementsPerPage - number of movements on page
+// page - next page with movements
+func (s *SService) StockMovements(ctx context.Context, barcode string, page, movementsPerPage int, login string) ([]Movement, error) {
+	// product existence and ownership is checked separately so empty page is distinguishable from foreign product
+	if _, err := s.Repo.ProductStock(ctx, barcode, login); err != nil {
+		return nil, err
+	}
+
+	movements, err := s.Repo.StockMovements(ctx, barcode, movementsPerPage, (page-1)*movementsPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Movement, len(movements))
+	for i, m := range movements {
+		res[i] = Movement{
+			ID:         m.ID,
+			Barcode:    m.Barcode,
+			Warehouse:  m.Warehouse,
+			Kind:       Kind(m.Kind),
+			Quantity:   m.Quantity,
+			OrderID:    m.OrderID.Int64,
+			TransferID: m.TransferID.Int64,
+			Note:       m.Note,
+			Actor:      m.Actor,
+			Created:    m.Created,
+		}
+	}
+	return res, nil
+}
+
+// Delta returns signed change of stock made by movement of given kind and quantity
+func Delta(kind Kind, quantity int64) (int64, error) {
+	switch kind {
+	case KindReceipt, KindReturn:
+		if quantity < 1 {
+			return 0, ErrQuantity
+		}
+		return quantity, nil
+	case KindSale:
+		if quantity < 1 {
+			return 0, ErrQuantity
+		}
+		return -quantity, nil
+	case KindAdjustment:
+		if quantity == 0 {
+			return 0, ErrQuantity
+		}
+		return quantity, nil
+	}
+	return 0, ErrUnknownKind
+}
+
+func newLevel(l mysql.StockLevel) Level {
+	res := Level{
+		Barcode:   l.Barcode,
+		Name:      l.Name,
+		Stock:     l.Stock,
+		Reserved:  l.Reserved,
+		Available: l.Stock - l.Reserved,
+	}
+	if l.Updated.Valid {
+		res.Updated = &l.Updated.Time
+	}
+	return res
+}
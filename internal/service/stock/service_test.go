@@ -0,0 +1,65 @@
+package stock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDelta(t *testing.T) {
+	cases := []struct {
+		kind     Kind
+		quantity int64
+		want     int64
+		err      error
+	}{
+		{KindReceipt, 5, 5, nil},
+		{KindReceipt, 0, 0, ErrQuantity},
+		{KindReceipt, -5, 0, ErrQuantity},
+		{KindReturn, 2, 2, nil},
+		{KindReturn, -2, 0, ErrQuantity},
+		{KindSale, 3, -3, nil},
+		{KindSale, 0, 0, ErrQuantity},
+		{KindSale, -3, 0, ErrQuantity},
+		{KindAdjustment, 4, 4, nil},
+		{KindAdjustment, -4, -4, nil},
+		{KindAdjustment, 0, 0, ErrQuantity},
+		{KindTransfer, 1, 0, ErrUnknownKind},
+		{"gift", 1, 0, ErrUnknownKind},
+	}
+
+	for _, c := range cases {
+		got, err := Delta(c.kind, c.quantity)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("Delta(%s, %d) = %d, %v; want %d, %v", c.kind, c.quantity, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	cases := []struct {
+		policy       Policy
+		stock, delta int64
+		err          error
+	}{
+		{PolicyReject, 5, -5, nil},
+		{PolicyReject, 5, -6, ErrOutOfStock},
+		{PolicyReject, -3, 1, nil},
+		{PolicyAllow, 5, -6, nil},
+	}
+
+	for _, c := range cases {
+		if err := c.policy.Check(c.stock, c.delta); !errors.Is(err, c.err) {
+			t.Errorf("%s.Check(%d, %d) = %v, want %v", c.policy, c.stock, c.delta, err, c.err)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	cases := map[string]Policy{"reject": PolicyReject, "allow": PolicyAllow, "": PolicyReject, "ignore": PolicyReject}
+
+	for name, want := range cases {
+		if got := ParsePolicy(name); got != want {
+			t.Errorf("ParsePolicy(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
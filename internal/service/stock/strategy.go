@@ -0,0 +1,212 @@
+package stock
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Strategy is rule choosing warehouse order takes its goods from, whole order is taken from one warehouse,
+// only stock that isn't held by reservations is taken into account
+type Strategy string
+
+// Fulfillment strategies, first takes goods from warehouse with lowest priority, priority from first warehouse
+// by priority that has all ordered goods and most_stock from warehouse that has all ordered goods and the most of them;
+// when no warehouse has all ordered goods, priority uses first warehouse and most_stock the one with the most goods
+const (
+	StrategyFirst     Strategy = "first"
+	StrategyPriority  Strategy = "priority"
+	StrategyMostStock Strategy = "most_stock"
+)
+
+var ErrNoWarehouse = errors.New("user has no active warehouse")
+
+// ParseStrategy returns fulfillment strategy with given name, unknown names fall back to first warehouse
+func ParseStrategy(name string) Strategy {
+	switch s := Strategy(name); s {
+	case StrategyFirst, StrategyPriority, StrategyMostStock:
+		return s
+	case "":
+	default:
+		log.WithFields(log.Fields{"strategy": name}).Warn("Unknown fulfillment strategy, first warehouse is used")
+	}
+	return StrategyFirst
+}
+
+type warehouseProduct struct {
+	warehouseID int64
+	barcode     string
+}
+
+// choose returns id of warehouse goods with given needed quantities are taken from, stock is available stock of products
+func (s Strategy) choose(warehouses []mysql.Warehouse, stock map[warehouseProduct]int64, need map[string]int64) (int64, error) {
+	if len(warehouses) == 0 {
+		return 0, ErrNoWarehouse
+	}
+	if s != StrategyPriority && s != StrategyMostStock {
+		return warehouses[0].ID, nil
+	}
+
+	chosen, chosenCovers, chosenTotal := warehouses[0].ID, false, int64(0)
+	for i, w := range warehouses {
+		covers, total := true, int64(0)
+		for barcode, n := range need {
+			st := stock[warehouseProduct{w.ID, barcode}]
+			if st < n {
+				covers = false
+			}
+			if st > 0 {
+				total += st
+			}
+		}
+
+		if s == StrategyPriority {
+			if covers {
+				return w.ID, nil
+			}
+			continue
+		}
+
+		if i == 0 || (covers && !chosenCovers) || (covers == chosenCovers && total > chosenTotal) {
+			chosen, chosenCovers, chosenTotal = w.ID, covers, total
+		}
+	}
+	return chosen, nil
+}
+
+// remaining returns quantities of order products that order neither took from stock nor holds by active reservations,
+// line ids by barcode and available stock of products in warehouses
+func remaining(st *mysql.OrderStock) (map[string]int64, map[string]int64, map[warehouseProduct]int64) {
+	need := make(map[string]int64, len(st.Items))
+	lines := make(map[string]int64, len(st.Items))
+	for _, it := range st.Items {
+		need[it.Barcode] += int64(it.Quantity)
+		lines[it.Barcode] = it.ID
+	}
+
+	available := make(map[warehouseProduct]int64, len(st.Levels))
+	for _, l := range st.Levels {
+		need[l.Barcode] -= l.Sold
+		available[warehouseProduct{l.WarehouseID, l.Barcode}] = l.Available()
+	}
+	for _, r := range st.Reservations {
+		need[r.Barcode] -= r.Quantity
+	}
+	for barcode, n := range need {
+		if n <= 0 {
+			delete(need, barcode)
+		}
+	}
+	return need, lines, available
+}
+
+// take chooses warehouse by strategy and checks that its available stock has needed quantities, calls add
+// for every product in order of order lines
+func take(st *mysql.OrderStock, policy Policy, strategy Strategy, add func(warehouseID int64, barcode string, n int64)) error {
+	need, _, available := remaining(st)
+	if len(need) == 0 {
+		return nil
+	}
+
+	warehouseID, err := strategy.choose(st.Warehouses, available, need)
+	if err != nil {
+		return err
+	}
+
+	for _, it := range st.Items {
+		n, ok := need[it.Barcode]
+		if !ok {
+			continue
+		}
+		if err := policy.Check(available[warehouseProduct{warehouseID, it.Barcode}], -n); err != nil {
+			return err
+		}
+		add(warehouseID, it.Barcode, n)
+		delete(need, it.Barcode)
+	}
+	return nil
+}
+
+// Reserve returns reservations holding quantities of order lines in available stock of warehouse chosen by strategy
+// until expires, quantities order already holds or took from stock are skipped
+func Reserve(st *mysql.OrderStock, policy Policy, strategy Strategy, expires time.Time) (*mysql.StockChange, error) {
+	_, lines, _ := remaining(st)
+
+	ch := &mysql.StockChange{}
+	err := take(st, policy, strategy, func(warehouseID int64, barcode string, n int64) {
+		ch.Reserve = append(ch.Reserve, mysql.StockReservation{
+			OrderItemID: lines[barcode],
+			Barcode:     barcode,
+			WarehouseID: warehouseID,
+			Quantity:    n,
+			Expires:     expires,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Sell returns sale movements converting active reservations of order, quantities order doesn't hold anymore
+// are taken from available stock of warehouse chosen by strategy, quantities already taken by order are skipped
+func Sell(st *mysql.OrderStock, policy Policy, strategy Strategy) (*mysql.StockChange, error) {
+	ch := &mysql.StockChange{}
+	for _, r := range st.Reservations {
+		ch.Movements = append(ch.Movements, mysql.StockMovement{
+			Barcode:     r.Barcode,
+			WarehouseID: r.WarehouseID,
+			Kind:        string(KindSale),
+			Quantity:    -r.Quantity,
+		})
+		r.Status = ReservationSold
+		ch.Close = append(ch.Close, r)
+	}
+
+	err := take(st, policy, strategy, func(warehouseID int64, barcode string, n int64) {
+		ch.Movements = append(ch.Movements, mysql.StockMovement{
+			Barcode:     barcode,
+			WarehouseID: warehouseID,
+			Kind:        string(KindSale),
+			Quantity:    -n,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Restock releases active reservations of order and returns return movements putting quantities order took
+// from stock back to warehouses they were taken from, goods taken from archived warehouse are returned to first active one
+func Restock(st *mysql.OrderStock) *mysql.StockChange {
+	active := make(map[int64]bool, len(st.Warehouses))
+	for _, w := range st.Warehouses {
+		active[w.ID] = true
+	}
+
+	ch := &mysql.StockChange{}
+	for _, r := range st.Reservations {
+		r.Status = ReservationReleased
+		ch.Close = append(ch.Close, r)
+	}
+	for _, l := range st.Levels {
+		if l.Sold <= 0 {
+			continue
+		}
+		warehouseID := l.WarehouseID
+		if !active[warehouseID] && len(st.Warehouses) > 0 {
+			warehouseID = st.Warehouses[0].ID
+		}
+		ch.Movements = append(ch.Movements, mysql.StockMovement{
+			Barcode:     l.Barcode,
+			WarehouseID: warehouseID,
+			Kind:        string(KindReturn),
+			Quantity:    l.Sold,
+		})
+	}
+	return ch
+}
@@ -0,0 +1,232 @@
+package stock
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+// testStock returns order of 2 pens and 1 pencil with warehouses 1 and 2 active in order of priority,
+// first one has 1 pen and 5 pencils, second one has 10 pens and 10 pencils
+func testStock() *mysql.OrderStock {
+	return &mysql.OrderStock{
+		Items: []mysql.OrderItem{
+			{ID: 11, Barcode: "pen", Quantity: 2},
+			{ID: 12, Barcode: "pencil", Quantity: 1},
+		},
+		Warehouses: []mysql.Warehouse{{ID: 1, Code: "main"}, {ID: 2, Code: "backup"}},
+		Levels: []mysql.WarehouseStock{
+			{WarehouseID: 1, Barcode: "pen", Stock: 1},
+			{WarehouseID: 1, Barcode: "pencil", Stock: 5},
+			{WarehouseID: 2, Barcode: "pen", Stock: 10},
+			{WarehouseID: 2, Barcode: "pencil", Stock: 10},
+		},
+	}
+}
+
+func TestStrategyChoose(t *testing.T) {
+	warehouses := []mysql.Warehouse{{ID: 1}, {ID: 2}, {ID: 3}}
+	stock := map[warehouseProduct]int64{
+		{1, "pen"}: 1, {1, "pencil"}: 5,
+		{2, "pen"}: 10, {2, "pencil"}: 10,
+		{3, "pen"}: 50, {3, "pencil"}: 0,
+	}
+
+	cases := []struct {
+		strategy Strategy
+		need     map[string]int64
+		want     int64
+	}{
+		{StrategyFirst, map[string]int64{"pen": 2}, 1},
+		{StrategyPriority, map[string]int64{"pen": 2}, 2},
+		{StrategyPriority, map[string]int64{"pencil": 1}, 1},
+		{StrategyPriority, map[string]int64{"pen": 100}, 1},
+		{StrategyMostStock, map[string]int64{"pen": 2, "pencil": 1}, 2},
+		{StrategyMostStock, map[string]int64{"pen": 2}, 3},
+		{StrategyMostStock, map[string]int64{"pen": 100, "pencil": 100}, 3},
+	}
+
+	for _, c := range cases {
+		got, err := c.strategy.choose(warehouses, stock, c.need)
+		if err != nil || got != c.want {
+			t.Errorf("%s.choose(%v) = %d, %v; want %d", c.strategy, c.need, got, err, c.want)
+		}
+	}
+
+	if _, err := StrategyPriority.choose(nil, stock, map[string]int64{"pen": 1}); !errors.Is(err, ErrNoWarehouse) {
+		t.Errorf("choose without warehouses returned %v, want ErrNoWarehouse", err)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	cases := map[string]Strategy{
+		"first":      StrategyFirst,
+		"priority":   StrategyPriority,
+		"most_stock": StrategyMostStock,
+		"":           StrategyFirst,
+		"random":     StrategyFirst,
+	}
+
+	for name, want := range cases {
+		if got := ParseStrategy(name); got != want {
+			t.Errorf("ParseStrategy(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestReserve(t *testing.T) {
+	expires := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	cases := []struct {
+		strategy Strategy
+		policy   Policy
+		want     []mysql.StockReservation
+		err      error
+	}{
+		{StrategyFirst, PolicyReject, nil, ErrOutOfStock},
+		{StrategyFirst, PolicyAllow, []mysql.StockReservation{
+			{OrderItemID: 11, Barcode: "pen", WarehouseID: 1, Quantity: 2, Expires: expires},
+			{OrderItemID: 12, Barcode: "pencil", WarehouseID: 1, Quantity: 1, Expires: expires},
+		}, nil},
+		{StrategyPriority, PolicyReject, []mysql.StockReservation{
+			{OrderItemID: 11, Barcode: "pen", WarehouseID: 2, Quantity: 2, Expires: expires},
+			{OrderItemID: 12, Barcode: "pencil", WarehouseID: 2, Quantity: 1, Expires: expires},
+		}, nil},
+		{StrategyMostStock, PolicyReject, []mysql.StockReservation{
+			{OrderItemID: 11, Barcode: "pen", WarehouseID: 2, Quantity: 2, Expires: expires},
+			{OrderItemID: 12, Barcode: "pencil", WarehouseID: 2, Quantity: 1, Expires: expires},
+		}, nil},
+	}
+
+	for _, c := range cases {
+		ch, err := Reserve(testStock(), c.policy, c.strategy, expires)
+		if !errors.Is(err, c.err) {
+			t.Errorf("Reserve with %s/%s returned %v, want %v", c.strategy, c.policy, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ch.Reserve, c.want) || len(ch.Movements) != 0 || len(ch.Close) != 0 {
+			t.Errorf("Reserve with %s/%s = %+v, want reservations %+v", c.strategy, c.policy, ch, c.want)
+		}
+	}
+}
+
+func TestReserveSkipsHeldGoods(t *testing.T) {
+	st := testStock()
+	st.Reservations = []mysql.StockReservation{{ID: 7, Barcode: "pen", WarehouseID: 2, Quantity: 2, Status: ReservationActive}}
+	st.Levels[2].Reserved = 2
+
+	ch, err := Reserve(st, PolicyReject, StrategyPriority, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []mysql.StockReservation{{OrderItemID: 12, Barcode: "pencil", WarehouseID: 1, Quantity: 1}}
+	if !reflect.DeepEqual(ch.Reserve, want) {
+		t.Fatalf("Reserve = %+v, want %+v", ch.Reserve, want)
+	}
+}
+
+func TestReserveReservedStockIsNotAvailable(t *testing.T) {
+	st := testStock()
+	st.Levels[2].Reserved = 9
+	st.Levels[3].Reserved = 10
+
+	if _, err := Reserve(st, PolicyReject, StrategyPriority, time.Time{}); !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("Reserve returned %v, want ErrOutOfStock", err)
+	}
+}
+
+func TestReserveNoWarehouse(t *testing.T) {
+	st := testStock()
+	st.Warehouses = nil
+
+	if _, err := Reserve(st, PolicyAllow, StrategyFirst, time.Time{}); !errors.Is(err, ErrNoWarehouse) {
+		t.Fatalf("Reserve returned %v, want ErrNoWarehouse", err)
+	}
+}
+
+func TestSell(t *testing.T) {
+	st := testStock()
+	st.Reservations = []mysql.StockReservation{{ID: 7, Barcode: "pen", WarehouseID: 2, Quantity: 2, Status: ReservationActive}}
+	st.Levels[2].Reserved = 2
+
+	ch, err := Sell(st, PolicyReject, StrategyFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reserved pens are sold from their warehouse, pencil isn't held and is taken by strategy
+	wantMovements := []mysql.StockMovement{
+		{Barcode: "pen", WarehouseID: 2, Kind: string(KindSale), Quantity: -2},
+		{Barcode: "pencil", WarehouseID: 1, Kind: string(KindSale), Quantity: -1},
+	}
+	if !reflect.DeepEqual(ch.Movements, wantMovements) {
+		t.Errorf("Sell movements = %+v, want %+v", ch.Movements, wantMovements)
+	}
+
+	if len(ch.Close) != 1 || ch.Close[0].ID != 7 || ch.Close[0].Status != ReservationSold {
+		t.Errorf("Sell closed %+v, want reservation 7 sold", ch.Close)
+	}
+	if len(ch.Reserve) != 0 {
+		t.Errorf("Sell made reservations %+v", ch.Reserve)
+	}
+}
+
+func TestSellSkipsTakenGoods(t *testing.T) {
+	st := testStock()
+	st.Levels[2].Sold = 2
+	st.Levels[1].Sold = 1
+
+	ch, err := Sell(st, PolicyReject, StrategyFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ch.Movements) != 0 || len(ch.Close) != 0 {
+		t.Fatalf("Sell of order that took all goods = %+v", ch)
+	}
+}
+
+func TestSellOutOfStock(t *testing.T) {
+	st := testStock()
+	st.Levels[3].Stock = 0
+	st.Levels[1].Stock = 0
+
+	if _, err := Sell(st, PolicyReject, StrategyMostStock); !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("Sell returned %v, want ErrOutOfStock", err)
+	}
+}
+
+func TestRestock(t *testing.T) {
+	st := testStock()
+	st.Reservations = []mysql.StockReservation{{ID: 7, Barcode: "pencil", WarehouseID: 1, Quantity: 1, Status: ReservationActive}}
+	st.Levels[2].Sold = 2
+	// pencil was sold from archived warehouse
+	st.Levels = append(st.Levels, mysql.WarehouseStock{WarehouseID: 3, Barcode: "pencil", Stock: 4, Sold: 1})
+
+	ch := Restock(st)
+
+	wantMovements := []mysql.StockMovement{
+		{Barcode: "pen", WarehouseID: 2, Kind: string(KindReturn), Quantity: 2},
+		{Barcode: "pencil", WarehouseID: 1, Kind: string(KindReturn), Quantity: 1},
+	}
+	if !reflect.DeepEqual(ch.Movements, wantMovements) {
+		t.Errorf("Restock movements = %+v, want %+v", ch.Movements, wantMovements)
+	}
+
+	if len(ch.Close) != 1 || ch.Close[0].ID != 7 || ch.Close[0].Status != ReservationReleased {
+		t.Errorf("Restock closed %+v, want reservation 7 released", ch.Close)
+	}
+}
+
+func TestRestockNothingTaken(t *testing.T) {
+	ch := Restock(testStock())
+	if len(ch.Movements) != 0 || len(ch.Close) != 0 || len(ch.Reserve) != 0 {
+		t.Fatalf("Restock of order that took nothing = %+v", ch)
+	}
+}
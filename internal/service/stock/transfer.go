@@ -0,0 +1,153 @@
+package stock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+// MaxTransferItems is max number of lines in one transfer
+const MaxTransferItems = 100
+
+var (
+	ErrSameWarehouse = errors.New("transfer source and destination are the same warehouse")
+	ErrTransferItems = errors.New("transfer should have 1-100 lines with different products")
+)
+
+// Transfer is service level model of document moving products between warehouses(codes) of user,
+// Lines is number of its items when they aren't loaded
+type Transfer struct {
+	ID      int64
+	From    string
+	To      string
+	Note    string
+	Actor   string
+	Items   []TransferItem
+	Lines   int
+	Created time.Time
+}
+
+// TransferItem is quantity of product moved by transfer
+type TransferItem struct {
+	Barcode  string
+	Quantity int64
+}
+
+// CreateTransfer moves quantities of user products from one warehouse to another atomically, every line
+// is recorded as transfer movement taking goods from source warehouse and another one adding them to destination,
+// transfer is rejected when it takes goods held by reservations or makes stock of source warehouse negative
+// unless negative stock is allowed
+func (s *SService) CreateTransfer(ctx context.Context, t Transfer, login string) (*Transfer, error) {
+	t.From = NormalizeCode(t.From)
+	t.To = NormalizeCode(t.To)
+	if t.From == t.To {
+		return nil, ErrSameWarehouse
+	}
+
+	if len(t.Items) == 0 || len(t.Items) > MaxTransferItems {
+		return nil, ErrTransferItems
+	}
+
+	seen := make(map[string]bool, len(t.Items))
+	items := make([]mysql.TransferItem, len(t.Items))
+	for i, it := range t.Items {
+		if seen[it.Barcode] {
+			return nil, ErrTransferItems
+		}
+		seen[it.Barcode] = true
+
+		if it.Quantity < 1 {
+			return nil, ErrQuantity
+		}
+		items[i] = mysql.TransferItem{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		}
+	}
+
+	dbModel := mysql.Transfer{
+		From: t.From,
+		To:   t.To,
+		Note: t.Note,
+	}
+
+	id, err := s.Repo.CreateTransfer(ctx, dbModel, items, func(fromID, toID int64, stock []mysql.WarehouseStock) ([]mysql.StockMovement, error) {
+		movements := make([]mysql.StockMovement, 0, len(items)*2)
+		for i, it := range items {
+			if err := s.Policy.Check(stock[i].Available(), -it.Quantity); err != nil {
+				return nil, err
+			}
+			movements = append(movements,
+				mysql.StockMovement{
+					Barcode:     it.Barcode,
+					WarehouseID: fromID,
+					Kind:        string(KindTransfer),
+					Quantity:    -it.Quantity,
+				},
+				mysql.StockMovement{
+					Barcode:     it.Barcode,
+					WarehouseID: toID,
+					Kind:        string(KindTransfer),
+					Quantity:    it.Quantity,
+				})
+		}
+		return movements, nil
+	}, login)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = id
+	t.Actor = login
+	t.Lines = len(t.Items)
+	t.Created = time.Now()
+	return &t, nil
+}
+
+// UserTransfers returns transfers of user without their lines, newest first
+// transfersPerPage - number of transfers on page
+// page - next page with transfers
+func (s *SService) UserTransfers(ctx context.Context, page, transfersPerPage int, login string) ([]Transfer, error) {
+	transfers, err := s.Repo.UserTransfers(ctx, transfersPerPage, (page-1)*transfersPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Transfer, len(transfers))
+	for i, t := range transfers {
+		res[i] = newTransfer(t)
+	}
+	return res, nil
+}
+
+// UserTransfer returns transfer of user with its lines
+func (s *SService) UserTransfer(ctx context.Context, id int64, login string) (*Transfer, error) {
+	t, items, err := s.Repo.UserTransfer(ctx, id, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newTransfer(*t)
+	res.Items = make([]TransferItem, len(items))
+	for i, it := range items {
+		res.Items[i] = TransferItem{
+			Barcode:  it.Barcode,
+			Quantity: it.Quantity,
+		}
+	}
+	return &res, nil
+}
+
+func newTransfer(t mysql.Transfer) Transfer {
+	return Transfer{
+		ID:      t.ID,
+		From:    t.From,
+		To:      t.To,
+		Note:    t.Note,
+		Actor:   t.Actor,
+		Lines:   t.Items,
+		Created: t.Created,
+	}
+}
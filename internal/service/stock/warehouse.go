@@ -0,0 +1,114 @@
+package stock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+)
+
+var (
+	ErrWarehouseExists   = errors.New("warehouse with this code already exists")
+	ErrWarehouseNotEmpty = errors.New("warehouse still has stock or reservations")
+)
+
+// Warehouse is service level model of storage location of user, warehouses with lower priority are used first
+type Warehouse struct {
+	ID       int64
+	Code     string
+	Name     string
+	Address  string
+	Priority int
+	Archived bool
+	Created  time.Time
+}
+
+// NormalizeCode returns warehouse code in the form it is stored in, codes are case insensitive
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreateWarehouse adds warehouse of user, codes of archived warehouses can't be reused
+func (s *SService) CreateWarehouse(ctx context.Context, w Warehouse, login string) (*Warehouse, error) {
+	w.Code = NormalizeCode(w.Code)
+
+	id, err := s.Repo.CreateWarehouse(ctx, mysql.Warehouse{
+		Code:     w.Code,
+		Name:     w.Name,
+		Address:  w.Address,
+		Priority: w.Priority,
+	}, login)
+	if err != nil {
+		if errors.Is(err, mysql.ErrUniqConstrViolation) {
+			return nil, ErrWarehouseExists
+		}
+		return nil, err
+	}
+
+	w.ID = id
+	w.Archived = false
+	w.Created = time.Now()
+	return &w, nil
+}
+
+// UserWarehouses returns active warehouses of user ordered by priority
+func (s *SService) UserWarehouses(ctx context.Context, login string) ([]Warehouse, error) {
+	warehouses, err := s.Repo.UserWarehouses(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Warehouse, len(warehouses))
+	for i, w := range warehouses {
+		res[i] = newWarehouse(w)
+	}
+	return res, nil
+}
+
+// ArchiveWarehouse archives warehouse of user, it isn't used for new movements and orders anymore
+// but stays in stock history; only warehouse without stock and reservations can be archived
+func (s *SService) ArchiveWarehouse(ctx context.Context, code string, login string) error {
+	return s.Repo.ArchiveWarehouse(ctx, NormalizeCode(code), func(products int) error {
+		if products > 0 {
+			return ErrWarehouseNotEmpty
+		}
+		return nil
+	}, login)
+}
+
+// WarehouseStock returns current stock of user products in warehouse
+// productsPerPage - number of products on page
+// page - next page with products
+func (s *SService) WarehouseStock(ctx context.Context, code string, page, productsPerPage int, login string) ([]Level, error) {
+	code = NormalizeCode(code)
+
+	// warehouse existence and ownership is checked separately so empty page is distinguishable from foreign warehouse
+	if _, err := s.Repo.UserWarehouse(ctx, code, login); err != nil {
+		return nil, err
+	}
+
+	levels, err := s.Repo.WarehouseStock(ctx, code, productsPerPage, (page-1)*productsPerPage, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Level, len(levels))
+	for i, l := range levels {
+		res[i] = newLevel(l)
+	}
+	return res, nil
+}
+
+func newWarehouse(w mysql.Warehouse) Warehouse {
+	return Warehouse{
+		ID:       w.ID,
+		Code:     w.Code,
+		Name:     w.Name,
+		Address:  w.Address,
+		Priority: w.Priority,
+		Archived: w.Archived,
+		Created:  w.Created,
+	}
+}
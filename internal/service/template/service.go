@@ -0,0 +1,328 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/config"
+	"github.com/AnisaForWork/user_orders/internal/money"
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+
+	"github.com/google/uuid"
+)
+
+// BuiltinName is name of template configured for product service, it is available to every user
+const BuiltinName = "default"
+
+// Repository used to call db level logic
+type Repository interface {
+	CreateTemplateVersion(ctx context.Context, tpl mysql.Template, login string) (int, error)
+	UserTemplates(ctx context.Context, login string) ([]mysql.Template, error)
+	UserTemplate(ctx context.Context, name string, version int, login string) (*mysql.Template, error)
+	DefaultTemplate(ctx context.Context, login string) (*mysql.Template, error)
+	TemplateVersion(ctx context.Context, id int64, version int) (*mysql.Template, error)
+	SetDefaultTemplate(ctx context.Context, name string, login string) error
+}
+
+// Locales used to get user settings for formatting sample data
+type Locales interface {
+	Locale(ctx context.Context, login string) (*locale.Settings, error)
+}
+
+var (
+	ErrNotPDF       = errors.New("template file is not PDF document")
+	ErrTooLarge     = errors.New("template file is too large")
+	ErrReservedName = errors.New("template name is reserved")
+)
+
+// TService struct implements check template registry functionality
+type TService struct {
+	Repo            Repository
+	PathToTemplates string
+	MaxSizeBytes    int64
+	Builtin         Template
+	Renderers       render.Renderers
+	Locales         Locales
+}
+
+// Template is service level model of one template version ready to be used for rendering
+type Template struct {
+	render.Page
+	ID        int64
+	Name      string
+	Version   int
+	IsDefault bool
+	Created   time.Time
+}
+
+// NewTemplate holds data of uploaded template
+type NewTemplate struct {
+	Name   string
+	Layout render.Layout
+	PDF    io.Reader
+	Font   io.Reader
+}
+
+func NewService(repo Repository, renderers render.Renderers, locales Locales, cfg *config.Template, prCfg *config.Product) *TService {
+	builtin := Template{
+		Name: BuiltinName,
+		Page: render.Page{
+			Layout:     render.DefaultLayout(prCfg.TemplateW, prCfg.TemplateH),
+			PathToPDF:  filepath.Join(prCfg.PathToTemplate, prCfg.TemplateName),
+			FontName:   prCfg.FontName,
+			PathToFont: filepath.Join(prCfg.PathToTemplate, prCfg.FontFileName),
+		},
+	}
+
+	s := &TService{
+		Repo:            repo,
+		PathToTemplates: cfg.PathToTemplates,
+		MaxSizeBytes:    cfg.MaxSizeBytes,
+		Builtin:         builtin,
+		Renderers:       renderers,
+		Locales:         locales,
+	}
+	return s
+}
+
+// CreateTemplate stores template files and registers them as new version of user template
+func (s *TService) CreateTemplate(ctx context.Context, nt NewTemplate, login string) (*Template, error) {
+	if nt.Name == BuiltinName {
+		return nil, ErrReservedName
+	}
+
+	if err := nt.Layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	layout, err := json.Marshal(nt.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.PathToTemplates, 0o755); err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	dbModel := mysql.Template{
+		Name:     nt.Name,
+		FileName: fmt.Sprintf("%s_%s.pdf", nt.Name, id),
+		Layout:   layout,
+	}
+
+	if err := s.saveFile(dbModel.FileName, nt.PDF, true); err != nil {
+		return nil, err
+	}
+
+	if nt.Font != nil {
+		dbModel.FontFile = fmt.Sprintf("%s_%s.ttf", nt.Name, id)
+		if err := s.saveFile(dbModel.FontFile, nt.Font, false); err != nil {
+			s.removeFiles(dbModel)
+			return nil, err
+		}
+	}
+
+	version, err := s.Repo.CreateTemplateVersion(ctx, dbModel, login)
+	if err != nil {
+		s.removeFiles(dbModel)
+		return nil, err
+	}
+	dbModel.Version = version
+
+	return s.fromDB(&dbModel)
+}
+
+// Templates returns latest versions of user templates together with builtin template
+func (s *TService) Templates(ctx context.Context, login string) ([]Template, error) {
+	tpls, err := s.Repo.UserTemplates(ctx, login)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	builtin := s.Builtin
+	builtin.IsDefault = true
+
+	res := make([]Template, 0, len(tpls)+1)
+	for i := range tpls {
+		tpl, err := s.fromDB(&tpls[i])
+		if err != nil {
+			return nil, err
+		}
+		if tpl.IsDefault {
+			builtin.IsDefault = false
+		}
+		res = append(res, *tpl)
+	}
+
+	return append([]Template{builtin}, res...), nil
+}
+
+// ResolveTemplate returns template that should be used to render check,
+// empty name means user default template, version 0 means latest version
+func (s *TService) ResolveTemplate(ctx context.Context, name string, version int, login string) (*Template, error) {
+	if name == BuiltinName {
+		builtin := s.Builtin
+		return &builtin, nil
+	}
+
+	var (
+		tpl *mysql.Template
+		err error
+	)
+	if name == "" {
+		tpl, err = s.Repo.DefaultTemplate(ctx, login)
+		if errors.Is(err, mysql.ErrNoRows) {
+			builtin := s.Builtin
+			return &builtin, nil
+		}
+	} else {
+		tpl, err = s.Repo.UserTemplate(ctx, name, version, login)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fromDB(tpl)
+}
+
+// TemplateVersion returns template version check was created with, id 0 means builtin template
+func (s *TService) TemplateVersion(ctx context.Context, id int64, version int) (*Template, error) {
+	if id == 0 {
+		builtin := s.Builtin
+		return &builtin, nil
+	}
+
+	tpl, err := s.Repo.TemplateVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fromDB(tpl)
+}
+
+// SetDefaultTemplate makes template default for user checks
+func (s *TService) SetDefaultTemplate(ctx context.Context, name string, login string) error {
+	if name == BuiltinName {
+		name = ""
+	}
+	return s.Repo.SetDefaultTemplate(ctx, name, login)
+}
+
+// TemplatePreview renders template with sample product data formatted for user locale in given format,
+// returns rendered preview and its content type
+func (s *TService) TemplatePreview(ctx context.Context, name string, version int, format render.Format, login string) (io.Reader, string, error) {
+	rn, err := s.Renderers.Get(format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tpl, err := s.ResolveTemplate(ctx, name, version, login)
+	if err != nil {
+		return nil, "", err
+	}
+
+	st, err := s.Locales.Locale(ctx, login)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	err = rn.Render(&buf, &tpl.Page, render.Data{
+		Barcode:  "1234567890",
+		Name:     "Sample product name",
+		Cost:     st.Money(money.Major(100, st.Currency.String())),
+		Date:     st.Date(time.Now()),
+		Language: st.Language,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &buf, rn.ContentType(), nil
+}
+
+func (s *TService) fromDB(dbModel *mysql.Template) (*Template, error) {
+	var layout render.Layout
+	if err := json.Unmarshal(dbModel.Layout, &layout); err != nil {
+		return nil, err
+	}
+
+	tpl := &Template{
+		ID:        dbModel.ID,
+		Name:      dbModel.Name,
+		Version:   dbModel.Version,
+		IsDefault: dbModel.IsDefault,
+		Created:   dbModel.Created,
+		Page: render.Page{
+			Layout:     layout,
+			PathToPDF:  filepath.Join(s.PathToTemplates, dbModel.FileName),
+			FontName:   s.Builtin.FontName,
+			PathToFont: s.Builtin.PathToFont,
+		},
+	}
+
+	if dbModel.FontFile != "" {
+		tpl.FontName = fmt.Sprintf("%s_v%d", dbModel.Name, dbModel.Version)
+		tpl.PathToFont = filepath.Join(s.PathToTemplates, dbModel.FontFile)
+	}
+
+	return tpl, nil
+}
+
+// saveFile copies uploaded file into templates directory, limiting its size
+func (s *TService) saveFile(name string, src io.Reader, isPDF bool) (err error) {
+	path := filepath.Join(s.PathToTemplates, name)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	if isPDF {
+		magic := make([]byte, 5)
+		if _, err = io.ReadFull(src, magic); err != nil || string(magic) != "%PDF-" {
+			return ErrNotPDF
+		}
+		if _, err = f.Write(magic); err != nil {
+			return err
+		}
+	}
+
+	n, err := io.Copy(f, io.LimitReader(src, s.MaxSizeBytes+1))
+	if err != nil {
+		return err
+	}
+
+	if n > s.MaxSizeBytes {
+		return ErrTooLarge
+	}
+
+	return nil
+}
+
+func (s *TService) removeFiles(dbModel mysql.Template) {
+	os.Remove(filepath.Join(s.PathToTemplates, dbModel.FileName))
+	if dbModel.FontFile != "" {
+		os.Remove(filepath.Join(s.PathToTemplates, dbModel.FontFile))
+	}
+}
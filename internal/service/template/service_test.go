@@ -0,0 +1,268 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+	"github.com/AnisaForWork/user_orders/internal/service/render"
+)
+
+// templatesRepo keeps versions of templates of one user
+type templatesRepo struct {
+	Repository
+	versions map[string][]mysql.Template
+	def      string
+	fail     bool
+}
+
+func (r *templatesRepo) CreateTemplateVersion(ctx context.Context, tpl mysql.Template, login string) (int, error) {
+	if r.fail {
+		return 0, errors.New("connection refused")
+	}
+	tpl.ID = int64(len(r.versions) + 1)
+	if v := r.versions[tpl.Name]; len(v) > 0 {
+		tpl.ID = v[0].ID
+	}
+	tpl.Version = len(r.versions[tpl.Name]) + 1
+	r.versions[tpl.Name] = append(r.versions[tpl.Name], tpl)
+	return tpl.Version, nil
+}
+
+func (r *templatesRepo) UserTemplate(ctx context.Context, name string, version int, login string) (*mysql.Template, error) {
+	v := r.versions[name]
+	if version == 0 {
+		version = len(v)
+	}
+	if version < 1 || version > len(v) {
+		return nil, mysql.ErrNoRows
+	}
+	tpl := v[version-1]
+	tpl.IsDefault = name == r.def
+	return &tpl, nil
+}
+
+func (r *templatesRepo) DefaultTemplate(ctx context.Context, login string) (*mysql.Template, error) {
+	if r.def == "" {
+		return nil, mysql.ErrNoRows
+	}
+	return r.UserTemplate(ctx, r.def, 0, login)
+}
+
+func (r *templatesRepo) SetDefaultTemplate(ctx context.Context, name string, login string) error {
+	if _, ok := r.versions[name]; name != "" && !ok {
+		return mysql.ErrNoRows
+	}
+	r.def = name
+	return nil
+}
+
+// testLocales gives every user german settings
+type testLocales struct{}
+
+func (testLocales) Locale(ctx context.Context, login string) (*locale.Settings, error) {
+	return locale.Parse("de-DE", "EUR", "Europe/Berlin")
+}
+
+// testRenderer remembers page and data of last rendered check
+type testRenderer struct {
+	page *render.Page
+	data render.Data
+}
+
+func (r *testRenderer) Render(w io.Writer, p *render.Page, d render.Data) error {
+	r.page, r.data = p, d
+	_, err := io.WriteString(w, "preview")
+	return err
+}
+
+func (r *testRenderer) ContentType() string {
+	return "text/plain"
+}
+
+func (r *testRenderer) Extension() string {
+	return "txt"
+}
+
+func testService(t *testing.T) (*TService, *templatesRepo) {
+	repo := &templatesRepo{versions: map[string][]mysql.Template{}}
+	s := &TService{
+		Repo:            repo,
+		PathToTemplates: t.TempDir(),
+		MaxSizeBytes:    64,
+		Builtin: Template{
+			Name: BuiltinName,
+			Page: render.Page{
+				Layout:     render.DefaultLayout(200, 148),
+				PathToPDF:  "template/template.pdf",
+				FontName:   "DejaVu",
+				PathToFont: "template/DejaVuSans.ttf",
+			},
+		},
+		Locales: testLocales{},
+	}
+	return s, repo
+}
+
+func templateFiles(t *testing.T, s *TService) []string {
+	entries, err := os.ReadDir(s.PathToTemplates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestCreateTemplate(t *testing.T) {
+	s, repo := testService(t)
+	ctx := context.Background()
+	layout := render.DefaultLayout(210, 148)
+
+	cases := []struct {
+		name string
+		nt   NewTemplate
+		err  error
+	}{
+		{name: "reserved name", nt: NewTemplate{Name: BuiltinName, Layout: layout, PDF: strings.NewReader("%PDF-1.4")}, err: ErrReservedName},
+		{name: "invalid layout", nt: NewTemplate{Name: "shop", Layout: render.DefaultLayout(0, 148), PDF: strings.NewReader("%PDF-1.4")}, err: render.ErrInvalidLayout},
+		{name: "not pdf", nt: NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("GIF89a")}, err: ErrNotPDF},
+		{name: "large pdf", nt: NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("%PDF-" + strings.Repeat("x", 65))}, err: ErrTooLarge},
+		{name: "large font", nt: NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("%PDF-1.4"), Font: strings.NewReader(strings.Repeat("x", 65))}, err: ErrTooLarge},
+	}
+	for _, c := range cases {
+		if _, err := s.CreateTemplate(ctx, c.nt, "seller"); !errors.Is(err, c.err) {
+			t.Errorf("%s: CreateTemplate returned %v, want %v", c.name, err, c.err)
+		}
+	}
+
+	repo.fail = true
+	if _, err := s.CreateTemplate(ctx, NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("%PDF-1.4")}, "seller"); err == nil {
+		t.Error("CreateTemplate succeeded when template wasn't registered")
+	}
+	repo.fail = false
+
+	if files := templateFiles(t, s); len(files) != 0 {
+		t.Fatalf("templates directory has files %v of rejected templates", files)
+	}
+
+	first, err := s.CreateTemplate(ctx, NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("%PDF-1.4")}, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.CreateTemplate(ctx, NewTemplate{Name: "shop", Layout: layout, PDF: strings.NewReader("%PDF-1.5"), Font: strings.NewReader("font")}, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Version != 1 || second.Version != 2 {
+		t.Errorf("template versions are %d and %d, want 1 and 2", first.Version, second.Version)
+	}
+	if first.FontName != "DejaVu" || first.PathToFont != s.Builtin.PathToFont {
+		t.Errorf("template without font uses font %s from %s, want builtin font", first.FontName, first.PathToFont)
+	}
+	if second.FontName != "shop_v2" || filepath.Dir(second.PathToFont) != s.PathToTemplates {
+		t.Errorf("template with font uses font %s from %s", second.FontName, second.PathToFont)
+	}
+	if first.PathToPDF == second.PathToPDF || first.Layout.Width != 210 {
+		t.Errorf("template versions share file %s or lost layout %+v", first.PathToPDF, first.Layout)
+	}
+
+	b, err := os.ReadFile(second.PathToPDF)
+	if err != nil || string(b) != "%PDF-1.5" {
+		t.Errorf("stored template is %q, %v", b, err)
+	}
+	if files := templateFiles(t, s); len(files) != 3 {
+		t.Errorf("templates directory has files %v, want two templates and one font", files)
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	s, _ := testService(t)
+	ctx := context.Background()
+
+	for v, content := range []string{"%PDF-1.4", "%PDF-1.5"} {
+		if _, err := s.CreateTemplate(ctx, NewTemplate{Name: "shop", Layout: render.DefaultLayout(210+float64(v), 148), PDF: strings.NewReader(content)}, "seller"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tpl, err := s.ResolveTemplate(ctx, "", 0, "seller")
+	if err != nil || tpl.Name != BuiltinName {
+		t.Fatalf("user without default template got %+v, %v, want builtin template", tpl, err)
+	}
+
+	if err := s.SetDefaultTemplate(ctx, "missing", "seller"); !errors.Is(err, mysql.ErrNoRows) {
+		t.Fatalf("SetDefaultTemplate of missing template returned %v", err)
+	}
+	if err := s.SetDefaultTemplate(ctx, "shop", "seller"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		version int
+		want    string
+		width   float64
+	}{
+		{name: "", want: "shop", width: 211},
+		{name: "shop", want: "shop", width: 211},
+		{name: "shop", version: 1, want: "shop", width: 210},
+		{name: BuiltinName, want: BuiltinName, width: 200},
+	}
+	for _, c := range cases {
+		tpl, err := s.ResolveTemplate(ctx, c.name, c.version, "seller")
+		if err != nil {
+			t.Fatalf("ResolveTemplate(%q, %d): %v", c.name, c.version, err)
+		}
+		if tpl.Name != c.want || tpl.Layout.Width != c.width {
+			t.Errorf("ResolveTemplate(%q, %d) = %s with width %v, want %s with width %v", c.name, c.version, tpl.Name, tpl.Layout.Width, c.want, c.width)
+		}
+	}
+
+	if _, err := s.ResolveTemplate(ctx, "shop", 3, "seller"); !errors.Is(err, mysql.ErrNoRows) {
+		t.Errorf("missing version returned %v, want ErrNoRows", err)
+	}
+
+	// choosing builtin template resets user choice
+	if err := s.SetDefaultTemplate(ctx, BuiltinName, "seller"); err != nil {
+		t.Fatal(err)
+	}
+	if tpl, err := s.ResolveTemplate(ctx, "", 0, "seller"); err != nil || tpl.Name != BuiltinName {
+		t.Errorf("after reset got %+v, %v, want builtin template", tpl, err)
+	}
+}
+
+func TestTemplatePreview(t *testing.T) {
+	s, _ := testService(t)
+	rn := &testRenderer{}
+	s.Renderers = render.Renderers{render.FormatHTML: rn}
+	ctx := context.Background()
+
+	if _, _, err := s.TemplatePreview(ctx, BuiltinName, 0, render.FormatPDF, "seller"); !errors.Is(err, render.ErrUnknownFormat) {
+		t.Fatalf("preview in unsupported format returned %v, want ErrUnknownFormat", err)
+	}
+
+	r, contentType, err := s.TemplatePreview(ctx, BuiltinName, 0, render.FormatHTML, "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	if string(b) != "preview" || contentType != "text/plain" {
+		t.Errorf("preview is %q of type %s", b, contentType)
+	}
+	if rn.page.PathToPDF != s.Builtin.PathToPDF {
+		t.Errorf("preview rendered with %s, want builtin template", rn.page.PathToPDF)
+	}
+	if rn.data.Cost != "100,00\u00a0€" || rn.data.Language.String() != "de-DE" {
+		t.Errorf("sample data formatted as %q for %s, want german format", rn.data.Cost, rn.data.Language)
+	}
+}
@@ -0,0 +1,139 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+	"github.com/AnisaForWork/user_orders/internal/service/locale"
+)
+
+// Repository used to call db level logic
+type Repository interface {
+	UserSettings(ctx context.Context, login string) (*mysql.UserSettings, error)
+	UpdateUserSettings(ctx context.Context, s mysql.UserSettings, login string) error
+	UserSeller(ctx context.Context, login string) (*mysql.Seller, error)
+	UpdateUserSeller(ctx context.Context, s mysql.Seller, login string) error
+}
+
+// UService struct implements user settings functionality
+type UService struct {
+	Repo Repository
+}
+
+// Settings is service level model of user locale settings
+type Settings struct {
+	Locale   string
+	Currency string
+	TimeZone string
+}
+
+// Seller is service level model of seller profile printed on user checks,
+// VATRate is in basis points, nil means default rate from configuration
+type Seller struct {
+	Name    string
+	TaxID   string
+	Address string
+	VATRate *int
+}
+
+func NewService(repo Repository) *UService {
+	s := &UService{
+		Repo: repo,
+	}
+	return s
+}
+
+// Settings returns user locale settings
+func (s *UService) Settings(ctx context.Context, login string) (*Settings, error) {
+	st, err := s.Repo.UserSettings(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Settings{
+		Locale:   st.Locale,
+		Currency: st.Currency,
+		TimeZone: st.TimeZone,
+	}
+	return res, nil
+}
+
+// UpdateSettings validates and saves user locale settings, returns them in canonical form
+func (s *UService) UpdateSettings(ctx context.Context, st Settings, login string) (*Settings, error) {
+	parsed, err := locale.Parse(st.Locale, st.Currency, st.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	dbModel := mysql.UserSettings{
+		Locale:   parsed.Language.String(),
+		Currency: parsed.Currency.String(),
+		TimeZone: parsed.Location.String(),
+	}
+
+	if err := s.Repo.UpdateUserSettings(ctx, dbModel, login); err != nil {
+		return nil, err
+	}
+
+	res := &Settings{
+		Locale:   dbModel.Locale,
+		Currency: dbModel.Currency,
+		TimeZone: dbModel.TimeZone,
+	}
+	return res, nil
+}
+
+// Locale returns user settings used to format checks
+func (s *UService) Locale(ctx context.Context, login string) (*locale.Settings, error) {
+	st, err := s.Repo.UserSettings(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return locale.Parse(st.Locale, st.Currency, st.TimeZone)
+}
+
+// Seller returns seller profile of user
+func (s *UService) Seller(ctx context.Context, login string) (*Seller, error) {
+	sl, err := s.Repo.UserSeller(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Seller{
+		Name:    sl.Name,
+		TaxID:   sl.TaxID,
+		Address: sl.Address,
+	}
+	if sl.VATRate.Valid {
+		rate := int(sl.VATRate.Int64)
+		res.VATRate = &rate
+	}
+	return res, nil
+}
+
+// UpdateSeller saves seller profile of user, it's printed on checks generated after update
+func (s *UService) UpdateSeller(ctx context.Context, sl Seller, login string) (*Seller, error) {
+	dbModel := mysql.Seller{
+		Name:    strings.TrimSpace(sl.Name),
+		TaxID:   strings.TrimSpace(sl.TaxID),
+		Address: strings.TrimSpace(sl.Address),
+	}
+	if sl.VATRate != nil {
+		dbModel.VATRate = sql.NullInt64{Int64: int64(*sl.VATRate), Valid: true}
+	}
+
+	if err := s.Repo.UpdateUserSeller(ctx, dbModel, login); err != nil {
+		return nil, err
+	}
+
+	res := &Seller{
+		Name:    dbModel.Name,
+		TaxID:   dbModel.TaxID,
+		Address: dbModel.Address,
+		VATRate: sl.VATRate,
+	}
+	return res, nil
+}
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Queue is persistent queue of tasks of one kind, claimed task stays owned by worker instance
+// while its lease is extended, so tasks of other live replicas aren't taken over
+type Queue interface {
+	// Claim takes next due task for owner, returns mysql.ErrNoRows if there is none
+	Claim(ctx context.Context, owner string, lease time.Duration) (Task, error)
+	// RequeueExpired returns in queue running tasks which lease expired
+	RequeueExpired(ctx context.Context) (int64, error)
+	// Permanent reports whether task failed with err should fail without retries
+	Permanent(err error) bool
+}
+
+// Task is claimed queue entry, its outcome is recorded only while owner holds its lease
+type Task interface {
+	// Run executes task
+	Run(ctx context.Context) error
+	// Attempts returns number of attempts including current one
+	Attempts() int
+	// Fields returns fields describing task in logs
+	Fields() log.Fields
+	Extend(ctx context.Context, owner string, lease time.Duration) error
+	Finish(ctx context.Context, owner string) error
+	Retry(ctx context.Context, owner string, errMsg string, delay time.Duration) error
+	Fail(ctx context.Context, owner string, errMsg string) error
+}
+
+// DefaultLease is time running task stays owned by worker instance without heartbeat when it isn't configured
+const DefaultLease = 2 * time.Minute
+
+// Settings of worker pool
+type Settings struct {
+	Workers      int
+	MaxAttempts  int
+	Backoff      time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Lease        time.Duration
+}
+
+// Pool executes tasks of queue on workers, failed tasks are retried with exponential backoff
+// until they run out of attempts, Instance identifies this process in task leases
+type Pool struct {
+	Queue    Queue
+	Name     string // kind of tasks in logs
+	Instance string
+	Settings
+	wakeUp chan struct{}
+}
+
+// NewPool returns pool executing tasks of queue, name is used in logs
+func NewPool(name string, queue Queue, st Settings) *Pool {
+	p := &Pool{
+		Queue:    queue,
+		Name:     name,
+		Instance: uuid.NewString(),
+		Settings: st,
+		wakeUp:   make(chan struct{}, 1),
+	}
+	if p.Lease <= 0 {
+		p.Lease = DefaultLease
+	}
+	return p
+}
+
+// WakeUp makes running pool claim tasks without waiting for next poll
+func (p *Pool) WakeUp() {
+	select {
+	case p.wakeUp <- struct{}{}:
+	default:
+	}
+}
+
+// Run executes queued tasks until context is canceled, running tasks which lease expired
+// because their worker was stopped are returned in queue every half of lease
+func (p *Pool) Run(ctx context.Context) {
+	sem := make(chan struct{}, p.Workers)
+	ticker := time.NewTicker(p.PollInterval)
+	defer ticker.Stop()
+
+	var requeued time.Time
+	for {
+		if time.Since(requeued) >= p.Lease/2 {
+			p.requeueExpired(ctx)
+			requeued = time.Now()
+		}
+
+		// claim tasks while there are free workers and due tasks
+		for claimed := true; claimed; {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			t, err := p.Queue.Claim(ctx, p.Instance, p.Lease)
+			if err != nil {
+				<-sem
+				if !errors.Is(err, mysql.ErrNoRows) && ctx.Err() == nil {
+					log.WithFields(log.Fields{"place": p.Name}).WithError(err).Error("Could not claim task")
+				}
+				claimed = false
+				continue
+			}
+
+			go func() {
+				defer func() { <-sem }()
+				p.execute(ctx, t)
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wakeUp:
+		}
+	}
+}
+
+func (p *Pool) execute(ctx context.Context, t Task) {
+	lg := log.WithFields(t.Fields()).WithFields(log.Fields{
+		"place":   p.Name,
+		"attempt": t.Attempts(),
+	})
+
+	stop := p.heartbeat(ctx, t, lg)
+	err := t.Run(ctx)
+	stop()
+	if err == nil {
+		if err := t.Finish(context.Background(), p.Instance); err != nil {
+			lg.WithError(err).Error("Could not mark task as done")
+		}
+		return
+	}
+
+	if ctx.Err() != nil {
+		// shutdown, task is requeued when its lease expires
+		return
+	}
+
+	if p.Queue.Permanent(err) || t.Attempts() >= p.MaxAttempts {
+		lg.WithError(err).Warn("Task failed")
+		if err := t.Fail(ctx, p.Instance, err.Error()); err != nil {
+			lg.WithError(err).Error("Could not mark task as failed")
+		}
+		return
+	}
+
+	delay := p.backoff(t.Attempts())
+	lg.WithError(err).WithField("retryIn", delay).Warn("Task failed, retrying")
+	if err := t.Retry(ctx, p.Instance, err.Error(), delay); err != nil {
+		lg.WithError(err).Error("Could not requeue task")
+	}
+}
+
+// heartbeat extends lease of running task every third of lease until returned function is called
+func (p *Pool) heartbeat(ctx context.Context, t Task, lg *log.Entry) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(p.Lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := t.Extend(ctx, p.Instance, p.Lease); err != nil && ctx.Err() == nil {
+				lg.WithError(err).Warn("Could not extend task lease")
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// requeueExpired returns in queue running tasks which workers stopped extending their lease
+func (p *Pool) requeueExpired(ctx context.Context) {
+	n, err := p.Queue.RequeueExpired(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.WithFields(log.Fields{"place": p.Name}).WithError(err).Error("Could not requeue tasks with expired lease")
+		}
+		return
+	}
+	if n > 0 {
+		log.WithFields(log.Fields{"place": p.Name, "tasks": n}).Info("Tasks with expired lease returned in queue")
+	}
+}
+
+// backoff returns exponential delay before next attempt
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnisaForWork/user_orders/internal/repository/mysql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	errTransient = errors.New("try again")
+	errPermanent = errors.New("never works")
+)
+
+// testQueue keeps tasks in memory, task is due when its delay passed
+type testQueue struct {
+	mu    sync.Mutex
+	tasks []*testTask
+}
+
+func (q *testQueue) Claim(ctx context.Context, owner string, lease time.Duration) (Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range q.tasks {
+		if t.status == "queued" && !time.Now().Before(t.due) {
+			t.status, t.owner = "running", owner
+			t.attempts++
+			return t, nil
+		}
+	}
+	return nil, mysql.ErrNoRows
+}
+
+func (q *testQueue) RequeueExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (q *testQueue) Permanent(err error) bool {
+	return errors.Is(err, errPermanent)
+}
+
+func (q *testQueue) statuses() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	res := make([]string, len(q.tasks))
+	for i, t := range q.tasks {
+		res[i] = t.status
+	}
+	return res
+}
+
+// testTask fails with errors in given order before it succeeds
+type testTask struct {
+	q        *testQueue
+	errs     []error
+	status   string
+	owner    string
+	attempts int
+	due      time.Time
+	delays   []time.Duration
+}
+
+func (t *testTask) Run(ctx context.Context) error {
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+
+	if len(t.errs) == 0 {
+		return nil
+	}
+	err := t.errs[0]
+	t.errs = t.errs[1:]
+	return err
+}
+
+func (t *testTask) Attempts() int {
+	return t.attempts
+}
+
+func (t *testTask) Fields() log.Fields {
+	return log.Fields{}
+}
+
+func (t *testTask) Extend(ctx context.Context, owner string, lease time.Duration) error {
+	return nil
+}
+
+func (t *testTask) finish(owner string, status string) error {
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+
+	if t.owner != owner || t.status != "running" {
+		return mysql.ErrNoRows
+	}
+	t.status = status
+	return nil
+}
+
+func (t *testTask) Finish(ctx context.Context, owner string) error {
+	return t.finish(owner, "done")
+}
+
+func (t *testTask) Retry(ctx context.Context, owner string, errMsg string, delay time.Duration) error {
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+
+	if t.owner != owner || t.status != "running" {
+		return mysql.ErrNoRows
+	}
+	t.status, t.due = "queued", time.Now().Add(delay)
+	t.delays = append(t.delays, delay)
+	return nil
+}
+
+func (t *testTask) Fail(ctx context.Context, owner string, errMsg string) error {
+	return t.finish(owner, "failed")
+}
+
+func TestPoolRun(t *testing.T) {
+	q := &testQueue{}
+	add := func(errs ...error) *testTask {
+		t := &testTask{q: q, errs: errs, status: "queued"}
+		q.tasks = append(q.tasks, t)
+		return t
+	}
+	done := add()
+	retried := add(errTransient, errTransient)
+	permanent := add(errTransient, errPermanent)
+	exhausted := add(errTransient, errTransient, errTransient)
+
+	p := NewPool("test", q, Settings{
+		Workers:      2,
+		MaxAttempts:  3,
+		Backoff:      time.Millisecond,
+		MaxBackoff:   2 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go p.Run(ctx)
+
+	want := []string{"done", "done", "failed", "failed"}
+	for {
+		got := q.statuses()
+		if reflect.DeepEqual(got, want) {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("tasks ended as %v, want %v", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	attempts := map[string]int{"done": 1, "retried": 3, "permanent": 2, "exhausted": 3}
+	for name, task := range map[string]*testTask{"done": done, "retried": retried, "permanent": permanent, "exhausted": exhausted} {
+		if task.attempts != attempts[name] {
+			t.Errorf("%s task made %d attempts, want %d", name, task.attempts, attempts[name])
+		}
+	}
+	if !reflect.DeepEqual(retried.delays, []time.Duration{time.Millisecond, 2 * time.Millisecond}) {
+		t.Errorf("retried task waited %v, want exponential backoff", retried.delays)
+	}
+	for _, task := range q.tasks {
+		if task.owner != p.Instance {
+			t.Errorf("task is owned by %q, want pool instance %q", task.owner, p.Instance)
+		}
+	}
+}
+
+func TestPoolBackoff(t *testing.T) {
+	p := NewPool("test", &testQueue{}, Settings{Backoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	cases := map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	}
+	for attempt, want := range cases {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+	if p.Lease != DefaultLease {
+		t.Errorf("pool without lease has lease %v, want %v", p.Lease, DefaultLease)
+	}
+}
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS templates(
+    id int NOT NULL AUTO_INCREMENT,
+    name varchar(40) NOT NULL,
+    userId int NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT t_user_name_UNQ UNIQUE (userId, name),
+    CONSTRAINT templates_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS template_versions(
+    templateId int NOT NULL,
+    version int NOT NULL,
+    filename varchar(100) NOT NULL,
+    fontFile varchar(100) NOT NULL DEFAULT '',
+    layout JSON NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (templateId, version),
+    CONSTRAINT versions_templates_fk
+    FOREIGN KEY (templateId)  REFERENCES templates (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN templateId int NULL,
+    ADD CONSTRAINT users_templates_fk
+    FOREIGN KEY (templateId)  REFERENCES templates (id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    ADD COLUMN templateId int NULL,
+    ADD COLUMN templateVersion int NOT NULL DEFAULT 0,
+    ADD CONSTRAINT prchecks_templates_fk
+    FOREIGN KEY (templateId)  REFERENCES templates (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP FOREIGN KEY prchecks_templates_fk,
+    DROP COLUMN templateId,
+    DROP COLUMN templateVersion;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP FOREIGN KEY users_templates_fk,
+    DROP COLUMN templateId;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS template_versions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS templates;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS jobs(
+    id varchar(36) NOT NULL,
+    userId int NOT NULL,
+    barcode varchar(10) NOT NULL,
+    template varchar(40) NOT NULL DEFAULT '',
+    status varchar(10) NOT NULL DEFAULT 'queued',
+    attempts int NOT NULL DEFAULT 0,
+    filename varchar(45) NULL,
+    error varchar(255) NOT NULL DEFAULT '',
+    nextRunAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX jobs_status_next_idx (status, nextRunAt),
+    CONSTRAINT jobs_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS jobs;
+-- +goose StatementEnd
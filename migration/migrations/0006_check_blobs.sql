@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS check_blobs(
+    name varchar(100) NOT NULL,
+    data MEDIUMBLOB NOT NULL,
+    size int NOT NULL,
+    modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS check_blobs;
+-- +goose StatementEnd
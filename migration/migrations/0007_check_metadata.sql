@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP PRIMARY KEY,
+    ADD COLUMN id int NOT NULL AUTO_INCREMENT FIRST,
+    ADD CONSTRAINT u_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT prchecks_filename_UNQ UNIQUE (filename),
+    ADD COLUMN created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN size int NOT NULL DEFAULT 0,
+    ADD COLUMN sha256 char(64) NOT NULL DEFAULT '',
+    ADD COLUMN productName varchar(60) NOT NULL DEFAULT '',
+    ADD COLUMN productCost int NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks
+    JOIN products ON products.barcode=prchecks.barcode
+    SET prchecks.productName=products.name, prchecks.productCost=products.cost;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP COLUMN productCost,
+    DROP COLUMN productName,
+    DROP COLUMN sha256,
+    DROP COLUMN size,
+    DROP COLUMN created,
+    DROP INDEX prchecks_filename_UNQ,
+    MODIFY id int NOT NULL,
+    DROP PRIMARY KEY,
+    DROP COLUMN id,
+    ADD CONSTRAINT u_pkey PRIMARY KEY (filename);
+-- +goose StatementEnd
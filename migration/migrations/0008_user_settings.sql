@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN locale varchar(35) NOT NULL DEFAULT 'en-US',
+    ADD COLUMN currency char(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN timeZone varchar(64) NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN timeZone,
+    DROP COLUMN currency,
+    DROP COLUMN locale;
+-- +goose StatementEnd
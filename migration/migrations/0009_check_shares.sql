@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS check_shares(
+    id int NOT NULL AUTO_INCREMENT,
+    checkId int NOT NULL,
+    expires TIMESTAMP NOT NULL,
+    maxDownloads int NULL,
+    downloads int NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT check_shares_prchecks_fk
+    FOREIGN KEY (checkId)  REFERENCES prchecks (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS check_shares;
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN sellerName varchar(120) NOT NULL DEFAULT '',
+    ADD COLUMN sellerTaxId varchar(32) NOT NULL DEFAULT '',
+    ADD COLUMN sellerAddress varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN vatRate int NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS receipt_sequences(
+    userId int NOT NULL,
+    lastNumber bigint NOT NULL DEFAULT 0,
+    CONSTRAINT u_pkey PRIMARY KEY (userId),
+    CONSTRAINT receipt_sequences_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    ADD COLUMN receiptNumber bigint NULL,
+    ADD COLUMN sellerName varchar(120) NOT NULL DEFAULT '',
+    ADD COLUMN sellerTaxId varchar(32) NOT NULL DEFAULT '',
+    ADD COLUMN sellerAddress varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN vatRate int NOT NULL DEFAULT 0,
+    ADD COLUMN netAmount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN taxAmount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN grossAmount bigint NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks SET netAmount=productCost*100, grossAmount=productCost*100;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP COLUMN grossAmount,
+    DROP COLUMN taxAmount,
+    DROP COLUMN netAmount,
+    DROP COLUMN vatRate,
+    DROP COLUMN sellerAddress,
+    DROP COLUMN sellerTaxId,
+    DROP COLUMN sellerName,
+    DROP COLUMN receiptNumber;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS receipt_sequences;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN vatRate,
+    DROP COLUMN sellerAddress,
+    DROP COLUMN sellerTaxId,
+    DROP COLUMN sellerName;
+-- +goose StatementEnd
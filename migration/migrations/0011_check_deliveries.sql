@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS check_deliveries(
+    id int NOT NULL AUTO_INCREMENT,
+    checkId int NOT NULL,
+    recipient varchar(254) NOT NULL,
+    message varchar(1000) NOT NULL DEFAULT '',
+    status varchar(10) NOT NULL DEFAULT 'queued',
+    attempts int NOT NULL DEFAULT 0,
+    error varchar(255) NOT NULL DEFAULT '',
+    nextRunAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX check_deliveries_status_next_idx (status, nextRunAt),
+    CONSTRAINT check_deliveries_prchecks_fk
+    FOREIGN KEY (checkId)  REFERENCES prchecks (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS check_deliveries;
+-- +goose StatementEnd
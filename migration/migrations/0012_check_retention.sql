@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    ADD INDEX prchecks_created_idx (created);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP INDEX prchecks_created_idx;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS orders(
+    id int NOT NULL AUTO_INCREMENT,
+    userId int NOT NULL,
+    total bigint NOT NULL DEFAULT 0,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX orders_user_created_idx (userId, created),
+    CONSTRAINT orders_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS order_items(
+    id int NOT NULL AUTO_INCREMENT,
+    orderId int NOT NULL,
+    barcode varchar(10) NOT NULL,
+    productName varchar(60) NOT NULL,
+    price int NOT NULL,
+    quantity int NOT NULL,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT order_items_order_barcode_UNQ UNIQUE (orderId, barcode),
+    CONSTRAINT order_items_orders_fk
+    FOREIGN KEY (orderId)  REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT order_items_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS order_items;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS orders;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN status varchar(20) NOT NULL DEFAULT 'draft',
+    ADD COLUMN updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS order_events(
+    id int NOT NULL AUTO_INCREMENT,
+    orderId int NOT NULL,
+    fromStatus varchar(20) NOT NULL DEFAULT '',
+    toStatus varchar(20) NOT NULL,
+    actor varchar(40) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT order_events_orders_fk
+    FOREIGN KEY (orderId)  REFERENCES orders (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO order_events (orderId, toStatus, actor, created)
+    SELECT orders.id, orders.status, users.login, orders.created FROM orders
+    JOIN users ON users.id=orders.userId;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS order_events;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN updated,
+    DROP COLUMN status;
+-- +goose StatementEnd
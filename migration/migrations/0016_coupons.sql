@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS coupons(
+    id int NOT NULL AUTO_INCREMENT,
+    userId int NOT NULL,
+    code varchar(40) NOT NULL,
+    kind varchar(10) NOT NULL,
+    value int NOT NULL,
+    minOrderValue bigint NOT NULL DEFAULT 0,
+    validFrom TIMESTAMP NULL,
+    validTo TIMESTAMP NULL,
+    maxUses int NULL,
+    maxUsesPerUser int NULL,
+    uses int NOT NULL DEFAULT 0,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT coupons_user_code_UNQ UNIQUE (userId, code),
+    CONSTRAINT coupons_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS order_discounts(
+    id int NOT NULL AUTO_INCREMENT,
+    orderId int NOT NULL,
+    couponId int NOT NULL,
+    userId int NOT NULL,
+    code varchar(40) NOT NULL,
+    amount bigint NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX order_discounts_coupon_user_idx (couponId, userId),
+    CONSTRAINT order_discounts_orders_fk
+    FOREIGN KEY (orderId)  REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT order_discounts_coupons_fk
+    FOREIGN KEY (couponId)  REFERENCES coupons (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS order_discounts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS coupons;
+-- +goose StatementEnd
@@ -0,0 +1,208 @@
+-- +goose Up
+-- amounts were whole currency units(fiscal amounts of checks were hundredths), they become minor units
+-- of ISO 4217 currency, existing amounts get currency of their user; currencies without 2 fraction digits
+-- are listed with number of minor units in major one
+-- +goose StatementBegin
+CREATE TEMPORARY TABLE currency_factors(
+    code char(3) NOT NULL,
+    factor bigint NOT NULL,
+    CONSTRAINT u_pkey PRIMARY KEY (code)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO currency_factors (code, factor) VALUES
+    ('AFN',1),('ALL',1),('AMD',1),('BIF',1),('CLP',1),('COP',1),('DJF',1),('GNF',1),('GYD',1),('IDR',1),
+    ('IQD',1),('IRR',1),('ISK',1),('JPY',1),('KMF',1),('KPW',1),('KRW',1),('LAK',1),('LBP',1),('MGA',1),
+    ('MMK',1),('MNT',1),('MRO',1),('MUR',1),('PKR',1),('PYG',1),('RSD',1),('RWF',1),('SLL',1),('SOS',1),
+    ('SYP',1),('TZS',1),('UGX',1),('UZS',1),('VND',1),('VUV',1),('XAF',1),('XOF',1),('XPF',1),('YER',1),
+    ('UYI',1),('BHD',1000),('JOD',1000),('KWD',1000),('LYD',1000),('OMR',1000),('TND',1000),('CLF',10000);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE products
+    MODIFY COLUMN cost bigint NOT NULL,
+    ADD COLUMN currency char(3) NOT NULL DEFAULT 'USD' AFTER cost;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE products
+    JOIN users ON users.id=products.userId
+    LEFT JOIN currency_factors ON currency_factors.code=users.currency
+    SET products.currency=users.currency, products.cost=products.cost*COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    MODIFY COLUMN productCost bigint NOT NULL,
+    ADD COLUMN currency char(3) NOT NULL DEFAULT 'USD' AFTER productCost;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks
+    JOIN products ON products.barcode=prchecks.barcode
+    LEFT JOIN currency_factors ON currency_factors.code=products.currency
+    SET prchecks.currency=products.currency,
+        prchecks.productCost=prchecks.productCost*COALESCE(currency_factors.factor, 100),
+        prchecks.netAmount=ROUND(prchecks.netAmount*COALESCE(currency_factors.factor, 100)/100),
+        prchecks.grossAmount=ROUND(prchecks.grossAmount*COALESCE(currency_factors.factor, 100)/100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks SET taxAmount=grossAmount-netAmount;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN currency char(3) NOT NULL DEFAULT 'USD' AFTER total;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_items
+    MODIFY COLUMN price bigint NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE order_items
+    JOIN orders ON orders.id=order_items.orderId
+    JOIN users ON users.id=orders.userId
+    LEFT JOIN currency_factors ON currency_factors.code=users.currency
+    SET order_items.price=order_items.price*COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE order_discounts
+    JOIN orders ON orders.id=order_discounts.orderId
+    JOIN users ON users.id=orders.userId
+    LEFT JOIN currency_factors ON currency_factors.code=users.currency
+    SET order_discounts.amount=order_discounts.amount*COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE orders
+    JOIN users ON users.id=orders.userId
+    LEFT JOIN currency_factors ON currency_factors.code=users.currency
+    SET orders.currency=users.currency,
+        orders.total=orders.total*COALESCE(currency_factors.factor, 100),
+        orders.discount=orders.discount*COALESCE(currency_factors.factor, 100),
+        orders.updated=orders.updated;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE coupons
+    MODIFY COLUMN value bigint NOT NULL,
+    ADD COLUMN currency char(3) NULL AFTER value;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE coupons
+    JOIN users ON users.id=coupons.userId
+    LEFT JOIN currency_factors ON currency_factors.code=users.currency
+    SET coupons.currency=users.currency,
+        coupons.value=IF(coupons.kind='fixed', coupons.value*COALESCE(currency_factors.factor, 100), coupons.value),
+        coupons.minOrderValue=coupons.minOrderValue*COALESCE(currency_factors.factor, 100)
+    WHERE coupons.kind='fixed' OR coupons.minOrderValue>0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TEMPORARY TABLE currency_factors;
+-- +goose StatementEnd
+
+-- +goose Down
+-- fractions of major units are dropped
+-- +goose StatementBegin
+CREATE TEMPORARY TABLE currency_factors(
+    code char(3) NOT NULL,
+    factor bigint NOT NULL,
+    CONSTRAINT u_pkey PRIMARY KEY (code)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO currency_factors (code, factor) VALUES
+    ('AFN',1),('ALL',1),('AMD',1),('BIF',1),('CLP',1),('COP',1),('DJF',1),('GNF',1),('GYD',1),('IDR',1),
+    ('IQD',1),('IRR',1),('ISK',1),('JPY',1),('KMF',1),('KPW',1),('KRW',1),('LAK',1),('LBP',1),('MGA',1),
+    ('MMK',1),('MNT',1),('MRO',1),('MUR',1),('PKR',1),('PYG',1),('RSD',1),('RWF',1),('SLL',1),('SOS',1),
+    ('SYP',1),('TZS',1),('UGX',1),('UZS',1),('VND',1),('VUV',1),('XAF',1),('XOF',1),('XPF',1),('YER',1),
+    ('UYI',1),('BHD',1000),('JOD',1000),('KWD',1000),('LYD',1000),('OMR',1000),('TND',1000),('CLF',10000);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE coupons
+    LEFT JOIN currency_factors ON currency_factors.code=coupons.currency
+    SET coupons.value=IF(coupons.kind='fixed', coupons.value DIV COALESCE(currency_factors.factor, 100), coupons.value),
+        coupons.minOrderValue=coupons.minOrderValue DIV COALESCE(currency_factors.factor, 100)
+    WHERE coupons.currency IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE coupons
+    DROP COLUMN currency,
+    MODIFY COLUMN value int NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE order_items
+    JOIN orders ON orders.id=order_items.orderId
+    LEFT JOIN currency_factors ON currency_factors.code=orders.currency
+    SET order_items.price=order_items.price DIV COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE order_discounts
+    JOIN orders ON orders.id=order_discounts.orderId
+    LEFT JOIN currency_factors ON currency_factors.code=orders.currency
+    SET order_discounts.amount=order_discounts.amount DIV COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE orders
+    LEFT JOIN currency_factors ON currency_factors.code=orders.currency
+    SET orders.total=orders.total DIV COALESCE(currency_factors.factor, 100),
+        orders.discount=orders.discount DIV COALESCE(currency_factors.factor, 100),
+        orders.updated=orders.updated;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_items
+    MODIFY COLUMN price int NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN currency;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks
+    LEFT JOIN currency_factors ON currency_factors.code=prchecks.currency
+    SET prchecks.productCost=prchecks.productCost DIV COALESCE(currency_factors.factor, 100),
+        prchecks.netAmount=prchecks.netAmount*100 DIV COALESCE(currency_factors.factor, 100),
+        prchecks.grossAmount=prchecks.grossAmount*100 DIV COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE prchecks SET taxAmount=grossAmount-netAmount;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP COLUMN currency,
+    MODIFY COLUMN productCost int NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE products
+    LEFT JOIN currency_factors ON currency_factors.code=products.currency
+    SET products.cost=products.cost DIV COALESCE(currency_factors.factor, 100);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE products
+    DROP COLUMN currency,
+    MODIFY COLUMN cost int NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TEMPORARY TABLE currency_factors;
+-- +goose StatementEnd
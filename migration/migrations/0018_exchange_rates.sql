@@ -0,0 +1,35 @@
+-- +goose Up
+-- rate is price of one major unit of base currency in major units of quote currency,
+-- it is used from effectiveFrom until the next rate of the same pair
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS exchange_rates(
+    id int NOT NULL AUTO_INCREMENT,
+    base char(3) NOT NULL,
+    quote char(3) NOT NULL,
+    rate DECIMAL(24,12) NOT NULL,
+    effectiveFrom DATE NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT exchange_rates_pair_date_UNQ UNIQUE (base, quote, effectiveFrom)
+);
+-- +goose StatementEnd
+
+-- checks printed in other currency than product one keep conversion so reprints show the same amounts
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    ADD COLUMN convCurrency char(3) NULL AFTER grossAmount,
+    ADD COLUMN convRate DECIMAL(24,12) NULL AFTER convCurrency,
+    ADD COLUMN convCost bigint NULL AFTER convRate;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP COLUMN convCost,
+    DROP COLUMN convRate,
+    DROP COLUMN convCurrency;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS exchange_rates;
+-- +goose StatementEnd
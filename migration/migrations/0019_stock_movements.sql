@@ -0,0 +1,27 @@
+-- +goose Up
+-- stock of product is sum of quantities of its movements, movements are never updated or deleted;
+-- quantity is signed: receipts and returns add stock, sales take it, adjustments do either
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS stock_movements(
+    id int NOT NULL AUTO_INCREMENT,
+    barcode varchar(10) NOT NULL,
+    kind varchar(20) NOT NULL,
+    quantity int NOT NULL,
+    orderId int NULL,
+    note varchar(200) NOT NULL DEFAULT '',
+    actor varchar(40) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX stock_movements_barcode_idx (barcode, id),
+    INDEX stock_movements_order_idx (orderId),
+    CONSTRAINT stock_movements_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode),
+    CONSTRAINT stock_movements_orders_fk
+    FOREIGN KEY (orderId)  REFERENCES orders (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS stock_movements;
+-- +goose StatementEnd
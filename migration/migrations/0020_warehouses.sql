@@ -0,0 +1,107 @@
+-- +goose Up
+-- warehouse with lowest priority is used by default, every user gets main warehouse that keeps existing stock
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS warehouses(
+    id int NOT NULL AUTO_INCREMENT,
+    userId int NOT NULL,
+    code varchar(20) NOT NULL,
+    name varchar(60) NOT NULL,
+    address varchar(200) NOT NULL DEFAULT '',
+    priority int NOT NULL DEFAULT 0,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT warehouses_user_code_UNQ UNIQUE (userId, code),
+    CONSTRAINT warehouses_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO warehouses (userId, code, name)
+    SELECT id, 'MAIN', 'Main warehouse' FROM users;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS transfers(
+    id int NOT NULL AUTO_INCREMENT,
+    userId int NOT NULL,
+    fromWarehouseId int NOT NULL,
+    toWarehouseId int NOT NULL,
+    note varchar(200) NOT NULL DEFAULT '',
+    actor varchar(40) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX transfers_user_created_idx (userId, created),
+    CONSTRAINT transfers_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id),
+    CONSTRAINT transfers_from_warehouses_fk
+    FOREIGN KEY (fromWarehouseId)  REFERENCES warehouses (id),
+    CONSTRAINT transfers_to_warehouses_fk
+    FOREIGN KEY (toWarehouseId)  REFERENCES warehouses (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS transfer_items(
+    id int NOT NULL AUTO_INCREMENT,
+    transferId int NOT NULL,
+    barcode varchar(10) NOT NULL,
+    quantity int NOT NULL,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT transfer_items_transfer_barcode_UNQ UNIQUE (transferId, barcode),
+    CONSTRAINT transfer_items_transfers_fk
+    FOREIGN KEY (transferId)  REFERENCES transfers (id) ON DELETE CASCADE,
+    CONSTRAINT transfer_items_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+    ADD COLUMN warehouseId int NULL AFTER barcode,
+    ADD COLUMN transferId int NULL AFTER orderId;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE stock_movements
+    JOIN products ON products.barcode=stock_movements.barcode
+    JOIN warehouses ON warehouses.userId=products.userId AND warehouses.code='MAIN'
+    SET stock_movements.warehouseId=warehouses.id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+    MODIFY COLUMN warehouseId int NOT NULL,
+    ADD INDEX stock_movements_warehouse_idx (warehouseId, barcode),
+    ADD CONSTRAINT stock_movements_warehouses_fk
+    FOREIGN KEY (warehouseId)  REFERENCES warehouses (id),
+    ADD CONSTRAINT stock_movements_transfers_fk
+    FOREIGN KEY (transferId)  REFERENCES transfers (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+    DROP FOREIGN KEY stock_movements_transfers_fk,
+    DROP FOREIGN KEY stock_movements_warehouses_fk;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE stock_movements
+    DROP INDEX stock_movements_warehouse_idx,
+    DROP COLUMN transferId,
+    DROP COLUMN warehouseId;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS transfer_items;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS transfers;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS warehouses;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- reservation is active until order is paid(sold), cancelled(released) or it expires(expired)
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS stock_reservations(
+    id int NOT NULL AUTO_INCREMENT,
+    orderId int NOT NULL,
+    orderItemId int NOT NULL,
+    barcode varchar(10) NOT NULL,
+    warehouseId int NOT NULL,
+    quantity int NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    expires TIMESTAMP NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed TIMESTAMP NULL,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX stock_reservations_product_idx (barcode, status, warehouseId),
+    INDEX stock_reservations_expires_idx (status, expires),
+    INDEX stock_reservations_order_idx (orderId, status),
+    CONSTRAINT stock_reservations_orders_fk
+    FOREIGN KEY (orderId)  REFERENCES orders (id),
+    CONSTRAINT stock_reservations_order_items_fk
+    FOREIGN KEY (orderItemId)  REFERENCES order_items (id),
+    CONSTRAINT stock_reservations_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode),
+    CONSTRAINT stock_reservations_warehouses_fk
+    FOREIGN KEY (warehouseId)  REFERENCES warehouses (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS stock_reservations;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- rule is alerted after low stock alert is raised and until available stock is back at threshold
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS reorder_rules(
+    id int NOT NULL AUTO_INCREMENT,
+    barcode varchar(10) NOT NULL UNIQUE,
+    threshold int NOT NULL,
+    alerted BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    CONSTRAINT reorder_rules_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS stock_alerts(
+    id int NOT NULL AUTO_INCREMENT,
+    userId int NOT NULL,
+    barcode varchar(10) NOT NULL,
+    available int NOT NULL,
+    threshold int NOT NULL,
+    isRead BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (id),
+    INDEX stock_alerts_user_idx (userId, id),
+    CONSTRAINT stock_alerts_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id),
+    CONSTRAINT stock_alerts_products_fk
+    FOREIGN KEY (barcode)  REFERENCES products (barcode)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN alertEmail BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN alertWebhook varchar(500) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN alertWebhook,
+    DROP COLUMN alertEmail;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS stock_alerts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS reorder_rules;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- running job is owned by worker instance in lockedBy until lockedUntil, worker extends lease while it runs the job
+-- and jobs with expired lease are returned in queue
+-- +goose StatementBegin
+ALTER TABLE jobs
+    ADD COLUMN lockedBy varchar(36) NULL,
+    ADD COLUMN lockedUntil TIMESTAMP NULL,
+    ADD INDEX jobs_status_lease_idx (status, lockedUntil);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs
+    DROP INDEX jobs_status_lease_idx,
+    DROP COLUMN lockedUntil,
+    DROP COLUMN lockedBy;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- sending delivery is owned by worker instance in lockedBy until lockedUntil, worker extends lease while it sends
+-- the email and deliveries with expired lease are returned in queue
+-- +goose StatementBegin
+ALTER TABLE check_deliveries
+    ADD COLUMN lockedBy varchar(36) NULL,
+    ADD COLUMN lockedUntil TIMESTAMP NULL,
+    ADD INDEX check_deliveries_status_lease_idx (status, lockedUntil);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE check_deliveries
+    DROP INDEX check_deliveries_status_lease_idx,
+    DROP COLUMN lockedUntil,
+    DROP COLUMN lockedBy;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- check names get receipt number suffix so checks of product created in the same second don't collide
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    MODIFY COLUMN filename varchar(100) NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE jobs
+    MODIFY COLUMN filename varchar(100) NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs
+    MODIFY COLUMN filename varchar(45) NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    MODIFY COLUMN filename varchar(45) NOT NULL;
+-- +goose StatementEnd
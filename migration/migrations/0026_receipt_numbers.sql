@@ -0,0 +1,33 @@
+-- +goose Up
+-- every receipt number taken from seller sequence is recorded: it is reserved before check is rendered and stored,
+-- then issued with registered check or voided when check couldn't be created, so numbering stays without gaps
+-- while sequence row is locked only to take the number
+-- +goose StatementBegin
+CREATE TABLE  IF NOT EXISTS receipt_numbers(
+    userId int NOT NULL,
+    number bigint NOT NULL,
+    status varchar(10) NOT NULL DEFAULT 'reserved',
+    checkId int NULL,
+    error varchar(255) NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT u_pkey PRIMARY KEY (userId, number),
+    INDEX receipt_numbers_status_idx (userId, status, created),
+    CONSTRAINT receipt_numbers_users_fk
+    FOREIGN KEY (userId)  REFERENCES users (id),
+    CONSTRAINT receipt_numbers_prchecks_fk
+    FOREIGN KEY (checkId)  REFERENCES prchecks (id) ON DELETE SET NULL
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO receipt_numbers (userId, number, status, checkId)
+    SELECT products.userId, prchecks.receiptNumber, 'issued', prchecks.id FROM prchecks
+    JOIN products ON products.barcode=prchecks.barcode
+    WHERE prchecks.receiptNumber IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE  IF EXISTS receipt_numbers;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- discounts of cancelled orders are released, their coupon uses are given back
+-- +goose StatementBegin
+ALTER TABLE order_discounts
+    ADD COLUMN released TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE order_discounts
+    JOIN orders ON orders.id=order_discounts.orderId AND orders.status='cancelled'
+    SET order_discounts.released=orders.updated,
+        orders.updated=orders.updated;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE coupons
+    JOIN (SELECT couponId, COUNT(*) AS n FROM order_discounts
+            WHERE released IS NOT NULL
+            GROUP BY couponId) released ON released.couponId=coupons.id
+    SET coupons.uses=coupons.uses-released.n;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE coupons
+    JOIN (SELECT couponId, COUNT(*) AS n FROM order_discounts
+            WHERE released IS NOT NULL
+            GROUP BY couponId) released ON released.couponId=coupons.id
+    SET coupons.uses=coupons.uses+released.n;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_discounts
+    DROP COLUMN released;
+-- +goose StatementEnd
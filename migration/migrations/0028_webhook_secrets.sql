@@ -0,0 +1,16 @@
+-- +goose Up
+-- webhooks are signed with secret of their user, existing webhooks get new secret that is returned by next update of alert settings
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN alertWebhookSecret varchar(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE users SET alertWebhookSecret=HEX(RANDOM_BYTES(32)) WHERE alertWebhook<>'';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN alertWebhookSecret;
+-- +goose StatementEnd
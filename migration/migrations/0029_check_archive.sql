@@ -0,0 +1,13 @@
+-- +goose Up
+-- fiscal receipts are never deleted, retention removes their files and marks records archived,
+-- archived checks are rendered from their records
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    ADD COLUMN archived TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prchecks
+    DROP COLUMN archived;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- order is created by seller of products for customer(buyer), per customer coupon limits count discounts of customer;
+-- discounts of orders created before have no customer
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN customer varchar(254) NOT NULL DEFAULT '' AFTER userId;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_discounts
+    ADD COLUMN customer varchar(254) NOT NULL DEFAULT '' AFTER userId,
+    ADD INDEX order_discounts_coupon_customer_idx (couponId, customer);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_discounts
+    DROP INDEX order_discounts_coupon_customer_idx,
+    DROP COLUMN customer;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN customer;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- queued jobs are limited per user
+-- +goose StatementBegin
+ALTER TABLE jobs
+    ADD INDEX jobs_user_status_idx (userId, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs
+    DROP INDEX jobs_user_status_idx;
+-- +goose StatementEnd